@@ -1,57 +1,84 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/png"
+	"io"
 	"log"
 	"os"
-	"strconv"
 
+	"github.com/bsthun/glyphcanvas/package/character"
 	"github.com/bsthun/glyphcanvas/package/page"
 	"github.com/bsthun/glyphcanvas/package/recognize"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Printf("Usage: %s <image_file>\n", os.Args[0])
+	jsonPath := flag.String("json", "", "write results as JSON to this file, \"-\" for stdout")
+	threshold := flag.Uint("threshold", character.DefaultForegroundThreshold, "gray level below which a pixel is ink, 1-255")
+	subpixel := flag.Bool("subpixel", false, "measure aspect ratios on sub-pixel ink edges, use with a database extracted with -subpixel")
+	contour := flag.Bool("contour", false, "describe glyphs by their outline, use with a database extracted with -contour")
+	databasePath := flag.String("db", "generate/extract/char.yml", "feature database written by the extract command")
+	flag.Parse()
+
+	if *threshold < 1 || *threshold > 255 {
+		log.Fatal("threshold must be between 1 and 255")
+	}
+
+	if flag.NArg() < 1 {
+		fmt.Printf("Usage: %s [-json <output_file>] [-threshold <n>] [-subpixel] [-contour] [-db <database>] <image_file>\n", os.Args[0])
 		os.Exit(1)
 	}
 
-	imagePath := os.Args[1]
-	databasePath := "generate/extract/char.yml"
+	// Keep progress and analysis logging out of the JSON stream
+	report := io.Writer(os.Stdout)
+	if *jsonPath == "-" {
+		report = os.Stderr
+	}
+
+	imagePath := flag.Arg(0)
 
 	// Load character database
-	fmt.Println("Loading character database...")
-	database, err := recognize.LoadDatabase(databasePath)
+	fmt.Fprintln(report, "Loading character database...")
+	database, err := recognize.LoadDatabase(*databasePath)
 	if err != nil {
 		log.Fatal("Failed to load database:", err)
 	}
-	fmt.Printf("Loaded %d characters from database\n", len(database.Characters))
+	fmt.Fprintf(report, "Loaded %d characters from database\n", len(database.Characters))
 
 	// Load and process page image
-	fmt.Printf("Processing page: %s\n", imagePath)
-	pageData, err := processPage(imagePath, database)
+	fmt.Fprintf(report, "Processing page: %s\n", imagePath)
+	pageData, err := processPage(report, imagePath, database, uint8(*threshold), *subpixel, *contour)
 	if err != nil {
 		log.Fatal("Failed to process page:", err)
 	}
 
+	if *jsonPath == "-" {
+		err = pageData.WriteJSON(os.Stdout)
+	} else if *jsonPath != "" {
+		err = pageData.SaveJSON(*jsonPath)
+	}
+	if err != nil {
+		log.Fatal("Failed to write JSON:", err)
+	}
+
 	// Display results
-	fmt.Printf("\n=== PAGE OCR RESULTS ===\n")
-	fmt.Printf("Page dimensions: %dx%d\n", pageData.Width, pageData.Height)
-	fmt.Printf("Found %d text areas\n", len(pageData.TextAreas))
-	fmt.Printf("Found %d lines\n", len(pageData.Lines))
-	fmt.Printf("Found %d words\n", len(pageData.Words))
-	fmt.Printf("Found %d characters\n", len(pageData.Chars))
-
-	fmt.Printf("\n=== EXTRACTED TEXT ===\n")
+	fmt.Fprintf(report, "\n=== PAGE OCR RESULTS ===\n")
+	fmt.Fprintf(report, "Page dimensions: %dx%d\n", pageData.Width, pageData.Height)
+	fmt.Fprintf(report, "Found %d text areas\n", len(pageData.TextAreas))
+	fmt.Fprintf(report, "Found %d lines\n", len(pageData.Lines))
+	fmt.Fprintf(report, "Found %d words\n", len(pageData.Words))
+	fmt.Fprintf(report, "Found %d characters\n", len(pageData.Chars))
+
+	fmt.Fprintf(report, "\n=== EXTRACTED TEXT ===\n")
 	text := pageData.GetPlainText()
-	fmt.Println(text)
+	fmt.Fprintln(report, text)
 
-	fmt.Printf("\n=== DETAILED RESULTS ===\n")
+	fmt.Fprintf(report, "\n=== DETAILED RESULTS ===\n")
 	for i, area := range pageData.TextAreas {
-		fmt.Printf("\nText Area %d: (%d,%d) %dx%d\n", i+1, area.X, area.Y, area.Width, area.Height)
+		fmt.Fprintf(report, "\nText Area %d: (%d,%d) %dx%d\n", i+1, area.X, area.Y, area.Width, area.Height)
 		for j, line := range area.Lines {
-			fmt.Printf("  Line %d: (%d,%d) %dx%d\n", j+1, line.X, line.Y, line.Width, line.Height)
+			fmt.Fprintf(report, "  Line %d: (%d,%d) %dx%d\n", j+1, line.X, line.Y, line.Width, line.Height)
 			for k, word := range line.Words {
 				avgConfidence := 0.0
 				if len(word.Chars) > 0 {
@@ -60,13 +87,13 @@ func main() {
 					}
 					avgConfidence /= float64(len(word.Chars))
 				}
-				fmt.Printf("    Word %d: \"%s\" (%.1f%% confidence)\n", k+1, word.Text, avgConfidence)
+				fmt.Fprintf(report, "    Word %d: \"%s\" (%.1f%% confidence)\n", k+1, word.Text, avgConfidence)
 			}
 		}
 	}
 }
 
-func processPage(imagePath string, database *recognize.FeatureDatabase) (*page.Page, error) {
+func processPage(report io.Writer, imagePath string, database *recognize.FeatureDatabase, threshold uint8, subpixel bool, contour bool) (*page.Page, error) {
 	// Load image
 	file, err := os.Open(imagePath)
 	if err != nil {
@@ -79,100 +106,34 @@ func processPage(imagePath string, database *recognize.FeatureDatabase) (*page.P
 		return nil, err
 	}
 
-	// Create page
-	pageData := page.NewPage(img)
-
-	// Detect text structure
-	fmt.Println("Detecting text areas...")
-	err = pageData.DetectTextAreas()
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Println("Detecting text lines...")
-	err = pageData.DetectLines()
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Println("Detecting words...")
-	err = pageData.DetectWords()
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Println("Detecting characters...")
-	err = pageData.DetectCharacters()
+	pageData, err := page.ProcessImage(img, database, page.ProcessOptions{
+		Threshold:   threshold,
+		EdgeBox:     subpixel,
+		ContourOnly: contour,
+		Progress: func(stage string, done, total int) {
+			switch {
+			case stage == "recognize" && done < total:
+				fmt.Fprintf(report, "  Processed %d/%d characters\n", done, total)
+			case done == 0:
+				fmt.Fprintf(report, "Running %s stage...\n", stage)
+			}
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Recognize characters
-	fmt.Println("Recognizing characters...")
-	for i, char := range pageData.Chars {
-		if i%50 == 0 {
-			fmt.Printf("  Processed %d/%d characters\n", i, len(pageData.Chars))
-		}
-
-		if char.Character != nil {
-			features, err := recognize.ExtractFeatures(char.Character)
-			if err != nil {
-				continue
-			}
-
-			candidates := recognize.RecognizeCharacter(features, database)
-			if len(candidates) > 0 {
-				best := candidates[0]
-				char.Unicode = best.Unicode
-				char.Text = unicodeToString(best.Unicode)
-				char.Confidence = best.Confidence
-			}
-		}
-	}
-
-	// Build text from recognized characters
-	for _, word := range pageData.Words {
-		wordText := ""
-		totalConfidence := 0.0
-		validChars := 0
-
-		for _, char := range word.Chars {
-			if char.Text != "" {
-				wordText += char.Text
-				totalConfidence += char.Confidence
-				validChars++
-			}
-		}
-
-		word.Text = wordText
-		if validChars > 0 {
-			word.Confidence = totalConfidence / float64(validChars)
+	suspects := 0
+	for _, char := range pageData.Chars {
+		if char.Suspect {
+			suspects++
 		}
 	}
-
-	// Build line text from words
-	for _, line := range pageData.Lines {
-		lineText := ""
-		for i, word := range line.Words {
-			if i > 0 && word.Text != "" {
-				lineText += " "
-			}
-			lineText += word.Text
-		}
-		line.Text = lineText
+	if suspects > 0 {
+		fmt.Fprintf(report, "Flagged %d suspect characters\n", suspects)
 	}
 
-	fmt.Printf("Character recognition completed (%d characters processed)\n", len(pageData.Chars))
+	fmt.Fprintf(report, "Character recognition completed (%d characters processed)\n", len(pageData.Chars))
 
 	return pageData, nil
 }
-
-func unicodeToString(unicode string) string {
-	// Convert hex unicode to actual character
-	if len(unicode) == 4 {
-		if code, err := strconv.ParseInt(unicode, 16, 32); err == nil {
-			return string(rune(code))
-		}
-	}
-	return "?"
-}
@@ -1,67 +1,47 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"image/color"
 	"image/png"
 	"log"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/bsthun/glyphcanvas/package/character"
 	characterCalculate "github.com/bsthun/glyphcanvas/package/character/calculate"
 	characterHelper "github.com/bsthun/glyphcanvas/package/character/helper"
+	"github.com/bsthun/glyphcanvas/package/recognize"
+	"github.com/bsthun/glyphcanvas/package/recognize/helper"
 	"github.com/bsthun/glyphcanvas/package/region"
 	regionCalculate "github.com/bsthun/glyphcanvas/package/region/calculate"
-	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
 	"gopkg.in/yaml.v3"
 )
 
-type CharacterFeature struct {
-	Unicode        string             `yaml:"unicode"`
-	GridSignature  string             `yaml:"grid_signature"`
-	DirectionHist  [8]float64         `yaml:"direction_histogram"`
-	ZoningFeatures [16]float64        `yaml:"zoning_features"`
-	ChainCode      string             `yaml:"chain_code"`
-	HuMoments      [7]float64         `yaml:"hu_moments"`
-	AspectRatio    float64            `yaml:"aspect_ratio"`
-	Density        float64            `yaml:"density"`
-	CenterOfMass   [2]float64         `yaml:"center_of_mass"`
-	EndPoints      int                `yaml:"end_points"`
-	Junctions      int                `yaml:"junctions"`
-	RegionCount    int                `yaml:"region_count"`
-	RegionFeatures []RegionFeatureSet `yaml:"region_features"`
-	TopologyHash   string             `yaml:"topology_hash"`
-}
-
-type RegionFeatureSet struct {
-	ArcType       string     `yaml:"arc_type"`
-	Circularity   float64    `yaml:"circularity"`
-	Linearity     float64    `yaml:"linearity"`
-	CurveStrength float64    `yaml:"curve_strength"`
-	HuMoments     [7]float64 `yaml:"hu_moments"`
-	ChainCodeHash string     `yaml:"chain_code_hash"`
-	RelativeSize  float64    `yaml:"relative_size"`
-	RelativePos   [2]float64 `yaml:"relative_position"`
-}
+func main() {
+	threshold := flag.Uint("threshold", character.DefaultForegroundThreshold, "gray level below which a pixel is ink, 1-255")
+	dataset := flag.String("dataset", "generate/dataset/singlecharacter", "directory of the generated character images")
+	subpixel := flag.Bool("subpixel", false, "binarize at the default threshold and measure aspect ratios on sub-pixel ink edges")
+	contour := flag.Bool("contour", false, "compute moments, center of mass and zoning on the glyph outline, for outline fonts")
+	output := flag.String("out", "generate/extract/char.yml", "feature database to write, its directory is created when missing")
+	flag.Parse()
 
-type FeatureDatabase struct {
-	Characters map[string]*CharacterFeature `yaml:"characters"`
-}
+	if *threshold < 1 || *threshold > 255 {
+		log.Fatal("threshold must be between 1 and 255")
+	}
 
-func main() {
-	datasetPath := "generate/dataset/singlecharacter"
-	outputPath := "generate/extract/char.yml"
+	datasetPath := *dataset
+	outputPath := *output
 
 	files, err := filepath.Glob(filepath.Join(datasetPath, "*.png"))
 	if err != nil {
 		log.Fatal("Failed to read dataset:", err)
 	}
 
-	database := &FeatureDatabase{
-		Characters: make(map[string]*CharacterFeature),
+	database := &recognize.FeatureDatabase{
+		Version:    recognize.DatabaseVersion,
+		Characters: make(map[string]*recognize.CharacterFeature),
 	}
 
 	for _, file := range files {
@@ -72,18 +52,22 @@ func main() {
 
 		fmt.Printf("Processing %s (Unicode: %s)...\n", filepath.Base(file), unicode)
 
-		char, err := loadCharacterFromImage(file)
+		char, err := loadCharacterFromImage(file, uint8(*threshold), *subpixel)
 		if err != nil {
 			log.Printf("Failed to load %s: %v\n", file, err)
 			continue
 		}
 
-		features, err := extractFeatures(char)
+		features, err := extractFeatures(char, *contour)
 		if err != nil {
 			log.Printf("Failed to extract features from %s: %v\n", file, err)
 			continue
 		}
 
+		for _, problem := range char.AnalysisErrors {
+			log.Printf("Incomplete analysis of %s: %v\n", file, problem)
+		}
+
 		features.Unicode = unicode
 		database.Characters[unicode] = features
 	}
@@ -93,6 +77,18 @@ func main() {
 		log.Fatal("Failed to marshal YAML:", err)
 	}
 
+	// Check the output the way the recognizer will load it before replacing a working database
+	var written recognize.FeatureDatabase
+	if err := yaml.Unmarshal(data, &written); err != nil {
+		log.Fatal("Failed to read back YAML:", err)
+	}
+	if problems := written.Validate(); len(problems) > 0 {
+		for _, problem := range problems {
+			log.Printf("Invalid database entry: %v\n", problem)
+		}
+		log.Fatalf("Refusing to write %s with %d invalid entries", outputPath, len(problems))
+	}
+
 	err = os.MkdirAll(filepath.Dir(outputPath), 0755)
 	if err != nil {
 		log.Fatal("Failed to create output directory:", err)
@@ -111,31 +107,32 @@ func extractUnicodeFromFilename(filename string) string {
 	base = strings.TrimSuffix(base, filepath.Ext(base))
 
 	if strings.HasPrefix(base, "char_th_") {
+		// A cluster or ligature lists its code points joined by '+'
 		hex := strings.TrimPrefix(base, "char_th_")
-		if code, err := strconv.ParseInt(hex, 16, 32); err == nil {
-			return fmt.Sprintf("%04X", code)
+		if runes, err := recognize.ParseUnicodeKey(hex); err == nil {
+			return recognize.UnicodeKey(string(runes))
 		}
 	} else if strings.HasPrefix(base, "char_en_upper_") {
 		char := strings.TrimPrefix(base, "char_en_upper_")
 		if len(char) == 1 {
-			return fmt.Sprintf("%04X", rune(char[0]))
+			return recognize.UnicodeKey(char)
 		}
 	} else if strings.HasPrefix(base, "char_en_lower_") {
 		char := strings.TrimPrefix(base, "char_en_lower_")
 		if len(char) == 1 {
-			return fmt.Sprintf("%04X", rune(char[0]))
+			return recognize.UnicodeKey(char)
 		}
 	} else if strings.HasPrefix(base, "char_") {
 		digit := strings.TrimPrefix(base, "char_")
 		if len(digit) == 1 && digit[0] >= '0' && digit[0] <= '9' {
-			return fmt.Sprintf("%04X", rune(digit[0]))
+			return recognize.UnicodeKey(digit)
 		}
 	}
 
 	return ""
 }
 
-func loadCharacterFromImage(filename string) (*character.Character, error) {
+func loadCharacterFromImage(filename string, threshold uint8, subpixel bool) (*character.Character, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -147,23 +144,24 @@ func loadCharacterFromImage(filename string) (*character.Character, error) {
 		return nil, err
 	}
 
-	bounds := img.Bounds()
-	char := character.NewCharacter(uint16(bounds.Dx()), uint16(bounds.Dy()), nil)
+	if subpixel {
+		return character.LoadFromImageSubpixel(img, nil), nil
+	}
+	return character.LoadFromImage(img, threshold, nil), nil
+}
 
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			c := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
-			if c.Y < 128 {
-				char.Draw(uint16(x-bounds.Min.X), uint16(y-bounds.Min.Y))
-			}
-		}
+func extractFeatures(char *character.Character, contourOnly bool) (*recognize.CharacterFeature, error) {
+	// A blank image has no shape to describe. recognize.ExtractFeatures returns empty features for it so a page still
+	// gets a result, but a database entry of zeros would match every blank input, so the file is skipped instead
+	if char.IsEmpty() {
+		return nil, character.ErrEmptyCharacter
 	}
 
-	return char, nil
-}
+	features := &recognize.CharacterFeature{}
 
-func extractFeatures(char *character.Character) (*CharacterFeature, error) {
-	features := &CharacterFeature{}
+	// Same canvas as recognize.ExtractFeatures so database and page glyphs are compared at one resolution
+	source := char
+	char = characterCalculate.NormalizeToCanvas(char, characterCalculate.NormalizedCanvasSize)
 
 	err := characterHelper.CharacterDetectAnchors(char)
 	if err != nil {
@@ -175,18 +173,27 @@ func extractFeatures(char *character.Character) (*CharacterFeature, error) {
 		return nil, err
 	}
 
+	// The bitmap features below do not depend on it, keep the error on the character like recognize.ExtractFeatures
 	err = characterHelper.CharacterComprehensiveAnalysis(char)
 	if err != nil {
-		// Ignore error as it may not be critical
+		source.RecordAnalysisError(fmt.Errorf("comprehensive analysis: %w", err))
+	}
+
+	// Same split as recognize.ExtractOptions.ContourOnly so a -contour database matches pages recognized with it
+	shape := char
+	if contourOnly {
+		shape = characterCalculate.CharacterContour(char)
 	}
 
-	features.GridSignature = computeGridSignature(char, 8)
-	features.DirectionHist = computeDirectionHistogram(char)
-	features.ZoningFeatures = computeZoningFeatures(char)
-	features.ChainCode = computeChainCodeFromBitmap(char)
-	features.HuMoments = computeHuMomentsFromChar(char)
+	features.GridSignature = helper.ComputeGridSignature(char, 8)
+	features.DirectionHist = helper.ComputeDirectionHistogram(char)
+	features.ZoningFeatures = helper.ComputeZoningFeatures(shape)
+	features.ChainCode = helper.ComputeChainCodeFromBitmap(char)
+	features.HuMoments = helper.ComputeHuMomentsFromChar(shape)
 
-	if char.GetBoundingBoxHeight() > 0 {
+	if box := source.EdgeBox; box != nil && box.Height() > 0 {
+		features.AspectRatio = box.Width() / box.Height()
+	} else if char.GetBoundingBoxHeight() > 0 {
 		features.AspectRatio = float64(char.GetBoundingBoxWidth()) / float64(char.GetBoundingBoxHeight())
 	} else {
 		features.AspectRatio = 1.0
@@ -196,403 +203,66 @@ func extractFeatures(char *character.Character) (*CharacterFeature, error) {
 	if totalArea > 0 {
 		features.Density = float64(char.GetPixelCount()) / totalArea
 	}
+	features.FillRatio = helper.ComputeFillRatioExcludingHoles(char)
 
-	cx, cy := computeCenterOfMass(char)
+	cx, cy := helper.ComputeCenterOfMass(shape)
 	features.CenterOfMass = [2]float64{cx, cy}
 
-	endpoints, junctions := countEndpointsAndJunctions(char)
+	features.Elongation = helper.ComputeElongation(shape)
+	features.Eccentricity = helper.ComputeEccentricity(shape)
+	features.SymmetryAxis, features.SymmetryScore = helper.ComputeSymmetryAxis(char)
+	features.Directions = characterHelper.CharacterDominantDirections(char, 8)
+
+	endpoints, junctions := helper.CountEndpointsAndJunctions(char)
 	features.EndPoints = endpoints
 	features.Junctions = junctions
+	features.StrokeCount = characterHelper.CharacterEstimateStrokeCount(char)
 
-	regions, _ := characterCalculate.CharacterBreakdownToRegions(char)
-	features.RegionCount = len(regions)
-
-	features.RegionFeatures = extractRegionFeatures(char, regions)
-
-	features.TopologyHash = computeTopologyHash(features)
-
-	return features, nil
-}
-
-func computeGridSignature(char *character.Character, gridSize int) string {
-	grid := make([][]bool, gridSize)
-	for i := range grid {
-		grid[i] = make([]bool, gridSize)
-	}
-
-	cellWidth := float64(char.SizeX) / float64(gridSize)
-	cellHeight := float64(char.SizeY) / float64(gridSize)
-
-	for x, col := range char.Bitmap {
-		for y, val := range col {
-			if val {
-				gridX := int(float64(x) / cellWidth)
-				gridY := int(float64(y) / cellHeight)
-
-				if gridX >= gridSize {
-					gridX = gridSize - 1
-				}
-				if gridY >= gridSize {
-					gridY = gridSize - 1
-				}
-
-				grid[gridY][gridX] = true
-			}
-		}
-	}
-
-	signature := ""
-	for y := 0; y < gridSize; y++ {
-		for x := 0; x < gridSize; x++ {
-			if grid[y][x] {
-				signature += "1"
-			} else {
-				signature += "0"
-			}
-		}
-	}
-
-	return signature
-}
-
-func computeDirectionHistogram(char *character.Character) [8]float64 {
-	var hist [8]float64
-	directions := [][2]int{
-		{1, 0}, {1, 1}, {0, 1}, {-1, 1},
-		{-1, 0}, {-1, -1}, {0, -1}, {1, -1},
-	}
-
-	for x, col := range char.Bitmap {
-		for y, val := range col {
-			if val {
-				for i, dir := range directions {
-					nx := int(x) + dir[0]
-					ny := int(y) + dir[1]
-
-					if nx >= 0 && ny >= 0 && uint16(nx) < char.SizeX && uint16(ny) < char.SizeY {
-						if char.IsDrew(uint16(nx), uint16(ny)) {
-							hist[i]++
-						}
-					}
-				}
-			}
-		}
-	}
-
-	total := 0.0
-	for _, v := range hist {
-		total += v
-	}
-	if total > 0 {
-		for i := range hist {
-			hist[i] /= total
-		}
-	}
-
-	return hist
-}
-
-func computeZoningFeatures(char *character.Character) [16]float64 {
-	var features [16]float64
-	zoneWidth := float64(char.SizeX) / 4.0
-	zoneHeight := float64(char.SizeY) / 4.0
-
-	for _, point := range char.Draws {
-		zoneX := int(float64(point.X) / zoneWidth)
-		zoneY := int(float64(point.Y) / zoneHeight)
-
-		if zoneX >= 4 {
-			zoneX = 3
-		}
-		if zoneY >= 4 {
-			zoneY = 3
-		}
-
-		zoneIdx := zoneY*4 + zoneX
-		features[zoneIdx]++
-	}
-
-	total := 0.0
-	for _, v := range features {
-		total += v
-	}
-	if total > 0 {
-		for i := range features {
-			features[i] /= total
-		}
+	count, layout := helper.ComputeComponents(char, recognize.MaxComponents)
+	features.ComponentCount = count
+	for _, component := range layout {
+		features.Components = append(features.Components, recognize.ComponentFeature{
+			RelativeSize: component[0],
+			RelativePos:  [2]float64{component[1], component[2]},
+		})
 	}
 
-	return features
-}
-
-func computeChainCodeFromBitmap(char *character.Character) string {
-	if len(char.Draws) == 0 {
-		return ""
-	}
-
-	visited := make(map[string]bool)
-	startX, startY := char.Draws[0].X, char.Draws[0].Y
-	currentX, currentY := startX, startY
-
-	chainCode := ""
-	directions := [][2]int{
-		{1, 0}, {1, 1}, {0, 1}, {-1, 1},
-		{-1, 0}, {-1, -1}, {0, -1}, {1, -1},
-	}
-
-	maxSteps := 100
-	for step := 0; step < maxSteps; step++ {
-		key := fmt.Sprintf("%d,%d", currentX, currentY)
-		if visited[key] {
-			break
-		}
-		visited[key] = true
-
-		found := false
-		for i, dir := range directions {
-			nx := int(currentX) + dir[0]
-			ny := int(currentY) + dir[1]
-
-			if nx >= 0 && ny >= 0 && uint16(nx) < char.SizeX && uint16(ny) < char.SizeY {
-				nextKey := fmt.Sprintf("%d,%d", nx, ny)
-				if !visited[nextKey] && char.IsDrew(uint16(nx), uint16(ny)) {
-					chainCode += fmt.Sprintf("%d", i)
-					currentX, currentY = uint16(nx), uint16(ny)
-					found = true
-					break
-				}
-			}
-		}
-
-		if !found {
-			break
-		}
-
-		if len(chainCode) > 50 {
-			break
+	// Skipped for plain strokes like recognize.ExtractFeatures so both sides carry the same region features
+	if characterHelper.CharacterComplexity(char) < recognize.DefaultSimpleGlyphComplexity {
+		features.RegionCount = features.ComponentCount
+	} else {
+		regions, err := characterCalculate.CharacterBreakdownToRegions(char)
+		if err != nil {
+			source.RecordAnalysisError(fmt.Errorf("region breakdown: %w", err))
 		}
+		features.RegionCount = len(regions)
+		features.RegionFeatures = extractRegionFeatures(char, regions)
 	}
 
-	return chainCode
-}
+	features.TopologyHash = helper.ComputeTopologyHash(features.EndPoints, features.Junctions, features.RegionCount, features.ChainCode, features.GridSignature)
 
-func computeHuMomentsFromChar(char *character.Character) [7]float64 {
-	moments := make(map[string]float64)
-
-	m00, m10, m01 := 0.0, 0.0, 0.0
-	m20, m02, m11 := 0.0, 0.0, 0.0
-	m30, m03, m21, m12 := 0.0, 0.0, 0.0, 0.0
-
-	for _, point := range char.Draws {
-		x := float64(point.X)
-		y := float64(point.Y)
-
-		m00++
-		m10 += x
-		m01 += y
-		m11 += x * y
-		m20 += x * x
-		m02 += y * y
-		m30 += x * x * x
-		m03 += y * y * y
-		m21 += x * x * y
-		m12 += x * y * y
-	}
-
-	moments["m00"] = m00
-	moments["m10"] = m10
-	moments["m01"] = m01
-
-	if m00 > 0 {
-		xc := m10 / m00
-		yc := m01 / m00
-
-		moments["mu20"] = m20 - xc*m10
-		moments["mu02"] = m02 - yc*m01
-		moments["mu11"] = m11 - xc*m01
-		moments["mu30"] = m30 - 3*xc*m20 + 2*xc*xc*m10
-		moments["mu03"] = m03 - 3*yc*m02 + 2*yc*yc*m01
-		moments["mu21"] = m21 - 2*xc*m11 - yc*m20 + 2*xc*xc*m01
-		moments["mu12"] = m12 - 2*yc*m11 - xc*m02 + 2*yc*yc*m10
-	}
-
-	huArray := regionHelper.RegionComputeHuInvariants(moments)
-	var result [7]float64
-	copy(result[:], huArray)
-	return result
+	return features, nil
 }
 
-func computeCenterOfMass(char *character.Character) (float64, float64) {
-	if len(char.Draws) == 0 {
-		return 0, 0
-	}
-
-	var sumX, sumY uint32
-	for _, point := range char.Draws {
-		sumX += uint32(point.X)
-		sumY += uint32(point.Y)
-	}
-
-	cx := float64(sumX) / float64(len(char.Draws))
-	cy := float64(sumY) / float64(len(char.Draws))
-
-	if char.SizeX > 0 && char.SizeY > 0 {
-		cx /= float64(char.SizeX)
-		cy /= float64(char.SizeY)
-	}
+func extractRegionFeatures(char *character.Character, regions []*region.Region) []region.RegionFeatureSet {
+	var featureSets []region.RegionFeatureSet
 
-	return cx, cy
-}
-
-func countEndpointsAndJunctions(char *character.Character) (int, int) {
-	endpoints := 0
-	junctions := 0
-
-	for x, col := range char.Bitmap {
-		for y, val := range col {
-			if val {
-				neighbors := 0
-				for dx := -1; dx <= 1; dx++ {
-					for dy := -1; dy <= 1; dy++ {
-						if dx == 0 && dy == 0 {
-							continue
-						}
-						nx := int(x) + dx
-						ny := int(y) + dy
-						if nx >= 0 && ny >= 0 && uint16(nx) < char.SizeX && uint16(ny) < char.SizeY {
-							if char.IsDrew(uint16(nx), uint16(ny)) {
-								neighbors++
-							}
-						}
-					}
-				}
-
-				if neighbors == 1 {
-					endpoints++
-				} else if neighbors > 2 {
-					junctions++
-				}
-			}
-		}
+	minArea := int(character.DefaultCharacterConfig().MinRegionSize)
+	if char.Config != nil {
+		minArea = int(char.Config.MinRegionSize)
 	}
 
-	return endpoints, junctions
-}
-
-func extractRegionFeatures(char *character.Character, regions []*region.Region) []RegionFeatureSet {
-	var featureSets []RegionFeatureSet
-
-	for _, reg := range regions {
-		if reg == nil || len(reg.Draws) == 0 {
-			continue
-		}
-
-		features := RegionFeatureSet{}
-
-		arc := regionCalculate.RegionArc(reg)
-		if arc != nil {
-			features.ArcType = getArcTypeString(arc.Type)
-			moments := regionHelper.RegionComputeMoments(reg)
-			huMoments := regionHelper.RegionComputeHuInvariants(moments)
-			features.Circularity = regionHelper.RegionComputeCircularity(huMoments)
-			features.Linearity = regionHelper.RegionComputeLinearity(huMoments)
-
-			// Compute curve strength
-			edges := regionHelper.RegionExtractEdge(reg)
-			chainCode := regionHelper.RegionComputeChainCode(edges)
-			curvatures := regionHelper.RegionComputeCurvatures(chainCode)
-			features.CurveStrength = float64(regionHelper.RegionComputeCurveStrength(curvatures, edges))
-		}
-
-		if arc == nil {
-			moments := regionHelper.RegionComputeMoments(reg)
-			huArray := regionHelper.RegionComputeHuInvariants(moments)
-			copy(features.HuMoments[:], huArray)
-		}
-
-		edges := regionHelper.RegionExtractEdge(reg)
-		chainCode := regionHelper.RegionComputeChainCode(edges)
-		features.ChainCodeHash = hashChainCode(chainCode)
+	for _, reg := range recognize.LargestRegions(regions, minArea, recognize.DefaultMaxRegions) {
+		features := regionCalculate.RegionFeaturesFromAnalysis(regionCalculate.RegionAnalyze(reg))
 
 		if char.GetPixelCount() > 0 {
 			features.RelativeSize = float64(len(reg.Draws)) / float64(char.GetPixelCount())
 		}
 
-		if len(reg.Draws) > 0 {
-			var sumX, sumY uint32
-			for _, point := range reg.Draws {
-				sumX += uint32(point.X)
-				sumY += uint32(point.Y)
-			}
-			avgX := float64(sumX) / float64(len(reg.Draws))
-			avgY := float64(sumY) / float64(len(reg.Draws))
-
-			if char.SizeX > 0 && char.SizeY > 0 {
-				features.RelativePos[0] = avgX / float64(char.SizeX)
-				features.RelativePos[1] = avgY / float64(char.SizeY)
-			}
-		}
+		features.RelativePos = reg.RelativePosition(char.SizeX, char.SizeY)
 
 		featureSets = append(featureSets, features)
-
-		if len(featureSets) >= 10 {
-			break
-		}
 	}
 
 	return featureSets
 }
-
-func getArcTypeString(arcType region.ArcType) string {
-	switch arcType {
-	case region.ArcTypeCircle:
-		return "circle"
-	case region.ArcTypeStrengthLine:
-		return "strength_line"
-	case region.ArcTypeCurveLine:
-		return "curve_line"
-	case region.ArcTypeTriangle:
-		return "triangle"
-	case region.ArcTypeRectangle:
-		return "rectangle"
-	default:
-		return "unknown"
-	}
-}
-
-func hashChainCode(chainCode []int) string {
-	if len(chainCode) == 0 {
-		return ""
-	}
-
-	str := ""
-	for _, code := range chainCode {
-		str += fmt.Sprintf("%d", code)
-		if len(str) > 20 {
-			break
-		}
-	}
-
-	hash := 0
-	for i, c := range str {
-		hash = hash*31 + int(c) + i
-	}
-
-	return fmt.Sprintf("%08x", hash)
-}
-
-func computeTopologyHash(features *CharacterFeature) string {
-	data := fmt.Sprintf("e%d_j%d_r%d_%s_%s",
-		features.EndPoints,
-		features.Junctions,
-		features.RegionCount,
-		features.ChainCode,
-		features.GridSignature[:16])
-
-	hash := 0
-	for _, c := range data {
-		hash = hash*31 + int(c)
-	}
-
-	return fmt.Sprintf("%016x", hash)
-}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
@@ -36,17 +37,33 @@ type CharacterInfo struct {
 }
 
 func main() {
+	outputDir := flag.String("out", "generate/dataset/singlecharacter", "directory the character images are written to, created when missing")
+	fontDir := flag.String("fonts", "generate/font", "directory holding NotoSansThaiLooped-Regular.ttf and Roboto-Regular.ttf")
+	flag.Parse()
+
 	fmt.Println("Starting character dataset generation...")
 
-	outputDir := "generate/dataset/singlecharacter"
+	generated, failed, err := generateDataset(*outputDir, *fontDir)
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	fmt.Printf("\nCharacter dataset generation complete!\n")
+	fmt.Printf("Generated: %d images\n", generated)
+	fmt.Printf("Failed: %d images\n", failed)
+	fmt.Printf("Output directory: %s\n", *outputDir)
+}
+
+// generateDataset renders every character of the dataset to outputDir, creating it when missing. Fonts missing from
+// fontDir fall back to the basic font. Returns how many images were written and how many failed
+func generateDataset(outputDir, fontDir string) (int, int, error) {
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		log.Fatalf("Failed to create output directory: %v", err)
+		return 0, 0, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Load fonts for different character sets
-	thaiFontPath := "generate/font/NotoSansThaiLooped-Regular.ttf"
+	thaiFontPath := filepath.Join(fontDir, "NotoSansThaiLooped-Regular.ttf")
 	thaiFontFace, err := loadFont(thaiFontPath, 32)
 	if err != nil {
 		fmt.Printf("Warning: Failed to load Thai font %s: %v\n", thaiFontPath, err)
@@ -56,7 +73,7 @@ func main() {
 		fmt.Println("Loaded Noto Sans Thai for Thai characters")
 	}
 
-	englishFontPath := "generate/font/Roboto-Regular.ttf"
+	englishFontPath := filepath.Join(fontDir, "Roboto-Regular.ttf")
 	englishFontFace, err := loadFont(englishFontPath, 32)
 	if err != nil {
 		fmt.Printf("Warning: Failed to load English font %s: %v\n", englishFontPath, err)
@@ -184,10 +201,7 @@ func main() {
 		}
 	}
 
-	fmt.Printf("\nCharacter dataset generation complete!\n")
-	fmt.Printf("Generated: %d images\n", generated)
-	fmt.Printf("Failed: %d images\n", failed)
-	fmt.Printf("Output directory: %s\n", outputDir)
+	return generated, failed, nil
 }
 
 func loadFont(fontPath string, size float64) (font.Face, error) {
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateDatasetOutputDir(t *testing.T) {
+	// A nested directory that does not exist yet and a font directory without fonts, so the basic font is used
+	outputDir := filepath.Join(t.TempDir(), "dataset", "singlecharacter")
+	generated, failed, err := generateDataset(outputDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("generateDataset failed: %v", err)
+	}
+	if generated == 0 || failed != 0 {
+		t.Errorf("Expected every image generated, got %d generated and %d failed", generated, failed)
+	}
+
+	files, err := filepath.Glob(filepath.Join(outputDir, "*.png"))
+	if err != nil {
+		t.Fatalf("Failed to list the output directory: %v", err)
+	}
+	if len(files) != generated {
+		t.Errorf("Expected %d images in %s, found %d", generated, outputDir, len(files))
+	}
+	for _, name := range []string{"char_en_upper_A.png", "char_en_lower_a.png", "char_7.png", "char_th_0e01.png"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected %s in the output directory: %v", name, err)
+		}
+	}
+}
@@ -1,28 +1,49 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/png"
 	"log"
 	"os"
-	"strconv"
+	"sort"
 
+	"github.com/bsthun/glyphcanvas/package/character"
 	"github.com/bsthun/glyphcanvas/package/page"
 	"github.com/bsthun/glyphcanvas/package/recognize"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Printf("Usage: %s <image_file>\n", os.Args[0])
+	seed := flag.Int64("seed", 0, "seed overlay filenames for reproducible output, 0 for random")
+	explain := flag.Bool("explain", false, "print the per-feature distance breakdown for each character's top candidate")
+	threshold := flag.Uint("threshold", character.DefaultForegroundThreshold, "gray level below which a pixel is ink, 1-255")
+	align := flag.Bool("align", false, "straighten slightly tilted glyphs before recognition, may hurt slanted scripts")
+	subpixel := flag.Bool("subpixel", false, "measure aspect ratios on sub-pixel ink edges, use with a database extracted with -subpixel")
+	contour := flag.Bool("contour", false, "describe glyphs by their outline, use with a database extracted with -contour")
+	databasePath := flag.String("db", "generate/extract/char.yml", "feature database written by the extract command")
+	outputDir := flag.String("out", "generate/recognize", "directory the overlay images are written to, created when missing")
+	fontDir := flag.String("fonts", "generate/font", "directory holding NotoSansThaiLooped-Regular.ttf and Roboto-Regular.ttf")
+	confidenceColors := flag.Bool("confidence-colors", false, "color character boxes from red to green by recognition confidence")
+	flag.Parse()
+
+	if *threshold < 1 || *threshold > 255 {
+		log.Fatal("threshold must be between 1 and 255")
+	}
+
+	if flag.NArg() < 1 {
+		fmt.Printf("Usage: %s [-seed <n>] [-explain] [-threshold <n>] [-align] [-subpixel] [-contour] [-db <database>] [-out <dir>] [-fonts <dir>] [-confidence-colors] <image_file>\n", os.Args[0])
 		os.Exit(1)
 	}
 
-	imagePath := os.Args[1]
-	databasePath := "generate/extract/char.yml"
+	if *seed != 0 {
+		SeedOverlayNames(*seed)
+	}
+
+	imagePath := flag.Arg(0)
 
 	// Load character database
 	fmt.Println("Loading character database...")
-	database, err := recognize.LoadDatabase(databasePath)
+	database, err := recognize.LoadDatabase(*databasePath)
 	if err != nil {
 		log.Fatal("Failed to load database:", err)
 	}
@@ -30,14 +51,14 @@ func main() {
 
 	// Load font manager
 	fmt.Println("Loading fonts...")
-	fontManager, err := NewFontManager()
+	fontManager, err := NewFontManager(*fontDir)
 	if err != nil {
 		log.Fatal("Failed to load fonts:", err)
 	}
 
 	// Load and process page image
 	fmt.Printf("Processing page: %s\n", imagePath)
-	pageData, err := processPage(imagePath, database)
+	pageData, err := processPage(imagePath, database, uint8(*threshold), *align, *subpixel, *contour)
 	if err != nil {
 		log.Fatal("Failed to process page:", err)
 	}
@@ -54,145 +75,118 @@ func main() {
 	text := pageData.GetPlainText()
 	fmt.Println(text)
 
+	if *explain {
+		fmt.Printf("\n=== DISTANCE BREAKDOWN ===\n")
+		explainCharacters(pageData, database)
+	}
+
 	// Generate overlay images
 	fmt.Println("\n=== GENERATING OVERLAY IMAGES ===")
 
-	err = RenderTextAreasOverlay(pageData, fontManager)
+	err = RenderTextAreasOverlay(pageData, fontManager, *outputDir)
 	if err != nil {
 		fmt.Printf("Failed to render text areas overlay: %v\n", err)
 	}
 
-	err = RenderLinesOverlay(pageData, fontManager)
+	err = RenderLinesOverlay(pageData, fontManager, *outputDir)
 	if err != nil {
 		fmt.Printf("Failed to render lines overlay: %v\n", err)
 	}
 
-	err = RenderWordsOverlay(pageData, fontManager)
+	err = RenderWordsOverlay(pageData, fontManager, *outputDir)
 	if err != nil {
 		fmt.Printf("Failed to render words overlay: %v\n", err)
 	}
 
-	err = RenderCharactersOverlay(pageData, fontManager)
+	colorMode := ColorByIndex
+	if *confidenceColors {
+		colorMode = ColorByConfidence
+	}
+	err = RenderCharactersOverlay(pageData, fontManager, *outputDir, colorMode)
 	if err != nil {
 		fmt.Printf("Failed to render characters overlay: %v\n", err)
 	}
 
-	err = RenderFullOverlay(pageData, fontManager)
+	err = RenderFullOverlay(pageData, fontManager, *outputDir)
 	if err != nil {
 		fmt.Printf("Failed to render full overlay: %v\n", err)
 	}
 
 	fmt.Println("\n=== OCR PROCESSING COMPLETE ===")
-	fmt.Printf("Check generate/recognize/ for overlay images\n")
+	fmt.Printf("Check %s for overlay images\n", *outputDir)
 }
 
-func processPage(imagePath string, database *recognize.FeatureDatabase) (*page.Page, error) {
-	// Load image
-	file, err := os.Open(imagePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+func explainCharacters(pageData *page.Page, database *recognize.FeatureDatabase) {
+	for i, char := range pageData.Chars {
+		if char.Unicode == "" || char.GetCharacter() == nil {
+			continue
+		}
 
-	img, err := png.Decode(file)
-	if err != nil {
-		return nil, err
-	}
+		features, err := recognize.ExtractFeaturesWithOptions(char.GetCharacter(), pageData.ExtractOptions)
+		if err != nil {
+			continue
+		}
+		contributions := recognize.ExplainDistance(features, database.Characters[char.Unicode])
 
-	// Create page
-	pageData := page.NewPage(img)
+		names := make([]string, 0, len(contributions))
+		total := 0.0
+		for name, value := range contributions {
+			names = append(names, name)
+			total += value
+		}
+		sort.Slice(names, func(a, b int) bool {
+			return contributions[names[a]] > contributions[names[b]]
+		})
 
-	// Detect text structure
-	fmt.Println("Detecting text areas...")
-	err = pageData.DetectTextAreas()
-	if err != nil {
-		return nil, err
+		fmt.Printf("Char %d at (%d,%d) '%s' distance %.4f\n", i+1, char.X, char.Y, char.Text, total)
+		for _, name := range names {
+			fmt.Printf("  %-20s %.4f\n", name, contributions[name])
+		}
 	}
+}
 
-	fmt.Println("Detecting text lines...")
-	err = pageData.DetectLines()
+func processPage(imagePath string, database *recognize.FeatureDatabase, threshold uint8, align bool, subpixel bool, contour bool) (*page.Page, error) {
+	// Load image
+	file, err := os.Open(imagePath)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	fmt.Println("Detecting words...")
-	err = pageData.DetectWords()
+	img, err := png.Decode(file)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("Detecting characters...")
-	err = pageData.DetectCharacters()
+	pageData, err := page.ProcessImage(img, database, page.ProcessOptions{
+		Threshold:   threshold,
+		Align:       align,
+		EdgeBox:     subpixel,
+		ContourOnly: contour,
+		Progress: func(stage string, done, total int) {
+			switch {
+			case stage == "recognize" && done < total:
+				fmt.Printf("  Processed %d/%d characters\n", done, total)
+			case done == 0:
+				fmt.Printf("Running %s stage...\n", stage)
+			}
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Recognize characters
-	fmt.Println("Recognizing characters...")
-	for i, char := range pageData.Chars {
-		if i%50 == 0 {
-			fmt.Printf("  Processed %d/%d characters\n", i, len(pageData.Chars))
-		}
-
-		if char.Character != nil {
-			features, err := recognize.ExtractFeatures(char.Character)
-			if err != nil {
-				continue
-			}
-
-			candidates := recognize.RecognizeCharacter(features, database)
-			if len(candidates) > 0 {
-				best := candidates[0]
-				char.Unicode = best.Unicode
-				char.Text = unicodeToString(best.Unicode)
-				char.Confidence = best.Confidence
-			}
-		}
-	}
-
-	// Build text from recognized characters
-	for _, word := range pageData.Words {
-		wordText := ""
-		totalConfidence := 0.0
-		validChars := 0
-
-		for _, char := range word.Chars {
-			if char.Text != "" {
-				wordText += char.Text
-				totalConfidence += char.Confidence
-				validChars++
-			}
-		}
-
-		word.Text = wordText
-		if validChars > 0 {
-			word.Confidence = totalConfidence / float64(validChars)
+	suspects := 0
+	for _, char := range pageData.Chars {
+		if char.Suspect {
+			suspects++
 		}
 	}
-
-	// Build line text from words
-	for _, line := range pageData.Lines {
-		lineText := ""
-		for i, word := range line.Words {
-			if i > 0 && word.Text != "" {
-				lineText += " "
-			}
-			lineText += word.Text
-		}
-		line.Text = lineText
+	if suspects > 0 {
+		fmt.Printf("Flagged %d suspect characters\n", suspects)
 	}
 
 	fmt.Printf("Character recognition completed (%d characters processed)\n", len(pageData.Chars))
 
 	return pageData, nil
 }
-
-func unicodeToString(unicode string) string {
-	// Convert hex unicode to actual character
-	if len(unicode) == 4 {
-		if code, err := strconv.ParseInt(unicode, 16, 32); err == nil {
-			return string(rune(code))
-		}
-	}
-	return "?"
-}
@@ -5,7 +5,8 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
-	"image/png"
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 
@@ -23,12 +24,12 @@ type FontManager struct {
 	EnglishFont font.Face
 }
 
-// NewFontManager creates a new font manager with loaded fonts
-func NewFontManager() (*FontManager, error) {
+// NewFontManager creates a new font manager with the fonts in fontDir, a missing font falls back to the basic font
+func NewFontManager(fontDir string) (*FontManager, error) {
 	fm := &FontManager{}
 
 	// Load Thai font (Noto Sans Thai)
-	thaiFontPath := "generate/font/NotoSansThaiLooped-Regular.ttf"
+	thaiFontPath := filepath.Join(fontDir, "NotoSansThaiLooped-Regular.ttf")
 	thaiFont, err := loadFont(thaiFontPath, 12)
 	if err != nil {
 		fmt.Printf("Warning: Failed to load Thai font %s: %v\n", thaiFontPath, err)
@@ -38,7 +39,7 @@ func NewFontManager() (*FontManager, error) {
 	}
 
 	// Load English font (Roboto)
-	englishFontPath := "generate/font/Roboto-Regular.ttf"
+	englishFontPath := filepath.Join(fontDir, "Roboto-Regular.ttf")
 	englishFont, err := loadFont(englishFontPath, 12)
 	if err != nil {
 		fmt.Printf("Warning: Failed to load English font %s: %v\n", englishFontPath, err)
@@ -62,7 +63,7 @@ func (fm *FontManager) GetFont(text string) font.Face {
 }
 
 // RenderTextAreasOverlay renders text areas with colored bounding boxes
-func RenderTextAreasOverlay(pageData *page.Page, fontManager *FontManager) error {
+func RenderTextAreasOverlay(pageData *page.Page, fontManager *FontManager, outputDir string) error {
 	if pageData.Image == nil {
 		return fmt.Errorf("no image in page data")
 	}
@@ -82,15 +83,13 @@ func RenderTextAreasOverlay(pageData *page.Page, fontManager *FontManager) error
 		drawText(img, label, area.X, area.Y-2, fontManager.EnglishFont, areaColor)
 	}
 
-	// Generate random filename
-	randomID := *gut.Random("abcdefghijklmnopqrstuvwxyz0123456789", 4)
-	filename := fmt.Sprintf("generate/recognize/output_areas_%s.png", randomID)
+	filename := overlayFilename(outputDir, "areas")
 
 	return saveImage(img, filename)
 }
 
 // RenderLinesOverlay renders text lines with colored bounding boxes
-func RenderLinesOverlay(pageData *page.Page, fontManager *FontManager) error {
+func RenderLinesOverlay(pageData *page.Page, fontManager *FontManager, outputDir string) error {
 	if pageData.Image == nil {
 		return fmt.Errorf("no image in page data")
 	}
@@ -110,15 +109,13 @@ func RenderLinesOverlay(pageData *page.Page, fontManager *FontManager) error {
 		drawText(img, label, line.X, line.Y-2, fontManager.EnglishFont, lineColor)
 	}
 
-	// Generate random filename
-	randomID := *gut.Random("abcdefghijklmnopqrstuvwxyz0123456789", 4)
-	filename := fmt.Sprintf("generate/recognize/output_lines_%s.png", randomID)
+	filename := overlayFilename(outputDir, "lines")
 
 	return saveImage(img, filename)
 }
 
 // RenderWordsOverlay renders words with colored bounding boxes
-func RenderWordsOverlay(pageData *page.Page, fontManager *FontManager) error {
+func RenderWordsOverlay(pageData *page.Page, fontManager *FontManager, outputDir string) error {
 	if pageData.Image == nil {
 		return fmt.Errorf("no image in page data")
 	}
@@ -140,15 +137,23 @@ func RenderWordsOverlay(pageData *page.Page, fontManager *FontManager) error {
 		}
 	}
 
-	// Generate random filename
-	randomID := *gut.Random("abcdefghijklmnopqrstuvwxyz0123456789", 4)
-	filename := fmt.Sprintf("generate/recognize/output_words_%s.png", randomID)
+	filename := overlayFilename(outputDir, "words")
 
 	return saveImage(img, filename)
 }
 
-// RenderCharactersOverlay renders individual characters with bounding boxes and recognized text
-func RenderCharactersOverlay(pageData *page.Page, fontManager *FontManager) error {
+// CharacterColorMode selects how RenderCharactersOverlay colors each character's box
+type CharacterColorMode int
+
+const (
+	// ColorByIndex cycles a fixed palette so neighboring characters stand apart
+	ColorByIndex CharacterColorMode = iota
+	// ColorByConfidence runs from red at 0% confidence to green at 100%, unrecognized characters are red
+	ColorByConfidence
+)
+
+// RenderCharactersOverlay renders individual characters with bounding boxes and recognized text, colored by mode
+func RenderCharactersOverlay(pageData *page.Page, fontManager *FontManager, outputDir string, mode CharacterColorMode) error {
 	if pageData.Image == nil {
 		return fmt.Errorf("no image in page data")
 	}
@@ -161,6 +166,9 @@ func RenderCharactersOverlay(pageData *page.Page, fontManager *FontManager) erro
 	// Draw character bounding boxes
 	for idx, char := range pageData.Chars {
 		charColor := getCharColor(idx)
+		if mode == ColorByConfidence {
+			charColor = confidenceColor(char.Confidence)
+		}
 		drawRectangle(img, char.X, char.Y, char.Width, char.Height, charColor, 1)
 
 		// Draw recognized character above the box
@@ -178,15 +186,13 @@ func RenderCharactersOverlay(pageData *page.Page, fontManager *FontManager) erro
 		}
 	}
 
-	// Generate random filename
-	randomID := *gut.Random("abcdefghijklmnopqrstuvwxyz0123456789", 4)
-	filename := fmt.Sprintf("generate/recognize/output_chars_%s.png", randomID)
+	filename := overlayFilename(outputDir, "chars")
 
 	return saveImage(img, filename)
 }
 
 // RenderFullOverlay renders a comprehensive overlay with all elements
-func RenderFullOverlay(pageData *page.Page, fontManager *FontManager) error {
+func RenderFullOverlay(pageData *page.Page, fontManager *FontManager, outputDir string) error {
 	if pageData.Image == nil {
 		return fmt.Errorf("no image in page data")
 	}
@@ -226,15 +232,38 @@ func RenderFullOverlay(pageData *page.Page, fontManager *FontManager) error {
 		}
 	}
 
-	// Generate random filename
-	randomID := *gut.Random("abcdefghijklmnopqrstuvwxyz0123456789", 4)
-	filename := fmt.Sprintf("generate/recognize/output_full_%s.png", randomID)
+	filename := overlayFilename(outputDir, "full")
 
 	return saveImage(img, filename)
 }
 
 // Helper functions
 
+const overlayIDCharset = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// overlayRand drives overlay filename IDs when seeded, otherwise gut.Random is used
+var overlayRand *rand.Rand
+
+// SeedOverlayNames makes overlay filenames reproducible across runs with the same seed
+func SeedOverlayNames(seed int64) {
+	overlayRand = rand.New(rand.NewSource(seed))
+}
+
+func overlayFilename(outputDir, kind string) string {
+	var id string
+	if overlayRand == nil {
+		id = *gut.Random(overlayIDCharset, 4)
+	} else {
+		b := make([]byte, 4)
+		for i := range b {
+			b[i] = overlayIDCharset[overlayRand.Intn(len(overlayIDCharset))]
+		}
+		id = string(b)
+	}
+
+	return filepath.Join(outputDir, fmt.Sprintf("output_%s_%s.png", kind, id))
+}
+
 func loadFont(fontPath string, size float64) (font.Face, error) {
 	fontBytes, err := os.ReadFile(fontPath)
 	if err != nil {
@@ -330,22 +359,8 @@ func blendColors(base, overlay color.RGBA) color.RGBA {
 }
 
 func saveImage(img image.Image, filename string) error {
-	// Ensure directory exists
-	dir := filepath.Dir(filename)
-	err := os.MkdirAll(dir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create directory: %v", err)
-	}
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %v", err)
-	}
-	defer file.Close()
-
-	err = png.Encode(file, img)
-	if err != nil {
-		return fmt.Errorf("failed to encode PNG: %v", err)
+	if err := page.SavePNG(img, filename); err != nil {
+		return err
 	}
 
 	fmt.Printf("Saved overlay image: %s\n", filename)
@@ -386,6 +401,14 @@ func getWordColor(index int) color.RGBA {
 	return colors[index%len(colors)]
 }
 
+// confidenceColor places confidence, a percentage, on a red to green gradient
+func confidenceColor(confidence float64) color.RGBA {
+	confidence = math.Max(0, math.Min(100, confidence))
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	green := color.RGBA{R: 0, G: 200, B: 0, A: uint8(math.Round(confidence / 100 * 255))}
+	return blendColors(red, green)
+}
+
 func getCharColor(index int) color.RGBA {
 	colors := []color.RGBA{
 		{255, 0, 0, 255},     // Red
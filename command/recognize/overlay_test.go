@@ -0,0 +1,68 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/page"
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestConfidenceColor(t *testing.T) {
+	low, high := confidenceColor(0), confidenceColor(100)
+	if low == high {
+		t.Fatalf("Expected 0%% and 100%% confidence to get distinct colors, both got %v", low)
+	}
+	if low.R <= low.G || high.G <= high.R {
+		t.Errorf("Expected red at 0%% and green at 100%%, got %v and %v", low, high)
+	}
+	if confidenceColor(-10) != low || confidenceColor(150) != high {
+		t.Errorf("Expected confidence outside 0-100 to clamp to the ends of the gradient")
+	}
+}
+
+func TestRenderCharactersOverlayConfidence(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 80, 40))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	pageData := &page.Page{
+		Width:  80,
+		Height: 40,
+		Image:  img,
+		Chars: []*page.CharacterBounds{
+			{X: 10, Y: 10, Width: 10, Height: 10, Confidence: 0},
+			{X: 50, Y: 10, Width: 10, Height: 10, Confidence: 100},
+		},
+	}
+	fontManager := &FontManager{ThaiFont: basicfont.Face7x13, EnglishFont: basicfont.Face7x13}
+
+	outputDir := t.TempDir()
+	if err := RenderCharactersOverlay(pageData, fontManager, outputDir, ColorByConfidence); err != nil {
+		t.Fatalf("RenderCharactersOverlay failed: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(outputDir, "output_chars_*.png"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one characters overlay in %s, found %v (%v)", outputDir, files, err)
+	}
+	file, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("Failed to open the overlay: %v", err)
+	}
+	defer file.Close()
+	overlay, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode the overlay: %v", err)
+	}
+
+	// The left edge of each box, clear of the confidence label drawn below it
+	low := color.RGBAModel.Convert(overlay.At(10, 15)).(color.RGBA)
+	high := color.RGBAModel.Convert(overlay.At(50, 15)).(color.RGBA)
+	if low != confidenceColor(0) || high != confidenceColor(100) {
+		t.Errorf("Expected boxes colored %v and %v, got %v and %v", confidenceColor(0), confidenceColor(100), low, high)
+	}
+}
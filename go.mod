@@ -5,12 +5,12 @@ go 1.24.0
 toolchain go1.24.4
 
 require (
+	github.com/bsthun/gut v1.2.7
 	golang.org/x/image v0.31.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/bsthun/gut v1.2.7 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.6 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
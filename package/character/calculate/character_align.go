@@ -0,0 +1,39 @@
+package characterCalculate
+
+import (
+	"math"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
+)
+
+const (
+	AlignMaxAngle      = 20.0 // Largest tilt in degrees AlignPrincipalAxis corrects, steeper axes are taken as part of the glyph design
+	alignMinAnisotropy = 0.1  // Below this the principal axis of a glyph is too weak to trust
+)
+
+// AlignPrincipalAxis returns a copy rotated about its centroid so the principal axis is exactly horizontal or vertical,
+// whichever is nearer. Glyphs tilted by more than AlignMaxAngle or without a clear axis are returned unchanged.
+func AlignPrincipalAxis(char *character.Character) *character.Character {
+	reg := char.ToRegion()
+	moments := regionHelper.RegionComputeMoments(reg)
+	if moments["m00"] == 0 {
+		return char
+	}
+
+	mu20, mu02, mu11 := moments["mu20"], moments["mu02"], moments["mu11"]
+	if mu20+mu02 == 0 || math.Sqrt((mu20-mu02)*(mu20-mu02)+4*mu11*mu11)/(mu20+mu02) < alignMinAnisotropy {
+		return char
+	}
+
+	// Tilt of the axis from the nearest of horizontal and vertical, in (-π/4, π/4]
+	tilt := math.Mod(regionHelper.RegionComputeOrientation(moments), math.Pi/2)
+	if tilt > math.Pi/4 {
+		tilt -= math.Pi / 2
+	}
+	if tilt == 0 || math.Abs(tilt) > AlignMaxAngle*math.Pi/180 {
+		return char
+	}
+
+	return character.FromRegion(regionHelper.RegionRotate(reg, tilt), char.Config)
+}
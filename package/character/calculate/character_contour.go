@@ -0,0 +1,27 @@
+package characterCalculate
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// CharacterContour returns a copy holding only the boundary pixels of char, those with a 4-connected neighbor that is
+// blank or off the canvas. A stroke two pixels wide or thinner is all boundary and comes back unchanged
+func CharacterContour(char *character.Character) *character.Character {
+	contour := character.NewCharacter(char.SizeX, char.SizeY, char.Config)
+	for _, point := range char.Draws {
+		if !char.IsDrew(point.X, point.Y) || contour.IsDrew(point.X, point.Y) {
+			continue
+		}
+		if isBoundaryPixel(char, point.X, point.Y) {
+			contour.Draw(point.X, point.Y)
+		}
+	}
+	return contour
+}
+
+func isBoundaryPixel(char *character.Character, x, y uint16) bool {
+	if x == 0 || y == 0 || x+1 >= char.SizeX || y+1 >= char.SizeY {
+		return true
+	}
+	return !char.IsDrew(x-1, y) || !char.IsDrew(x+1, y) || !char.IsDrew(x, y-1) || !char.IsDrew(x, y+1)
+}
@@ -0,0 +1,57 @@
+package characterCalculate
+
+import (
+	"math"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
+)
+
+// CharacterDeshear returns a copy with the slant removed by the horizontal shear x - mu11/mu02 * (y - cy), the moment
+// based correction used for MNIST. Rows keep their position and the canvas widens to fit, a glyph without slant is
+// returned unchanged.
+func CharacterDeshear(char *character.Character) *character.Character {
+	moments := regionHelper.RegionComputeMoments(char.ToRegion())
+	if moments["m00"] == 0 || moments["mu02"] == 0 {
+		return char
+	}
+
+	shear := moments["mu11"] / moments["mu02"]
+	if math.Abs(shear) < 1e-3 {
+		return char
+	}
+	cy := moments["cy"]
+
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, point := range char.Draws {
+		if !char.IsDrew(point.X, point.Y) {
+			continue
+		}
+		x := float64(point.X) - shear*(float64(point.Y)-cy)
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+	}
+
+	// One pixel margin on either side like the other resampling helpers
+	originX := math.Floor(minX) - 1
+	sizeX := int(math.Ceil(maxX)-originX) + 2
+	if sizeX > math.MaxUint16 {
+		return char
+	}
+
+	desheared := character.NewCharacter(uint16(sizeX), char.SizeY, char.Config)
+	for y := 0; y < int(char.SizeY); y++ {
+		offset := shear * (float64(y) - cy)
+		for x := 0; x < sizeX; x++ {
+			// Inverse mapping keeps the straightened strokes free of holes
+			sx := math.Round(float64(x) + originX + offset)
+			if sx < 0 || sx >= float64(char.SizeX) {
+				continue
+			}
+			if char.IsDrew(uint16(sx), uint16(y)) {
+				desheared.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+
+	return desheared
+}
@@ -0,0 +1,44 @@
+package characterCalculate
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// CharacterMirrorHorizontal returns a copy flipped left to right on the same canvas, x becomes SizeX-1-x
+func CharacterMirrorHorizontal(char *character.Character) *character.Character {
+	mirrored := characterMirror(char, func(point *character.Point) (uint16, uint16) {
+		return char.SizeX - 1 - point.X, point.Y
+	})
+	if box := char.EdgeBox; box != nil {
+		size := float64(char.SizeX)
+		mirrored.EdgeBox = &character.EdgeBox{MinX: size - box.MaxX, MinY: box.MinY, MaxX: size - box.MinX, MaxY: box.MaxY}
+	}
+	return mirrored
+}
+
+// CharacterMirrorVertical returns a copy flipped top to bottom on the same canvas, y becomes SizeY-1-y
+func CharacterMirrorVertical(char *character.Character) *character.Character {
+	mirrored := characterMirror(char, func(point *character.Point) (uint16, uint16) {
+		return point.X, char.SizeY - 1 - point.Y
+	})
+	if box := char.EdgeBox; box != nil {
+		size := float64(char.SizeY)
+		mirrored.EdgeBox = &character.EdgeBox{MinX: box.MinX, MinY: size - box.MaxY, MaxX: box.MaxX, MaxY: size - box.MinY}
+	}
+	return mirrored
+}
+
+// characterMirror draws every inked pixel of char at its flipped position, analysis results are not carried over
+func characterMirror(char *character.Character, flip func(point *character.Point) (uint16, uint16)) *character.Character {
+	mirrored := character.NewCharacter(char.SizeX, char.SizeY, char.Config)
+	for _, point := range char.Draws {
+		if !char.IsDrew(point.X, point.Y) {
+			continue
+		}
+		x, y := flip(point)
+		if !mirrored.IsDrew(x, y) {
+			mirrored.Draw(x, y)
+		}
+	}
+	return mirrored
+}
@@ -0,0 +1,57 @@
+package characterCalculate
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// NormalizedCanvasSize is the side of the square canvas glyphs are resampled onto before feature extraction
+const NormalizedCanvasSize = 48
+
+// NormalizeToCanvas crops char to its ink and resamples it onto a size×size canvas. The longer side spans the canvas
+// less a one pixel margin and the shorter side is centered, so the aspect ratio is kept. A size below 3 returns char unchanged.
+func NormalizeToCanvas(char *character.Character, size uint16) *character.Character {
+	if size < 3 {
+		return char
+	}
+
+	normalized := character.NewCharacter(size, size, char.Config)
+	if char.IsEmpty() {
+		return normalized
+	}
+
+	minX, minY := int(char.BoundingBox["minX"]), int(char.BoundingBox["minY"])
+	width, height := int(char.GetBoundingBoxWidth()), int(char.GetBoundingBoxHeight())
+
+	span := int(size) - 2
+	scale := float64(span) / float64(max(width, height))
+	targetWidth := max(1, min(span, int(float64(width)*scale)))
+	targetHeight := max(1, min(span, int(float64(height)*scale)))
+	offsetX := 1 + (span-targetWidth)/2
+	offsetY := 1 + (span-targetHeight)/2
+
+	// Every source pixel covers the target pixels its footprint falls on, at least one, so strokes neither gap when
+	// upscaling nor vanish when downscaling
+	footprint := func(from, limit int) (int, int) {
+		start := min(limit-1, int(float64(from)*scale))
+		end := max(start+1, min(limit, int(float64(from+1)*scale)))
+		return start, end
+	}
+
+	for _, point := range char.Draws {
+		if !char.IsDrew(point.X, point.Y) {
+			continue
+		}
+		startX, endX := footprint(int(point.X)-minX, targetWidth)
+		startY, endY := footprint(int(point.Y)-minY, targetHeight)
+		for y := startY; y < endY; y++ {
+			for x := startX; x < endX; x++ {
+				tx, ty := uint16(offsetX+x), uint16(offsetY+y)
+				if !normalized.IsDrew(tx, ty) {
+					normalized.Draw(tx, ty)
+				}
+			}
+		}
+	}
+
+	return normalized
+}
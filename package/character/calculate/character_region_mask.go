@@ -0,0 +1,107 @@
+package characterCalculate
+
+import "github.com/bsthun/glyphcanvas/package/region"
+
+// regionMask holds row major bitsets of a region over a canvas shared by the regions being compared
+type regionMask struct {
+	stride int      // Words per row
+	draws  []uint64 // Every listed draw, whether or not it was erased later
+	ink    []uint64 // Pixels the region reports as drawn
+	halo   []uint64 // 8-neighbors of every draw, a draw is only included when another draw borders it
+
+	// Rows holding any draw, the halo extends one row further each way, empty when top > bottom
+	top, bottom int
+}
+
+// newRegionMasks builds masks for regions on one canvas large enough for every draw and its neighbors
+func newRegionMasks(regions []*region.Region) []*regionMask {
+	width, height := 0, 0
+	for _, reg := range regions {
+		for _, point := range reg.Draws {
+			width = max(width, int(point.X)+2)
+			height = max(height, int(point.Y)+2)
+		}
+	}
+	stride := (width + 63) / 64
+
+	masks := make([]*regionMask, len(regions))
+	for i, reg := range regions {
+		mask := &regionMask{
+			stride: stride,
+			draws:  make([]uint64, stride*height),
+			ink:    make([]uint64, stride*height),
+			halo:   make([]uint64, stride*height),
+			top:    height,
+			bottom: -1,
+		}
+		for _, point := range reg.Draws {
+			mask.top = min(mask.top, int(point.Y))
+			mask.bottom = max(mask.bottom, int(point.Y))
+			index := int(point.Y)*stride + int(point.X)/64
+			bit := uint64(1) << (point.X % 64)
+			mask.draws[index] |= bit
+			if reg.IsDrew(point.X, point.Y) {
+				mask.ink[index] |= bit
+			}
+		}
+		mask.dilate()
+		masks[i] = mask
+	}
+
+	return masks
+}
+
+func (m *regionMask) dilate() {
+	rows := len(m.draws) / max(m.stride, 1)
+	for y := max(m.top-1, 0); y <= min(m.bottom+1, rows-1); y++ {
+		halo := m.halo[y*m.stride : (y+1)*m.stride]
+		spreadRow(m.draws[y*m.stride:(y+1)*m.stride], halo, false)
+		if y > 0 {
+			spreadRow(m.draws[(y-1)*m.stride:y*m.stride], halo, true)
+		}
+		if y+1 < rows {
+			spreadRow(m.draws[(y+1)*m.stride:(y+2)*m.stride], halo, true)
+		}
+	}
+}
+
+// spreadRow ORs the left and right neighbors of every bit in row into out, and the bits themselves when center is set
+func spreadRow(row, out []uint64, center bool) {
+	for i, word := range row {
+		spread := word<<1 | word>>1
+		if i > 0 {
+			spread |= row[i-1] >> 63
+		}
+		if i+1 < len(row) {
+			spread |= row[i+1] << 63
+		}
+		if center {
+			spread |= word
+		}
+		out[i] |= spread
+	}
+}
+
+// touches reports whether a draw of m has an 8-neighbor drawn in other
+func (m *regionMask) touches(other *regionMask) bool {
+	// Ink only ever sits on draw rows, so only the overlap of the halo rows and the other's draw rows can match
+	top := max(m.top-1, other.top)
+	bottom := min(m.bottom+1, other.bottom)
+	for i := top * m.stride; i < (bottom+1)*m.stride; i++ {
+		if m.halo[i]&other.ink[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// merge mirrors mergeRegions, every draw of source becomes drawn in m
+func (m *regionMask) merge(source *regionMask) {
+	m.top = min(m.top, source.top)
+	m.bottom = max(m.bottom, source.bottom)
+	for i := range m.draws {
+		m.draws[i] |= source.draws[i]
+		m.ink[i] |= source.draws[i]
+		m.halo[i] |= source.halo[i]
+	}
+}
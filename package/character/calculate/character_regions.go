@@ -29,11 +29,19 @@ func CharacterBreakdownToRegions(char *character.Character) ([]*region.Region, e
 		}
 	}
 
-	// Step 2: Identify segmentation lines based on anchor points and medial axis
-	segmentationLines := identifySegmentationLines(char)
+	// Step 2: Grow stroke regions from the skeleton segments when selected, a glyph without any falls back to lines
+	var regions []*region.Region
+	switch char.Config.SegmentationMethod {
+	case character.SegmentationSkeleton:
+		regions = segmentBySkeleton(char)
+	case character.SegmentationWatershed:
+		regions = floodFromSkeleton(char, watershedFlood(char))
+	}
 
-	// Step 3: Segment the character using the identified lines
-	regions := segmentCharacter(char, segmentationLines)
+	// Step 3: Otherwise segment the character along lines from anchor points and the medial axis
+	if len(regions) == 0 {
+		regions = segmentCharacter(char, identifySegmentationLines(char))
+	}
 
 	// Step 4: Refine regions by merging small adjacent regions
 	refinedRegions := refineRegions(char, regions)
@@ -165,7 +173,7 @@ func createStrokeBoundaryLines(char *character.Character) []*SegmentationLine {
 	var lines []*SegmentationLine
 
 	// Analyze stroke width variations to identify natural segmentation points
-	strokeWidthMap := computeStrokeWidthMap(char)
+	strokeWidthMap := characterHelper.CharacterStrokeWidthMap(char)
 
 	// Find points where stroke width changes significantly
 	widthChangePoints := findStrokeWidthChangePoints(char, strokeWidthMap)
@@ -182,11 +190,13 @@ func createStrokeBoundaryLines(char *character.Character) []*SegmentationLine {
 	return lines
 }
 
+// findNearbyAnchors returns the anchors within maxDistance of anchor to draw segmentation lines to. A smooth_bend only
+// marks a gentle turn along one stroke, cutting there would split the stroke, so it is left out.
 func findNearbyAnchors(char *character.Character, anchor *character.AnchorPoint, maxDistance float64) []*character.AnchorPoint {
 	var nearby []*character.AnchorPoint
 
 	for _, other := range char.AnchorPoints {
-		if other == anchor {
+		if other == anchor || other.Type == "smooth_bend" {
 			continue
 		}
 
@@ -267,10 +277,14 @@ func findMedialAxisBranchingPoints(char *character.Character) []*character.Point
 func findNearestBoundaryPoints(char *character.Character, point *character.Point) []*character.Point {
 	var boundaryPoints []*character.Point
 
-	// Cast rays in multiple directions to find boundary intersections
-	directions := []float64{0, math.Pi / 4, math.Pi / 2, 3 * math.Pi / 4, math.Pi, 5 * math.Pi / 4, 3 * math.Pi / 2, 7 * math.Pi / 4}
+	rays := char.Config.BoundaryRayCount
+	if rays <= 0 {
+		rays = 8
+	}
 
-	for _, angle := range directions {
+	// Cast evenly spaced rays to find boundary intersections, more rays reach into narrow concavities
+	for i := 0; i < rays; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(rays)
 		boundaryPoint := castRayToBoundary(char, point, angle)
 		if boundaryPoint != nil {
 			boundaryPoints = append(boundaryPoints, boundaryPoint)
@@ -280,31 +294,50 @@ func findNearestBoundaryPoints(char *character.Character, point *character.Point
 	return boundaryPoints
 }
 
+// castRayToBoundary walks from start in the given direction and returns the last foreground pixel before the ray
+// leaves the stroke. The crossing between the last foreground and the first background sample is refined by bisection,
+// so a BoundaryRayStep above one pixel still lands on the boundary. A ray that leaves the canvas inside the stroke finds nothing.
 func castRayToBoundary(char *character.Character, start *character.Point, angle float64) *character.Point {
 	dx := math.Cos(angle)
 	dy := math.Sin(angle)
 
-	x := float64(start.X)
-	y := float64(start.Y)
-
-	for step := 0; step < int(math.Max(float64(char.SizeX), float64(char.SizeY))); step++ {
-		x += dx
-		y += dy
+	step := char.Config.BoundaryRayStep
+	if step <= 0 {
+		step = 1
+	}
 
-		nx := uint16(math.Round(x))
-		ny := uint16(math.Round(y))
+	// sample reports whether the ray point at distance is on the canvas and whether it is foreground
+	sample := func(distance float64) (bool, bool) {
+		x := math.Round(float64(start.X) + dx*distance)
+		y := math.Round(float64(start.Y) + dy*distance)
+		if x < 0 || y < 0 || x >= float64(char.SizeX) || y >= float64(char.SizeY) {
+			return false, false
+		}
+		return true, char.IsDrew(uint16(x), uint16(y))
+	}
 
-		// Check bounds
-		if nx >= char.SizeX || ny >= char.SizeY {
+	maxDistance := math.Max(float64(char.SizeX), float64(char.SizeY))
+	for distance := step; distance <= maxDistance; distance += step {
+		onCanvas, drawn := sample(distance)
+		if !onCanvas {
 			break
 		}
 
 		// Check if we've hit the boundary (transition from foreground to background)
-		if !char.IsDrew(nx, ny) {
-			// Go back one step to find the last foreground pixel
-			x -= dx
-			y -= dy
-			return &character.Point{X: uint16(math.Round(x)), Y: uint16(math.Round(y))}
+		if !drawn {
+			low, high := distance-step, distance
+			for high-low > 0.125 {
+				middle := (low + high) / 2
+				if _, drawn := sample(middle); drawn {
+					low = middle
+				} else {
+					high = middle
+				}
+			}
+			return &character.Point{
+				X: uint16(math.Round(float64(start.X) + dx*low)),
+				Y: uint16(math.Round(float64(start.Y) + dy*low)),
+			}
 		}
 	}
 
@@ -325,11 +358,12 @@ func findSkeletonBranchConnections(char *character.Character) []*SegmentationLin
 		}
 	}
 
-	// Connect endpoints of different branches if they're close
+	// Connect endpoints of different branches if they're close, in branch key order so the lines come out the same every run
 	branches := make([]string, 0, len(branchEndpoints))
 	for branchID := range branchEndpoints {
 		branches = append(branches, branchID)
 	}
+	sort.Strings(branches)
 
 	for i, branch1 := range branches {
 		for j, branch2 := range branches {
@@ -360,76 +394,17 @@ func findSkeletonBranchConnections(char *character.Character) []*SegmentationLin
 	return lines
 }
 
-func computeStrokeWidthMap(char *character.Character) map[string]float64 {
-	strokeWidths := make(map[string]float64)
-
-	// For each medial axis point, compute the stroke width
-	for _, point := range char.MedialAxis {
-		width := computeLocalStrokeWidth(char, point)
-		key := getPointKey(point)
-		strokeWidths[key] = width
-	}
-
-	return strokeWidths
-}
-
-func computeLocalStrokeWidth(char *character.Character, point *character.Point) float64 {
-	// Cast rays in perpendicular directions to find stroke boundaries
-	maxWidth := 0.0
-
-	directions := []float64{0, math.Pi / 4, math.Pi / 2, 3 * math.Pi / 4}
-
-	for _, angle := range directions {
-		width1 := castRayToBackground(char, point, angle)
-		width2 := castRayToBackground(char, point, angle+math.Pi)
-		totalWidth := width1 + width2
-
-		if totalWidth > maxWidth {
-			maxWidth = totalWidth
-		}
-	}
-
-	return maxWidth
-}
-
-func castRayToBackground(char *character.Character, start *character.Point, angle float64) float64 {
-	dx := math.Cos(angle)
-	dy := math.Sin(angle)
-
-	x := float64(start.X)
-	y := float64(start.Y)
-	distance := 0.0
-
-	for step := 0; step < 50; step++ { // Limit search distance
-		x += dx
-		y += dy
-		distance += 1.0
-
-		nx := uint16(math.Round(x))
-		ny := uint16(math.Round(y))
-
-		// Check bounds or background
-		if nx >= char.SizeX || ny >= char.SizeY || !char.IsDrew(nx, ny) {
-			return distance
-		}
-	}
-
-	return distance
-}
-
-func findStrokeWidthChangePoints(char *character.Character, strokeWidths map[string]float64) []*character.Point {
+func findStrokeWidthChangePoints(char *character.Character, strokeWidths map[character.Point]float64) []*character.Point {
 	var changePoints []*character.Point
 	threshold := 2.0 // Significant change threshold
 
 	for _, point := range char.MedialAxis {
-		key := getPointKey(point)
-		currentWidth := strokeWidths[key]
+		currentWidth := strokeWidths[*point]
 
 		// Check neighboring medial axis points for width changes
 		neighbors := findMedialAxisNeighbors(char, point)
 		for _, neighbor := range neighbors {
-			neighborKey := getPointKey(neighbor)
-			neighborWidth := strokeWidths[neighborKey]
+			neighborWidth := strokeWidths[*neighbor]
 
 			if math.Abs(currentWidth-neighborWidth) > threshold {
 				changePoints = append(changePoints, point)
@@ -441,7 +416,7 @@ func findStrokeWidthChangePoints(char *character.Character, strokeWidths map[str
 	return changePoints
 }
 
-func computePerpendicularStrokeLine(char *character.Character, point *character.Point, strokeWidths map[string]float64) *SegmentationLine {
+func computePerpendicularStrokeLine(char *character.Character, point *character.Point, strokeWidths map[character.Point]float64) *SegmentationLine {
 	// Find the direction of the stroke at this point
 	strokeDirection := computeLocalStrokeDirection(char, point)
 
@@ -502,8 +477,8 @@ func findMedialAxisNeighbors(char *character.Character, point *character.Point)
 }
 
 func filterSegmentationLines(char *character.Character, lines []*SegmentationLine) []*SegmentationLine {
-	// Sort by strength (descending)
-	sort.Slice(lines, func(i, j int) bool {
+	// Sort by strength (descending), equal strengths keep their generation order
+	sort.SliceStable(lines, func(i, j int) bool {
 		return lines[i].Strength > lines[j].Strength
 	})
 
@@ -543,7 +518,7 @@ func linesOverlap(line1, line2 *SegmentationLine, threshold float64) bool {
 
 func segmentCharacter(char *character.Character, segmentationLines []*SegmentationLine) []*region.Region {
 	// Create initial region containing all character pixels
-	regions := []*region.Region{createRegionFromCharacter(char)}
+	regions := []*region.Region{char.ToRegion()}
 
 	// Apply each segmentation line to split regions
 	for _, line := range segmentationLines {
@@ -553,16 +528,6 @@ func segmentCharacter(char *character.Character, segmentationLines []*Segmentati
 	return regions
 }
 
-func createRegionFromCharacter(char *character.Character) *region.Region {
-	reg := region.NewRegion(char.SizeX, char.SizeY)
-
-	for _, point := range char.Draws {
-		reg.Draw(point.X, point.Y)
-	}
-
-	return reg
-}
-
 func applySemgentationLine(char *character.Character, regions []*region.Region, line *SegmentationLine) []*region.Region {
 	var newRegions []*region.Region
 
@@ -574,37 +539,206 @@ func applySemgentationLine(char *character.Character, regions []*region.Region,
 	return newRegions
 }
 
+// splitRegionByLine cuts reg along the segmentation line. The pixels the segment passes over are taken out and the rest
+// is labeled into 8-connected components, so a line that leaves the region in one piece reverts to the whole region
+// instead of carving a connected stroke in two. A cut straight across a uniform stroke also reverts, only a neck
+// narrower than the ink beside it splits. Each component becomes a region and the cut pixels rejoin a component they
+// border, preferring one on their side of the line
 func splitRegionByLine(reg *region.Region, line *SegmentationLine) []*region.Region {
-	// Simple implementation: split based on which side of the line pixels are on
-	region1 := region.NewRegion(reg.GetSizeX(), reg.GetSizeY())
-	region2 := region.NewRegion(reg.GetSizeX(), reg.GetSizeY())
-
+	cut := make(map[[2]int]bool)
+	labels := make(map[[2]int]int)
 	for _, point := range reg.Draws {
-		side := getPointSideOfLine(point, line)
-		if side >= 0 {
-			region1.Draw(point.X, point.Y)
+		if !reg.IsDrew(point.X, point.Y) {
+			continue
+		}
+		key := [2]int{int(point.X), int(point.Y)}
+		if distanceToSegment(point, line) <= segmentationCutRadius {
+			cut[key] = true
 		} else {
-			region2.Draw(point.X, point.Y)
+			labels[key] = -1
 		}
 	}
 
-	// Return non-empty regions
-	var result []*region.Region
-	if len(region1.Draws) > 0 {
-		result = append(result, region1)
-	}
-	if len(region2.Draws) > 0 {
-		result = append(result, region2)
+	components := 0
+	for _, start := range reg.Draws {
+		key := [2]int{int(start.X), int(start.Y)}
+		if label, ok := labels[key]; !ok || label >= 0 {
+			continue
+		}
+
+		labels[key] = components
+		stack := [][2]int{key}
+		for len(stack) > 0 {
+			point := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					next := [2]int{point[0] + dx, point[1] + dy}
+					if label, ok := labels[next]; ok && label < 0 {
+						labels[next] = components
+						stack = append(stack, next)
+					}
+				}
+			}
+		}
+		components++
 	}
 
-	// If no split occurred, return original region
-	if len(result) == 0 {
+	if components < 2 || !segmentationCutIsNeck(reg, cut, line) {
 		return []*region.Region{reg}
 	}
 
+	// Cut pixels take the label of a labeled neighbor, a wide cut fills in from its edges over several passes. Each
+	// pass only reads labels of the previous one so the result does not depend on the order pixels are visited in
+	pending := make([][2]int, 0, len(cut))
+	for _, point := range reg.Draws {
+		key := [2]int{int(point.X), int(point.Y)}
+		if cut[key] {
+			pending = append(pending, key)
+			delete(cut, key)
+		}
+	}
+	for len(pending) > 0 {
+		assigned := make(map[[2]int]int)
+		var remaining [][2]int
+		for _, key := range pending {
+			side := getPointSideOfLine(&region.Point{X: uint16(key[0]), Y: uint16(key[1])}, line) >= 0
+			label, sameSide := -1, false
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					next := [2]int{key[0] + dx, key[1] + dy}
+					neighbor, ok := labels[next]
+					if !ok {
+						continue
+					}
+					nextSide := getPointSideOfLine(&region.Point{X: uint16(next[0]), Y: uint16(next[1])}, line) >= 0
+					if label < 0 || (nextSide == side && !sameSide) {
+						label, sameSide = neighbor, nextSide == side
+					}
+				}
+			}
+			if label >= 0 {
+				assigned[key] = label
+			} else {
+				remaining = append(remaining, key)
+			}
+		}
+		if len(assigned) == 0 {
+			// Cut pixels no component reaches stay with the first one
+			for _, key := range remaining {
+				assigned[key] = 0
+			}
+			remaining = nil
+		}
+		for key, label := range assigned {
+			labels[key] = label
+		}
+		pending = remaining
+	}
+
+	result := make([]*region.Region, components)
+	for i := range result {
+		result[i] = region.NewRegion(reg.GetSizeX(), reg.GetSizeY())
+	}
+	for _, point := range reg.Draws {
+		label, ok := labels[[2]int{int(point.X), int(point.Y)}]
+		if ok && !result[label].IsDrew(point.X, point.Y) {
+			result[label].Draw(point.X, point.Y)
+		}
+	}
+
 	return result
 }
 
+// segmentationCutRadius is how far from a segmentation line a pixel is cut, wide enough that a diagonal cut leaves
+// no 8-connected gap
+const segmentationCutRadius = 0.75
+
+// segmentationNeckRatio is how much narrower than the ink beside it a cut has to be to count as a neck
+const segmentationNeckRatio = 0.75
+
+// segmentationCutIsNeck reports whether the ink run along line through the cut pixels is clearly shorter than the
+// cross-section of the stroke next to it on either side. The cross-sections are measured parallel to the line up to
+// twice the cut width away, so a junction where a stroke meets a wider one counts while a bar cut across does not
+func segmentationCutIsNeck(reg *region.Region, cut map[[2]int]bool, line *SegmentationLine) bool {
+	dx := float64(line.EndPoint.X) - float64(line.StartPoint.X)
+	dy := float64(line.EndPoint.Y) - float64(line.StartPoint.Y)
+	length := math.Hypot(dx, dy)
+	if length == 0 || len(cut) == 0 {
+		return false
+	}
+	ux, uy := dx/length, dy/length
+
+	// The cut pixel nearest to the centroid of the cut anchors every cross-section
+	sumX, sumY := 0.0, 0.0
+	for key := range cut {
+		sumX += float64(key[0])
+		sumY += float64(key[1])
+	}
+	meanX, meanY := sumX/float64(len(cut)), sumY/float64(len(cut))
+	centerX, centerY, best := 0.0, 0.0, math.MaxFloat64
+	for _, point := range reg.Draws {
+		if !cut[[2]int{int(point.X), int(point.Y)}] {
+			continue
+		}
+		if distance := math.Hypot(float64(point.X)-meanX, float64(point.Y)-meanY); distance < best {
+			centerX, centerY, best = float64(point.X), float64(point.Y), distance
+		}
+	}
+
+	width := inkRunLength(reg, centerX, centerY, ux, uy)
+	maxOffset := 2 * width
+	for _, sign := range []float64{-1, 1} {
+		for offset := 1; offset <= maxOffset; offset++ {
+			x := centerX - sign*uy*float64(offset)
+			y := centerY + sign*ux*float64(offset)
+			if !regionInkAt(reg, x, y) {
+				break
+			}
+			if float64(width) < segmentationNeckRatio*float64(inkRunLength(reg, x, y, ux, uy)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// inkRunLength counts the ink pixels met walking from (x, y) along (ux, uy) in both directions until the first gap
+func inkRunLength(reg *region.Region, x, y, ux, uy float64) int {
+	if !regionInkAt(reg, x, y) {
+		return 0
+	}
+	run := 1
+	for _, sign := range []float64{-1, 1} {
+		for step := 1.0; regionInkAt(reg, x+sign*ux*step, y+sign*uy*step); step++ {
+			run++
+		}
+	}
+	return run
+}
+
+func regionInkAt(reg *region.Region, x, y float64) bool {
+	px, py := math.Round(x), math.Round(y)
+	if px < 0 || py < 0 || px >= float64(reg.GetSizeX()) || py >= float64(reg.GetSizeY()) {
+		return false
+	}
+	return reg.IsDrew(uint16(px), uint16(py))
+}
+
+func distanceToSegment(point *region.Point, line *SegmentationLine) float64 {
+	x1, y1 := float64(line.StartPoint.X), float64(line.StartPoint.Y)
+	x2, y2 := float64(line.EndPoint.X), float64(line.EndPoint.Y)
+	x, y := float64(point.X), float64(point.Y)
+
+	dx, dy := x2-x1, y2-y1
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, ((x-x1)*dx+(y-y1)*dy)/length))
+	}
+	return math.Hypot(x-(x1+t*dx), y-(y1+t*dy))
+}
+
 func getPointSideOfLine(point *region.Point, line *SegmentationLine) float64 {
 	// Use cross product to determine which side of the line the point is on
 	x1, y1 := float64(line.StartPoint.X), float64(line.StartPoint.Y)
@@ -617,17 +751,21 @@ func getPointSideOfLine(point *region.Point, line *SegmentationLine) float64 {
 func refineRegions(char *character.Character, regions []*region.Region) []*region.Region {
 	// Merge small adjacent regions
 	minSize := char.Config.MinRegionSize
+	masks := newRegionMasks(regions)
 
 	var refined []*region.Region
-	for _, reg := range regions {
+	var refinedMasks []*regionMask
+	for i, reg := range regions {
 		if uint16(len(reg.Draws)) >= minSize {
 			refined = append(refined, reg)
+			refinedMasks = append(refinedMasks, masks[i])
 		} else {
 			// Try to merge with adjacent larger region
 			merged := false
-			for _, other := range refined {
-				if regionsAreAdjacent(reg, other) {
+			for j, other := range refined {
+				if masks[i].touches(refinedMasks[j]) {
 					mergeRegions(other, reg)
+					refinedMasks[j].merge(masks[i])
 					merged = true
 					break
 				}
@@ -635,6 +773,7 @@ func refineRegions(char *character.Character, regions []*region.Region) []*regio
 			if !merged {
 				// Keep small region if it can't be merged
 				refined = append(refined, reg)
+				refinedMasks = append(refinedMasks, masks[i])
 			}
 		}
 	}
@@ -642,25 +781,26 @@ func refineRegions(char *character.Character, regions []*region.Region) []*regio
 	return refined
 }
 
-func regionsAreAdjacent(reg1, reg2 *region.Region) bool {
-	// Check if regions share any adjacent pixels
-	for _, point1 := range reg1.Draws {
-		for dx := int16(-1); dx <= 1; dx++ {
-			for dy := int16(-1); dy <= 1; dy++ {
-				if dx == 0 && dy == 0 {
-					continue
-				}
-
-				nx := uint16(int16(point1.X) + dx)
-				ny := uint16(int16(point1.Y) + dy)
+// RegionAdjacencyGraph maps each region index to the ascending indices of the regions it touches with 8-connectivity.
+// Every index is present, regions that touch nothing map to nil
+func RegionAdjacencyGraph(regions []*region.Region) map[int][]int {
+	graph := make(map[int][]int, len(regions))
+	for i := range regions {
+		graph[i] = nil
+	}
 
-				if reg2.IsDrew(nx, ny) {
-					return true
-				}
+	masks := newRegionMasks(regions)
+	for i := range regions {
+		for j := i + 1; j < len(regions); j++ {
+			if masks[i].touches(masks[j]) {
+				graph[i] = append(graph[i], j)
+				graph[j] = append(graph[j], i)
 			}
 		}
 	}
-	return false
+
+	// Pairs are visited in order so lists are already ascending
+	return graph
 }
 
 func mergeRegions(target, source *region.Region) {
@@ -670,13 +810,28 @@ func mergeRegions(target, source *region.Region) {
 }
 
 func analyzeRegions(regions []*region.Region) []*region.Region {
-	// Apply existing region analysis to each region
-	// This would use the existing region helper functions
-	// For now, just return the regions as-is since the existing
-	// analysis functions can be applied separately
+	// Record where each region sits, the rest of the region analysis is applied separately by callers
+	for _, reg := range regions {
+		reg.OffsetX, reg.OffsetY = regionCentroid(reg)
+	}
 	return regions
 }
 
-func getPointKey(point *character.Point) string {
-	return string(rune(point.X)) + "," + string(rune(point.Y))
+// regionCentroid averages the drawn pixels, points drawn more than once by a merge count once
+func regionCentroid(reg *region.Region) (float64, float64) {
+	seen := make(map[uint32]bool, len(reg.Draws))
+	var sumX, sumY float64
+	for _, point := range reg.Draws {
+		key := uint32(point.X)<<16 | uint32(point.Y)
+		if seen[key] || !reg.IsDrew(point.X, point.Y) {
+			continue
+		}
+		seen[key] = true
+		sumX += float64(point.X)
+		sumY += float64(point.Y)
+	}
+	if len(seen) == 0 {
+		return 0, 0
+	}
+	return sumX / float64(len(seen)), sumY / float64(len(seen))
 }
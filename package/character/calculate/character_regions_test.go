@@ -0,0 +1,319 @@
+package characterCalculate
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/package/region"
+	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
+)
+
+// createScatteredRegions draws count small blobs of random size at random spots, some overlapping or touching
+func createScatteredRegions(count int, size uint16, seed int64) []*region.Region {
+	random := rand.New(rand.NewSource(seed))
+	regions := make([]*region.Region, count)
+	for i := range regions {
+		reg := region.NewRegion(size, size)
+		x0, y0 := uint16(random.Intn(int(size)-4)), uint16(random.Intn(int(size)-4))
+		for x := x0; x < x0+uint16(1+random.Intn(4)); x++ {
+			for y := y0; y < y0+uint16(1+random.Intn(4)); y++ {
+				reg.Draw(x, y)
+			}
+		}
+		regions[i] = reg
+	}
+	return regions
+}
+
+// regionsAreAdjacentReference is the direct per pixel neighbor scan the masks must agree with
+func regionsAreAdjacentReference(reg1, reg2 *region.Region) bool {
+	for _, point := range reg1.Draws {
+		for dx := int16(-1); dx <= 1; dx++ {
+			for dy := int16(-1); dy <= 1; dy++ {
+				if (dx != 0 || dy != 0) && reg2.IsDrew(uint16(int16(point.X)+dx), uint16(int16(point.Y)+dy)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func TestRegionsAreAdjacentMatchesReference(t *testing.T) {
+	regions := createScatteredRegions(80, 70, 7)
+	// Erased pixels stay in Draws but no longer count as drawn
+	for i := 0; i < len(regions); i += 5 {
+		point := regions[i].Draws[0]
+		regions[i].Erase(point.X, point.Y)
+	}
+	// Regions on a different canvas size are compared on a shared one
+	regions = append(regions, region.NewRegion(200, 30))
+	regions[len(regions)-1].Draw(69, 10)
+	regions[len(regions)-1].Draw(150, 20)
+
+	masks := newRegionMasks(regions)
+	adjacent := 0
+	for i := range regions {
+		for j := range regions {
+			if i == j {
+				continue
+			}
+			want := regionsAreAdjacentReference(regions[i], regions[j])
+			if got := masks[i].touches(masks[j]); got != want {
+				t.Fatalf("regions %d and %d: mask says %v, reference %v", i, j, got, want)
+			}
+			if want {
+				adjacent++
+			}
+		}
+	}
+	if adjacent == 0 {
+		t.Fatalf("fixture has no adjacent regions")
+	}
+
+	graph := RegionAdjacencyGraph(regions)
+	for i := range regions {
+		var want []int
+		for j := range regions {
+			if i != j && regionsAreAdjacentReference(regions[min(i, j)], regions[max(i, j)]) {
+				want = append(want, j)
+			}
+		}
+		if !slices.Equal(graph[i], want) {
+			t.Errorf("graph[%d] = %v, want %v", i, graph[i], want)
+		}
+	}
+}
+
+func TestRefineRegionsMatchesReference(t *testing.T) {
+	char := character.NewCharacter(70, 70, nil)
+	refined := refineRegions(char, createScatteredRegions(120, 70, 3))
+
+	// Same merge loop using the reference scan on a fresh copy of the fixture
+	var want []*region.Region
+	for _, reg := range createScatteredRegions(120, 70, 3) {
+		merged := false
+		if uint16(len(reg.Draws)) < char.Config.MinRegionSize {
+			for _, other := range want {
+				if regionsAreAdjacentReference(reg, other) {
+					mergeRegions(other, reg)
+					merged = true
+					break
+				}
+			}
+		}
+		if !merged {
+			want = append(want, reg)
+		}
+	}
+
+	if len(refined) != len(want) {
+		t.Fatalf("refined to %d regions, reference %d", len(refined), len(want))
+	}
+	for i := range refined {
+		if len(refined[i].Draws) != len(want[i].Draws) {
+			t.Errorf("region %d has %d draws, reference %d", i, len(refined[i].Draws), len(want[i].Draws))
+		}
+	}
+}
+
+func BenchmarkRegionAdjacencyGraph(b *testing.B) {
+	regions := createScatteredRegions(300, 120, 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RegionAdjacencyGraph(regions)
+	}
+}
+
+func TestCharacterBreakdownRegionOffsets(t *testing.T) {
+	// A T shape, the bar and the stem end up as separate regions
+	char := character.NewCharacter(40, 40, nil)
+	for x := uint16(6); x < 34; x++ {
+		for y := uint16(6); y < 11; y++ {
+			char.Draw(x, y)
+		}
+	}
+	for x := uint16(18); x < 23; x++ {
+		for y := uint16(11); y < 34; y++ {
+			char.Draw(x, y)
+		}
+	}
+
+	regions, err := CharacterBreakdownToRegions(char)
+	if err != nil {
+		t.Fatalf("CharacterBreakdownToRegions failed: %v", err)
+	}
+	if len(regions) == 0 {
+		t.Fatal("Expected regions for the T shape")
+	}
+
+	for i, reg := range regions {
+		moments := regionHelper.RegionComputeMoments(reg)
+		if moments["m00"] == 0 {
+			continue
+		}
+		centerX, centerY := moments["m10"]/moments["m00"], moments["m01"]/moments["m00"]
+		if math.Abs(reg.OffsetX-centerX) > 1e-9 || math.Abs(reg.OffsetY-centerY) > 1e-9 {
+			t.Errorf("Expected region %d offset at its centroid (%.2f, %.2f), got (%.2f, %.2f)", i, centerX, centerY, reg.OffsetX, reg.OffsetY)
+		}
+
+		position := reg.RelativePosition(char.SizeX, char.SizeY)
+		if position != [2]float64{reg.OffsetX / 40, reg.OffsetY / 40} {
+			t.Errorf("Expected region %d relative position %v to be its offset over the character size", i, position)
+		}
+	}
+}
+
+func TestFindNearbyAnchorsSkipsSmoothBends(t *testing.T) {
+	char := character.NewCharacter(40, 40, nil)
+	junction := &character.AnchorPoint{Point: &character.Point{X: 10, Y: 10}, Type: "junction"}
+	bend := &character.AnchorPoint{Point: &character.Point{X: 14, Y: 10}, Type: "smooth_bend"}
+	corner := &character.AnchorPoint{Point: &character.Point{X: 18, Y: 10}, Type: "corner"}
+	char.AnchorPoints = []*character.AnchorPoint{junction, bend, corner}
+
+	nearby := findNearbyAnchors(char, junction, 20)
+	if len(nearby) != 1 || nearby[0] != corner {
+		t.Errorf("Expected only the corner near the junction, got %d anchors", len(nearby))
+	}
+}
+
+func TestSplitRegionByLineKeepsConnectedStrokes(t *testing.T) {
+	// A short cut down the middle, it does not reach the edges of the bar
+	cut := &SegmentationLine{StartPoint: &character.Point{X: 30, Y: 22}, EndPoint: &character.Point{X: 30, Y: 27}}
+
+	bar := region.NewRegion(60, 50)
+	for x := uint16(10); x < 50; x++ {
+		for y := uint16(20); y < 30; y++ {
+			bar.Draw(x, y)
+		}
+	}
+	if split := splitRegionByLine(bar, cut); len(split) != 1 || split[0] != bar {
+		t.Errorf("Expected a cut that leaves the bar connected to keep it whole, got %d regions", len(split))
+	}
+
+	// A cut straight across the bar disconnects it, but the bar is as wide on both sides so there is no neck to split
+	across := &SegmentationLine{StartPoint: &character.Point{X: 30, Y: 15}, EndPoint: &character.Point{X: 30, Y: 35}}
+	if split := splitRegionByLine(bar, across); len(split) != 1 || split[0] != bar {
+		t.Errorf("Expected a full cut through a uniform bar to keep it whole, got %d regions", len(split))
+	}
+
+	// Two blocks joined by a neck the same cut crosses completely
+	dumbbell := region.NewRegion(60, 50)
+	for x := uint16(10); x < 50; x++ {
+		for y := uint16(10); y < 40; y++ {
+			if x < 25 || x >= 35 || (y >= 23 && y < 27) {
+				dumbbell.Draw(x, y)
+			}
+		}
+	}
+	split := splitRegionByLine(dumbbell, cut)
+	if len(split) != 2 {
+		t.Fatalf("Expected the cut through the neck to split the dumbbell in 2, got %d regions", len(split))
+	}
+	total := 0
+	for i, reg := range split {
+		left, _, right, _ := reg.ContentBounds()
+		if left < 30 && right > 30 {
+			t.Errorf("Expected region %d to stay on one side of the cut, spans x %d to %d", i, left, right)
+		}
+		total += len(reg.Draws)
+	}
+	if total != len(dumbbell.Draws) {
+		t.Errorf("Expected the split regions to hold all %d dumbbell pixels, got %d", len(dumbbell.Draws), total)
+	}
+}
+
+func TestCharacterBreakdownSkeletonSegmentation(t *testing.T) {
+	// A '+' of two 6 pixel strokes crossing at the center
+	char := character.NewCharacter(48, 48, nil)
+	char.Config.SegmentationMethod = character.SegmentationSkeleton
+	for i := uint16(4); i < 44; i++ {
+		for j := uint16(21); j < 27; j++ {
+			for _, point := range [][2]uint16{{i, j}, {j, i}} {
+				if !char.IsDrew(point[0], point[1]) {
+					char.Draw(point[0], point[1])
+				}
+			}
+		}
+	}
+
+	regions, err := CharacterBreakdownToRegions(char)
+	if err != nil {
+		t.Fatalf("CharacterBreakdownToRegions failed: %v", err)
+	}
+	if len(regions) != 4 {
+		t.Fatalf("Expected a region per arm of the '+', got %d", len(regions))
+	}
+
+	total := 0
+	for i, reg := range regions {
+		left, top, right, bottom := reg.ContentBounds()
+		// Each arm reaches one edge of the glyph and stops around the center
+		if (left < 15 && right > 33) || (top < 15 && bottom > 33) {
+			t.Errorf("Expected region %d to cover one arm, spans %d,%d to %d,%d", i, left, top, right, bottom)
+		}
+		total += len(reg.Draws)
+	}
+	if total != char.GetPixelCount() {
+		t.Errorf("Expected the arms to hold all %d pixels, got %d", char.GetPixelCount(), total)
+	}
+}
+
+func TestWatershedRegionsMultiRegion(t *testing.T) {
+	char := createTestCharacterMultiRegion()
+	regions := WatershedRegions(char)
+
+	// The square, the disc and the two crossing lines between them
+	part := func(point *region.Point) string {
+		switch {
+		case point.X <= 8 && point.Y <= 8:
+			return "square"
+		case point.X >= 14 && point.Y >= 14:
+			return "disc"
+		default:
+			return "lines"
+		}
+	}
+
+	counts := map[string]int{}
+	for i, reg := range regions {
+		parts := map[string]bool{}
+		for _, point := range reg.Draws {
+			parts[part(point)] = true
+		}
+		if len(parts) != 1 {
+			t.Errorf("Expected region %d to lie in one part, spans %v", i, parts)
+		}
+		for name := range parts {
+			counts[name]++
+		}
+
+		if parts["lines"] {
+			left, top, right, bottom := reg.ContentBounds()
+			if left != right && top != bottom {
+				t.Errorf("Expected region %d to be a single straight stroke, spans %d,%d to %d,%d", i, left, top, right, bottom)
+			}
+		}
+	}
+
+	if counts["square"] != 1 || counts["disc"] != 1 {
+		t.Errorf("Expected the square and the disc to stay whole, got %d and %d regions", counts["square"], counts["disc"])
+	}
+	if counts["lines"] < 2 {
+		t.Errorf("Expected the crossing lines to split into strokes, got %d regions", counts["lines"])
+	}
+
+	char.ClearAnalysisResults()
+	char.Config.SegmentationMethod = character.SegmentationWatershed
+	broken, err := CharacterBreakdownToRegions(char)
+	if err != nil {
+		t.Fatalf("CharacterBreakdownToRegions failed: %v", err)
+	}
+	if len(broken) == 0 || len(broken) > len(regions) {
+		t.Errorf("Expected the watershed breakdown to refine the %d watershed regions, got %d", len(regions), len(broken))
+	}
+}
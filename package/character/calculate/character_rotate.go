@@ -0,0 +1,20 @@
+package characterCalculate
+
+import (
+	"math"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
+)
+
+// CharacterRotate returns a copy turned by degrees about its centroid on a canvas fitted to the rotated ink, positive
+// angles turn clockwise on screen since y points down. Analysis results are not carried over, a zero angle or a
+// blank character returns char itself.
+func CharacterRotate(char *character.Character, degrees float64) *character.Character {
+	if degrees == 0 || char.IsEmpty() {
+		return char
+	}
+
+	// RegionRotate makes the direction at theta horizontal, that is it turns the ink by -theta
+	return character.FromRegion(regionHelper.RegionRotate(char.ToRegion(), -degrees*math.Pi/180), char.Config)
+}
@@ -0,0 +1,93 @@
+package characterCalculate
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+// segmentBySkeleton cuts the medial axis at its junctions and grows every skeleton segment into a region. Growing is a
+// breadth first flood through the ink from all segments at once, so each pixel joins the segment it is nearest to and
+// the pixels around a junction are shared out between the strokes meeting there. Returns nil without a medial axis
+func segmentBySkeleton(char *character.Character) []*region.Region {
+	return floodFromSkeleton(char, func(labels map[[2]int]int, queue [][2]int) {
+		for len(queue) > 0 {
+			point := queue[0]
+			queue = queue[1:]
+			forEachInkNeighbor(char, point, func(next [2]int) {
+				if _, ok := labels[next]; !ok {
+					labels[next] = labels[point]
+					queue = append(queue, next)
+				}
+			})
+		}
+	})
+}
+
+// floodFromSkeleton seeds a label per skeleton segment and lets flood spread the labels from the seed pixels through
+// the ink. Ink the flood does not reach, like a dot whose skeleton was pruned, is flooded again from its first pixel
+// and becomes a region per connected piece. Returns nil without a medial axis
+func floodFromSkeleton(char *character.Character, flood func(labels map[[2]int]int, seeds [][2]int)) []*region.Region {
+	segments := char.SkeletonSegments()
+	if len(segments) == 0 {
+		return nil
+	}
+
+	labels := make(map[[2]int]int, len(char.Draws))
+	var seeds [][2]int
+	for label, segment := range segments {
+		for _, point := range segment {
+			key := [2]int{int(point.X), int(point.Y)}
+			if _, ok := labels[key]; !ok && char.IsDrew(point.X, point.Y) {
+				labels[key] = label
+				seeds = append(seeds, key)
+			}
+		}
+	}
+	flood(labels, seeds)
+
+	count := len(segments)
+	for _, point := range char.Draws {
+		key := [2]int{int(point.X), int(point.Y)}
+		if _, ok := labels[key]; ok || !char.IsDrew(point.X, point.Y) {
+			continue
+		}
+		labels[key] = count
+		count++
+		flood(labels, [][2]int{key})
+	}
+
+	regions := make([]*region.Region, count)
+	for i := range regions {
+		regions[i] = region.NewRegion(char.SizeX, char.SizeY)
+	}
+	for _, point := range char.Draws {
+		label, ok := labels[[2]int{int(point.X), int(point.Y)}]
+		if ok && !regions[label].IsDrew(point.X, point.Y) {
+			regions[label].Draw(point.X, point.Y)
+		}
+	}
+
+	// A segment whose pixels were all erased grows nothing
+	kept := regions[:0]
+	for _, reg := range regions {
+		if len(reg.Draws) > 0 {
+			kept = append(kept, reg)
+		}
+	}
+	return kept
+}
+
+// forEachInkNeighbor calls visit with every drawn 8-neighbor of point on the canvas
+func forEachInkNeighbor(char *character.Character, point [2]int, visit func(next [2]int)) {
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			next := [2]int{point[0] + dx, point[1] + dy}
+			if next == point || next[0] < 0 || next[1] < 0 || next[0] >= int(char.SizeX) || next[1] >= int(char.SizeY) {
+				continue
+			}
+			if char.IsDrew(uint16(next[0]), uint16(next[1])) {
+				visit(next)
+			}
+		}
+	}
+}
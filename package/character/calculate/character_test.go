@@ -1,11 +1,20 @@
 package characterCalculate
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"image"
+	"math"
+	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/bsthun/glyphcanvas/package/character"
 	"github.com/bsthun/glyphcanvas/package/character/helper"
+	"github.com/bsthun/glyphcanvas/package/region"
+	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
+	"github.com/bsthun/glyphcanvas/test"
 )
 
 func TestCharacterBasicFunctionality(t *testing.T) {
@@ -62,6 +71,60 @@ func TestCharacterAnchorDetection(t *testing.T) {
 	}
 }
 
+func TestCharacterAnchorBendAndCorner(t *testing.T) {
+	// A one pixel outline traced in order, a square whose top side dips gently by about 23 degrees at (40, 16) and
+	// whose bottom side has a sharp notch up to (40, 55), both away from the extremes the extremum anchors take
+	char := character.NewCharacter(80, 80, nil)
+	outline := [][2]float64{{10, 10}, {40, 16}, {70, 10}, {70, 70}, {45, 70}, {40, 55}, {35, 70}, {10, 70}, {10, 10}}
+	for i := 0; i+1 < len(outline); i++ {
+		x0, y0, x1, y1 := outline[i][0], outline[i][1], outline[i+1][0], outline[i+1][1]
+		steps := math.Max(math.Abs(x1-x0), math.Abs(y1-y0))
+		for s := 0.0; s < steps; s++ {
+			x := uint16(math.Round(x0 + (x1-x0)*s/steps))
+			y := uint16(math.Round(y0 + (y1-y0)*s/steps))
+			if !char.IsDrew(x, y) {
+				char.Draw(x, y)
+			}
+		}
+	}
+
+	if err := characterHelper.CharacterDetectAnchors(char); err != nil {
+		t.Fatalf("Anchor detection failed: %v", err)
+	}
+
+	near := func(anchorType string, x, y float64) bool {
+		for _, anchor := range char.GetAnchorPointsByType(anchorType) {
+			if math.Abs(float64(anchor.Point.X)-x) <= 3 && math.Abs(float64(anchor.Point.Y)-y) <= 3 {
+				return true
+			}
+		}
+		return false
+	}
+	if !near("smooth_bend", 40, 16) {
+		t.Errorf("Expected a smooth_bend anchor at the gentle bend, got %v", describeAnchors(char))
+	}
+	if !near("sharp_corner", 40, 55) {
+		t.Errorf("Expected a sharp_corner anchor at the notch, got %v", describeAnchors(char))
+	}
+
+	// Raising the sharp corner multiplier past the notch's curvature leaves it a plain corner
+	char.Config.SharpCornerMultiplier = 6
+	if err := characterHelper.CharacterDetectAnchors(char); err != nil {
+		t.Fatalf("Anchor detection failed: %v", err)
+	}
+	if !near("corner", 40, 55) || near("sharp_corner", 40, 55) {
+		t.Errorf("Expected a plain corner at the notch with the raised multiplier, got %v", describeAnchors(char))
+	}
+}
+
+func describeAnchors(char *character.Character) []string {
+	var anchors []string
+	for _, anchor := range char.AnchorPoints {
+		anchors = append(anchors, fmt.Sprintf("%s(%d,%d)", anchor.Type, anchor.Point.X, anchor.Point.Y))
+	}
+	return anchors
+}
+
 func TestCharacterMedialAxis(t *testing.T) {
 	// Create a test character
 	char := createTestCharacterWithThickness()
@@ -108,6 +171,53 @@ func TestCharacterRegionBreakdown(t *testing.T) {
 	}
 }
 
+func TestCharacterRegionAdjacencyGraph(t *testing.T) {
+	char := createTestCharacterMultiRegion()
+
+	// Split the fixture into the square, the circle and the two arms of the cross
+	regions := make([]*region.Region, 4)
+	for i := range regions {
+		regions[i] = region.NewRegion(char.SizeX, char.SizeY)
+	}
+	for _, point := range char.Draws {
+		switch {
+		case point.X <= 8 && point.Y <= 8:
+			regions[0].Draw(point.X, point.Y)
+		case point.X >= 14 && point.Y >= 14:
+			regions[1].Draw(point.X, point.Y)
+		case point.X == 10:
+			regions[3].Draw(point.X, point.Y)
+		default:
+			regions[2].Draw(point.X, point.Y)
+		}
+	}
+
+	graph := RegionAdjacencyGraph(regions)
+	expected := map[int][]int{0: nil, 1: nil, 2: {3}, 3: {2}}
+	if !reflect.DeepEqual(graph, expected) {
+		t.Errorf("Expected adjacency %v, got %v", expected, graph)
+	}
+
+	// Whatever the breakdown produces, the graph must be symmetric
+	broken, err := CharacterBreakdownToRegions(createTestCharacterMultiRegion())
+	if err != nil {
+		t.Fatalf("Region breakdown failed: %v", err)
+	}
+	graph = RegionAdjacencyGraph(broken)
+	if len(graph) != len(broken) {
+		t.Errorf("Expected an entry for each of %d regions, got %d", len(broken), len(graph))
+	}
+	for i, neighbors := range graph {
+		for _, j := range neighbors {
+			if !slices.Contains(graph[j], i) {
+				t.Errorf("Region %d lists %d but not the reverse", i, j)
+			}
+		}
+	}
+
+	fmt.Printf("Adjacency of %d broken down regions: %v\n", len(broken), graph)
+}
+
 func TestCharacterComprehensiveAnalysis(t *testing.T) {
 	// Create a complex test character
 	char := createTestCharacterComplex()
@@ -156,12 +266,215 @@ func TestCharacterConfiguration(t *testing.T) {
 		t.Error("Custom configuration not applied correctly")
 	}
 
+	// A smooth bend above the corner threshold would leave the curvatures between them without any anchor
+	config.SmoothBendThreshold = config.CurvatureThreshold * 1.5
+	if err := config.Validate(); err == nil {
+		t.Error("Expected a smooth bend threshold above the curvature threshold to be rejected")
+	}
+	config.SmoothBendThreshold = config.CurvatureThreshold
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected a smooth bend threshold equal to the curvature threshold to pass, got %v", err)
+	}
+
 	fmt.Printf("Using custom config with anchor threshold: %.2f\n",
 		char.Config.AnchorDetectionThreshold)
 }
 
 // Helper functions to create test characters
 
+func TestCharacterPBMRoundTrip(t *testing.T) {
+	char := createTestCharacterComplex()
+
+	var buffer bytes.Buffer
+	if err := char.WritePBM(&buffer); err != nil {
+		t.Fatalf("WritePBM failed: %v", err)
+	}
+
+	loaded, err := character.ReadPBM(&buffer)
+	if err != nil {
+		t.Fatalf("ReadPBM failed: %v", err)
+	}
+
+	if loaded.ContentHash() != char.ContentHash() {
+		t.Error("Content hash should survive a PBM round trip")
+	}
+
+	fmt.Printf("Round-tripped %dx%d character with %d pixels\n", loaded.SizeX, loaded.SizeY, loaded.GetPixelCount())
+
+	// Raw variant: 10x2 with the first and last pixel of each row set
+	raw := []byte("P4\n# fixture\n10 2\n")
+	raw = append(raw, 0x80, 0x40, 0x80, 0x40)
+	loaded, err = character.ReadPBM(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadPBM raw failed: %v", err)
+	}
+
+	if loaded.GetPixelCount() != 4 || !loaded.IsDrew(0, 1) || !loaded.IsDrew(9, 1) {
+		t.Errorf("Raw PBM decoded incorrectly: %d pixels", loaded.GetPixelCount())
+	}
+}
+
+func TestCharacterErrors(t *testing.T) {
+	_, err := character.ReadPBM(bytes.NewReader([]byte("P6\n2 2\n")))
+	if !errors.Is(err, character.ErrImageDecode) {
+		t.Errorf("Expected ErrImageDecode for an unsupported format, got %v", err)
+	}
+
+	config := character.DefaultCharacterConfig()
+	config.MaxGlyphSize = 16
+	oversized := createTestCharacterComplex()
+	oversized.Config = config
+	if err := characterHelper.CharacterComprehensiveAnalysis(oversized); !errors.Is(err, character.ErrGlyphTooLarge) {
+		t.Errorf("Expected ErrGlyphTooLarge, got %v", err)
+	}
+
+	// 0 disables the limit
+	config.MaxGlyphSize = 0
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected a MaxGlyphSize of 0 to be valid, got %v", err)
+	}
+	if err := characterHelper.CharacterComprehensiveAnalysis(oversized); errors.Is(err, character.ErrGlyphTooLarge) {
+		t.Errorf("Expected no size limit with a MaxGlyphSize of 0, got %v", err)
+	}
+
+	// A filled 160x160 disk cannot finish the basic analysis step within 1ms
+	config = character.DefaultCharacterConfig()
+	config.ComputationTimeout = 1
+	disk := character.NewCharacter(160, 160, config)
+	for x := uint16(0); x < 160; x++ {
+		for y := uint16(0); y < 160; y++ {
+			dx, dy := float64(x)-80, float64(y)-80
+			if dx*dx+dy*dy < 75*75 {
+				disk.Draw(x, y)
+			}
+		}
+	}
+	err = characterHelper.CharacterComprehensiveAnalysis(disk)
+	if !errors.Is(err, character.ErrAnalysisTimeout) {
+		t.Errorf("Expected ErrAnalysisTimeout, got %v", err)
+	}
+
+	fmt.Printf("Analysis stopped with: %v\n", err)
+}
+
+func TestCharacterLoadFromImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 3, 1))
+	img.Pix = []uint8{0, 128, 255}
+
+	dark := character.LoadFromImage(img, 200, nil)
+	if !dark.IsDrew(1, 0) || dark.GetPixelCount() != 2 {
+		t.Errorf("Expected mid-gray to be foreground at threshold 200, got %d pixels", dark.GetPixelCount())
+	}
+
+	light := character.LoadFromImage(img, 100, nil)
+	if light.IsDrew(1, 0) || light.GetPixelCount() != 1 {
+		t.Errorf("Expected mid-gray to be background at threshold 100, got %d pixels", light.GetPixelCount())
+	}
+
+	if character.LoadFromImage(img, character.DefaultForegroundThreshold, nil).GetPixelCount() != 1 {
+		t.Error("Expected only black to be foreground at the default threshold")
+	}
+}
+
+func TestCharacterRegionBridge(t *testing.T) {
+	char := createTestCharacterComplex()
+
+	reg := char.ToRegion()
+	if reg.GetSizeX() != char.SizeX || reg.GetSizeY() != char.SizeY {
+		t.Errorf("Region size %dx%d should match character size %dx%d", reg.GetSizeX(), reg.GetSizeY(), char.SizeX, char.SizeY)
+	}
+
+	// Erased region pixels must not come back
+	reg.Draw(1, 1)
+	reg.Erase(1, 1)
+
+	restored := character.FromRegion(reg, nil)
+	if restored.ContentHash() != char.ContentHash() {
+		t.Error("Pixel set should survive a character to region round trip")
+	}
+
+	if restored.GetPixelCount() != char.GetPixelCount() {
+		t.Errorf("Restored character has %d pixels, expected %d", restored.GetPixelCount(), char.GetPixelCount())
+	}
+
+	fmt.Printf("Bridged character with %d pixels through region\n", restored.GetPixelCount())
+}
+
+func TestCharacterAnchorSpatialQuery(t *testing.T) {
+	char := createTestCharacterWithCorners()
+	char.AddAnchorPoint(2, 2, "corner", 0.9, 1.2, 0)
+	char.AddAnchorPoint(15, 2, "corner", 0.8, 1.1, 0)
+	char.AddAnchorPoint(2, 15, "terminal", 0.7, 0, 0)
+	char.AddAnchorPoint(9, 9, "junction", 1.0, 0, 0)
+
+	inTopRow := char.AnchorPointsInRect(image.Rect(0, 0, 20, 5))
+	if len(inTopRow) != 2 {
+		t.Errorf("Expected 2 anchors in top rows, got %d", len(inTopRow))
+	}
+
+	// Max bound is exclusive like image.Rectangle
+	if len(char.AnchorPointsInRect(image.Rect(0, 0, 9, 9))) != 1 {
+		t.Error("Anchor on the exclusive max edge should not be included")
+	}
+
+	nearest := char.NearestAnchor(10, 8)
+	if nearest == nil || nearest.Type != "junction" {
+		t.Errorf("Expected nearest anchor to be the junction, got %v", nearest)
+	}
+
+	nearest = char.NearestAnchor(0, 19)
+	if nearest == nil || nearest.Point.X != 2 || nearest.Point.Y != 15 {
+		t.Errorf("Expected nearest anchor at (2,15), got %v", nearest)
+	}
+
+	empty := character.NewCharacter(5, 5, nil)
+	if empty.NearestAnchor(1, 1) != nil {
+		t.Error("Character without anchors should have no nearest anchor")
+	}
+
+	fmt.Printf("Found %d anchors in top rows\n", len(inTopRow))
+}
+
+func TestCharacterDominantDirections(t *testing.T) {
+	char := createTestCharacterV()
+	directions := characterHelper.CharacterDominantDirections(char, 8)
+	if len(directions) != 8 {
+		t.Fatalf("Expected 8 direction bins, got %d", len(directions))
+	}
+
+	// Both arms of the V lean about 63 degrees from horizontal, landing in bins 3 and 5 with nothing horizontal or vertical
+	if directions[3] < 0.3 || directions[5] < 0.3 {
+		t.Errorf("Expected two dominant diagonal directions, got %.2f", directions)
+	}
+	if directions[0] > 0.05 || directions[4] > 0.05 {
+		t.Errorf("Expected no horizontal or vertical strokes, got %.2f", directions)
+	}
+
+	fmt.Printf("V stroke directions: %.2f\n", directions)
+}
+
+func createTestCharacterV() *character.Character {
+	// Two thick strokes meeting at the bottom, drawn along true diagonals
+	char := character.NewCharacter(80, 80, nil)
+	stroke := func(x0, y0, x1, y1 float64) {
+		length := math.Hypot(x1-x0, y1-y0)
+		for s := 0.0; s <= length; s += 0.5 {
+			cx, cy := x0+(x1-x0)*s/length, y0+(y1-y0)*s/length
+			for dx := -3.0; dx <= 3; dx++ {
+				for dy := -3.0; dy <= 3; dy++ {
+					x, y := uint16(math.Round(cx+dx)), uint16(math.Round(cy+dy))
+					if dx*dx+dy*dy <= 9 && !char.IsDrew(x, y) {
+						char.Draw(x, y)
+					}
+				}
+			}
+		}
+	}
+	stroke(10, 8, 40, 70)
+	stroke(70, 8, 40, 70)
+	return char
+}
+
 func createTestCharacterWithCorners() *character.Character {
 	// Create a rectangular character with clear corners
 	char := character.NewCharacter(15, 15, nil)
@@ -294,3 +607,661 @@ func BenchmarkCharacterAnalysis(b *testing.B) {
 		}
 	}
 }
+
+func TestCharacterNormalizeToCanvas(t *testing.T) {
+	// A 10x4 bar away from the origin fills the canvas width and keeps its aspect ratio
+	bar := character.NewCharacter(30, 30, nil)
+	for x := uint16(5); x < 15; x++ {
+		for y := uint16(7); y < 11; y++ {
+			bar.Draw(x, y)
+		}
+	}
+
+	normalized := NormalizeToCanvas(bar, 48)
+	if normalized.SizeX != 48 || normalized.SizeY != 48 {
+		t.Fatalf("Expected a 48x48 canvas, got %dx%d", normalized.SizeX, normalized.SizeY)
+	}
+	width, height := normalized.GetBoundingBoxWidth(), normalized.GetBoundingBoxHeight()
+	if width != 46 || height < 17 || height > 19 {
+		t.Errorf("Expected a 46 wide bar about 18 tall, got %dx%d", width, height)
+	}
+	if normalized.BoundingBox["minX"] != 1 || normalized.BoundingBox["minY"] != (48-height)/2 {
+		t.Errorf("Expected the bar centered with a 1px margin, got origin (%d, %d)", normalized.BoundingBox["minX"], normalized.BoundingBox["minY"])
+	}
+	if normalized.GetPixelCount() != int(width)*int(height) {
+		t.Errorf("Expected a solid bar after upscaling, got %d pixels in %dx%d", normalized.GetPixelCount(), width, height)
+	}
+
+	// A one pixel line survives downscaling without gaps
+	line := character.NewCharacter(200, 5, nil)
+	for x := uint16(0); x < 200; x++ {
+		line.Draw(x, 2)
+	}
+	shrunk := NormalizeToCanvas(line, 48)
+	if shrunk.GetPixelCount() != 46 || shrunk.GetBoundingBoxHeight() != 1 {
+		t.Errorf("Expected a 46x1 line, got %d pixels %dx%d", shrunk.GetPixelCount(), shrunk.GetBoundingBoxWidth(), shrunk.GetBoundingBoxHeight())
+	}
+
+	if NormalizeToCanvas(bar, 2) != bar {
+		t.Errorf("Expected a canvas below 3 pixels to return the character unchanged")
+	}
+
+	fmt.Printf("Normalized 10x4 bar to %dx%d on a 48x48 canvas\n", width, height)
+}
+
+func TestCharacterAlignPrincipalAxis(t *testing.T) {
+	bar := func(angle float64) *character.Character {
+		char := character.NewCharacter(80, 80, nil)
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for l := -25.0; l <= 25; l += 0.5 {
+			for w := -3.0; w <= 3; w += 0.5 {
+				x := uint16(math.Round(40 + l*cos - w*sin))
+				y := uint16(math.Round(40 + l*sin + w*cos))
+				if !char.IsDrew(x, y) {
+					char.Draw(x, y)
+				}
+			}
+		}
+		return char
+	}
+
+	for _, angle := range []float64{10, -15, 80} {
+		aligned := AlignPrincipalAxis(bar(angle))
+		width, height := aligned.GetBoundingBoxWidth(), aligned.GetBoundingBoxHeight()
+		if min(width, height) > 10 {
+			t.Errorf("Expected a bar tilted %v degrees to become axis aligned, got %dx%d", angle, width, height)
+		}
+	}
+
+	steep := bar(40)
+	if AlignPrincipalAxis(steep) != steep {
+		t.Errorf("Expected a bar tilted beyond AlignMaxAngle to be returned unchanged")
+	}
+
+	square := character.NewCharacter(20, 20, nil)
+	for x := uint16(5); x < 15; x++ {
+		for y := uint16(5); y < 15; y++ {
+			square.Draw(x, y)
+		}
+	}
+	if AlignPrincipalAxis(square) != square {
+		t.Errorf("Expected a glyph without a clear axis to be returned unchanged")
+	}
+
+	aligned := AlignPrincipalAxis(bar(10))
+	fmt.Printf("Aligned bar tilted 10 degrees to %dx%d\n", aligned.GetBoundingBoxWidth(), aligned.GetBoundingBoxHeight())
+}
+
+func TestCharacterDeshear(t *testing.T) {
+	// An I leaning right by 0.3 pixels per row, like an italic scan
+	sheared := character.NewCharacter(60, 50, nil)
+	for y := 5; y < 45; y++ {
+		offset := int(math.Round(0.3 * float64(45-y)))
+		for x := 20; x < 26; x++ {
+			sheared.Draw(uint16(x+offset), uint16(y))
+		}
+	}
+
+	upright := CharacterDeshear(sheared)
+	if width := upright.GetBoundingBoxWidth(); width > 8 {
+		t.Errorf("Expected a near vertical stroke at most 8 wide, got %d", width)
+	}
+	if upright.GetBoundingBoxHeight() != sheared.GetBoundingBoxHeight() {
+		t.Errorf("Expected the stroke height %d kept, got %d", sheared.GetBoundingBoxHeight(), upright.GetBoundingBoxHeight())
+	}
+
+	moments := regionHelper.RegionComputeMoments(upright.ToRegion())
+	if slant := moments["mu11"] / moments["mu02"]; math.Abs(slant) > 0.02 {
+		t.Errorf("Expected no remaining slant, got %v", slant)
+	}
+
+	straight := character.NewCharacter(20, 20, nil)
+	for y := uint16(2); y < 18; y++ {
+		straight.Draw(9, y)
+		straight.Draw(10, y)
+	}
+	if CharacterDeshear(straight) != straight {
+		t.Errorf("Expected an upright stroke to be returned unchanged")
+	}
+
+	fmt.Printf("Deshearing a %d wide slanted I gave a %d wide stroke\n", sheared.GetBoundingBoxWidth(), upright.GetBoundingBoxWidth())
+}
+
+func TestCharacterBridgeDashes(t *testing.T) {
+	dashes := func(rows ...int) *character.Character {
+		char := character.NewCharacter(60, 30, nil)
+		for _, row := range rows {
+			// Five dashes 6 long and 2 thick with gaps of 3
+			for start := 5; start < 50; start += 9 {
+				for x := start; x < start+6; x++ {
+					char.Draw(uint16(x), uint16(row))
+					char.Draw(uint16(x), uint16(row+1))
+				}
+			}
+		}
+		return char
+	}
+	components := func(char *character.Character) int {
+		visited := map[character.Point]bool{}
+		count := 0
+		for _, start := range char.Draws {
+			if visited[*start] {
+				continue
+			}
+			count++
+			stack := []*character.Point{start}
+			visited[*start] = true
+			for len(stack) > 0 {
+				point := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, next := range char.Draws {
+					if !visited[*next] && math.Abs(float64(next.X)-float64(point.X)) <= 1 && math.Abs(float64(next.Y)-float64(point.Y)) <= 1 {
+						visited[*next] = true
+						stack = append(stack, next)
+					}
+				}
+			}
+		}
+		return count
+	}
+
+	line := dashes(10)
+	if bridges := characterHelper.CharacterBridgeDashes(line, 4); bridges != 4 {
+		t.Errorf("Expected 4 bridges, got %d", bridges)
+	}
+	if count := components(line); count != 1 {
+		t.Errorf("Expected the dashed line to become one stroke, got %d components", count)
+	}
+	if height := line.GetBoundingBoxHeight(); height != 2 {
+		t.Errorf("Expected bridges as thick as the dashes, got height %d", height)
+	}
+
+	equals := dashes(8, 16)
+	characterHelper.CharacterBridgeDashes(equals, 4)
+	if count := components(equals); count != 2 {
+		t.Errorf("Expected parallel dashed lines to stay apart, got %d components", count)
+	}
+
+	wide := dashes(10)
+	if bridges := characterHelper.CharacterBridgeDashes(wide, 2); bridges != 0 {
+		t.Errorf("Expected gaps wider than maxGap left open, got %d bridges", bridges)
+	}
+
+	fmt.Printf("Bridged a dashed line into %d component\n", components(line))
+}
+
+func TestCharacterSkeletonEndpoints(t *testing.T) {
+	// An E with 5 pixel strokes, arms centered on rows 6, 20 and 33 ending at column 25
+	char := character.NewCharacter(30, 40, nil)
+	for y := 4; y < 36; y++ {
+		for x := 4; x < 26; x++ {
+			spine := x < 9
+			arm := y < 9 || (y >= 18 && y < 23) || y >= 31
+			if spine || arm {
+				char.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+
+	if endpoints := char.SkeletonEndpoints(); len(endpoints) != 0 {
+		t.Errorf("Expected no endpoints before the medial axis is computed, got %d", len(endpoints))
+	}
+
+	err := characterHelper.CharacterComputeMedialAxis(char)
+	if err != nil {
+		t.Fatalf("Medial axis computation failed: %v", err)
+	}
+
+	endpoints := char.SkeletonEndpoints()
+	if len(endpoints) != 3 {
+		t.Fatalf("Expected 3 endpoints for the arms of E, got %d: %v", len(endpoints), endpoints)
+	}
+	for i, arm := range []int{6, 20, 33} {
+		endpoint := endpoints[i]
+		if endpoint.X < 21 || math.Abs(float64(endpoint.Y)-float64(arm)) > 1 {
+			t.Errorf("Expected endpoint %d at the right end of the arm on row %d, got (%d, %d)", i, arm, endpoint.X, endpoint.Y)
+		}
+	}
+
+	junctions := char.SkeletonJunctions()
+	if len(junctions) != 1 {
+		t.Fatalf("Expected 1 junction where the middle arm meets the spine, got %d: %v", len(junctions), junctions)
+	}
+	if junction := junctions[0]; junction.X > 8 || math.Abs(float64(junction.Y)-20) > 1 {
+		t.Errorf("Expected the junction on the spine at row 20, got (%d, %d)", junction.X, junction.Y)
+	}
+
+	fmt.Printf("E skeleton has %d endpoints and %d junctions\n", len(endpoints), len(junctions))
+}
+
+func TestCharacterMedialAxisKeepsThinStrokes(t *testing.T) {
+	// A one pixel L is its own skeleton, a coarse epsilon must not drop any of it
+	config := character.DefaultCharacterConfig()
+	config.MedialAxisEpsilon = 3
+	char := character.NewCharacter(30, 30, config)
+	for i := uint16(5); i < 25; i++ {
+		char.Draw(5, i)
+		if i > 5 {
+			char.Draw(i, 24)
+		}
+	}
+
+	err := characterHelper.CharacterComputeMedialAxis(char)
+	if err != nil {
+		t.Fatalf("Medial axis computation failed: %v", err)
+	}
+
+	if len(char.MedialAxis) != char.GetPixelCount() {
+		t.Errorf("Expected all %d pixels of the thin L on the medial axis, got %d", char.GetPixelCount(), len(char.MedialAxis))
+	}
+	if endpoints := char.SkeletonEndpoints(); len(endpoints) != 2 {
+		t.Errorf("Expected the thin L to stay one stroke with 2 endpoints, got %d: %v", len(endpoints), endpoints)
+	}
+}
+
+func TestCharacterAnalysisKeepsOtherResults(t *testing.T) {
+	char := createTestCharacterWithThickness()
+
+	err := characterHelper.CharacterComputeMedialAxis(char)
+	if err != nil {
+		t.Fatalf("Medial axis computation failed: %v", err)
+	}
+	medialAxis := len(char.MedialAxis)
+	if medialAxis == 0 {
+		t.Fatal("Expected a medial axis for the test character")
+	}
+
+	err = characterHelper.CharacterDetectAnchors(char)
+	if err != nil {
+		t.Fatalf("Anchor detection failed: %v", err)
+	}
+	if len(char.MedialAxis) != medialAxis {
+		t.Errorf("Expected the medial axis of %d points to survive anchor detection, got %d", medialAxis, len(char.MedialAxis))
+	}
+
+	anchors := len(char.AnchorPoints)
+	err = characterHelper.CharacterDetectAnchors(char)
+	if err != nil {
+		t.Fatalf("Anchor detection failed: %v", err)
+	}
+	if len(char.AnchorPoints) != anchors {
+		t.Errorf("Expected repeated anchor detection to replace the %d anchors, got %d", anchors, len(char.AnchorPoints))
+	}
+
+	fmt.Printf("Medial axis kept %d points next to %d anchors\n", len(char.MedialAxis), len(char.AnchorPoints))
+}
+
+func TestCharacterBreakdownDeterministic(t *testing.T) {
+	type box struct {
+		draws                  int
+		minX, minY, maxX, maxY uint16
+	}
+	breakdown := func(char *character.Character) []box {
+		regions, err := CharacterBreakdownToRegions(char)
+		if err != nil {
+			t.Fatalf("Breakdown failed: %v", err)
+		}
+		var boxes []box
+		for _, reg := range regions {
+			minX, minY, maxX, maxY := reg.ContentBounds()
+			boxes = append(boxes, box{len(reg.Draws), minX, minY, maxX, maxY})
+		}
+		return boxes
+	}
+
+	for name, create := range map[string]func() *character.Character{
+		"complex":      createTestCharacterComplex,
+		"multi region": createTestCharacterMultiRegion,
+		"thick cross":  createTestCharacterWithThickness,
+	} {
+		first := breakdown(create())
+		for run := 0; run < 5; run++ {
+			if again := breakdown(create()); !reflect.DeepEqual(first, again) {
+				t.Errorf("Expected the %s breakdown to repeat %v, got %v", name, first, again)
+				break
+			}
+		}
+	}
+
+	// Short skeleton branches close together produce many connection lines, map order must not leak into them
+	char := character.NewCharacter(40, 40, nil)
+	for i := 0; i < 8; i++ {
+		x := uint16(4 + 4*i)
+		char.SkeletonBranches[fmt.Sprintf("branch_%d", i)] = []*character.Point{{X: x, Y: 10}, {X: x + 2, Y: 12}}
+	}
+	lines := findSkeletonBranchConnections(char)
+	for run := 0; run < 5; run++ {
+		again := findSkeletonBranchConnections(char)
+		if len(again) != len(lines) {
+			t.Fatalf("Expected %d branch connections every run, got %d", len(lines), len(again))
+		}
+		for i := range lines {
+			if *lines[i].StartPoint != *again[i].StartPoint || *lines[i].EndPoint != *again[i].EndPoint {
+				t.Fatalf("Expected branch connection %d to repeat, got a different order", i)
+			}
+		}
+	}
+
+	fmt.Printf("Breakdown repeated with %d branch connections\n", len(lines))
+}
+
+func TestCharacterBoundaryRays(t *testing.T) {
+	// A solid square with a thin notch cut in at 22.5 degrees, reaching to 5 pixels from the center
+	char := character.NewCharacter(49, 49, nil)
+	sin, cos := math.Sincos(math.Pi / 8)
+	for y := 4; y < 45; y++ {
+		for x := 4; x < 45; x++ {
+			dx, dy := float64(x-24), float64(y-24)
+			along, across := dx*cos+dy*sin, -dx*sin+dy*cos
+			if along >= 5 && math.Abs(across) < 1.5 {
+				continue
+			}
+			char.Draw(uint16(x), uint16(y))
+		}
+	}
+	center := &character.Point{X: 24, Y: 24}
+
+	nearest := func(rays int, step float64) float64 {
+		char.Config.BoundaryRayCount = rays
+		char.Config.BoundaryRayStep = step
+		best := math.Inf(1)
+		for _, point := range findNearestBoundaryPoints(char, center) {
+			best = math.Min(best, computeDistance(center, point))
+		}
+		return best
+	}
+
+	if distance := nearest(8, 1); distance < 10 {
+		t.Errorf("Expected 8 rays to miss the notch, nearest boundary at %v", distance)
+	}
+	if distance := nearest(16, 1); distance > 6 {
+		t.Errorf("Expected 16 rays to find the notch within 6 pixels, got %v", distance)
+	}
+	// Coarse steps jump over the notch edge, bisection brings the point back to it
+	if distance := nearest(16, 4); distance > 6 {
+		t.Errorf("Expected 16 coarse rays to find the notch within 6 pixels, got %v", distance)
+	}
+
+	fmt.Printf("Nearest boundary with 8 rays at %.1f, with 16 rays at %.1f\n", nearest(8, 1), nearest(16, 1))
+}
+
+func TestCharacterStrokeWidthMap(t *testing.T) {
+	// Horizontal strokes 40 long, one 5 wide throughout and one tapering from 11 down to 1
+	uniform := character.NewCharacter(50, 20, nil)
+	tapering := character.NewCharacter(50, 20, nil)
+	for x := 5; x < 45; x++ {
+		half := 5 - (x-5)/8
+		for y := 10 - half; y <= 10+half; y++ {
+			tapering.Draw(uint16(x), uint16(y))
+		}
+		for y := 8; y <= 12; y++ {
+			uniform.Draw(uint16(x), uint16(y))
+		}
+	}
+
+	widths := characterHelper.CharacterStrokeWidthMap(uniform)
+	if len(widths) == 0 {
+		t.Fatal("Expected stroke widths along the medial axis")
+	}
+	if width := widths[character.Point{X: 25, Y: 10}]; width != 5 {
+		t.Errorf("Expected a width of 5 at the middle of the uniform stroke, got %v", width)
+	}
+
+	uniformMean, uniformVariance := characterHelper.CharacterStrokeWidthStats(uniform)
+	taperingMean, taperingVariance := characterHelper.CharacterStrokeWidthStats(tapering)
+	if math.Abs(uniformMean-5) > 1 {
+		t.Errorf("Expected a mean width near 5 for the uniform stroke, got %v", uniformMean)
+	}
+	if uniformVariance > 1 {
+		t.Errorf("Expected a low width variance for the uniform stroke, got %v", uniformVariance)
+	}
+	if taperingVariance < 4*max(uniformVariance, 1) {
+		t.Errorf("Expected the tapering stroke variance %v well above the uniform %v", taperingVariance, uniformVariance)
+	}
+
+	fmt.Printf("Stroke width uniform %.2f±%.2f, tapering %.2f±%.2f\n", uniformMean, math.Sqrt(uniformVariance), taperingMean, math.Sqrt(taperingVariance))
+}
+
+func TestCharacterLoadFromImageSubpixel(t *testing.T) {
+	// An anti-aliased 9.4×17.6 rectangle, each pixel gray by how much of it the rectangle covers
+	render := func(left, top float64) *image.Gray {
+		img := image.NewGray(image.Rect(0, 0, 24, 28))
+		for y := 0; y < 28; y++ {
+			for x := 0; x < 24; x++ {
+				coverX := math.Max(0, math.Min(float64(x+1), left+9.4)-math.Max(float64(x), left))
+				coverY := math.Max(0, math.Min(float64(y+1), top+17.6)-math.Max(float64(y), top))
+				img.Pix[y*img.Stride+x] = uint8(math.Round(255 * (1 - coverX*coverY)))
+			}
+		}
+		return img
+	}
+
+	spread := func(widths []float64) float64 {
+		return slices.Max(widths) - slices.Min(widths)
+	}
+
+	var dark, light, subpixel []float64
+	for _, offset := range []float64{3, 3.2, 3.4, 3.5, 3.6, 3.8} {
+		img := render(offset, offset)
+		dark = append(dark, float64(character.LoadFromImage(img, 100, nil).GetBoundingBoxWidth()))
+		light = append(light, float64(character.LoadFromImage(img, 150, nil).GetBoundingBoxWidth()))
+
+		char := character.LoadFromImageSubpixel(img, nil)
+		if char.EdgeBox == nil {
+			t.Fatal("Expected an edge box for the anti-aliased rectangle")
+		}
+		if reference := character.LoadFromImage(img, character.DefaultForegroundThreshold, nil); char.GetPixelCount() != reference.GetPixelCount() {
+			t.Errorf("Expected the sub-pixel bitmap to match the default threshold, got %d pixels instead of %d", char.GetPixelCount(), reference.GetPixelCount())
+		}
+		if math.Abs(char.EdgeBox.Width()-9.4) > 0.05 || math.Abs(char.EdgeBox.Height()-17.6) > 0.05 {
+			t.Errorf("Expected a 9.4×17.6 edge box at offset %v, got %.2f×%.2f", offset, char.EdgeBox.Width(), char.EdgeBox.Height())
+		}
+		subpixel = append(subpixel, char.EdgeBox.Width())
+	}
+
+	if spread(dark) < 1 && spread(light) < 1 {
+		t.Errorf("Expected hard thresholds to jitter the box width by a pixel, got %v and %v", dark, light)
+	}
+	if spread(subpixel) > 0.05 {
+		t.Errorf("Expected a stable sub-pixel width, got %v", subpixel)
+	}
+
+	blank := image.NewGray(image.Rect(0, 0, 4, 4))
+	for i := range blank.Pix {
+		blank.Pix[i] = 255
+	}
+	if empty := character.LoadFromImageSubpixel(blank, nil); empty.EdgeBox != nil {
+		t.Errorf("Expected no edge box for a blank image, got %+v", empty.EdgeBox)
+	}
+
+	fmt.Printf("Box widths threshold 100 %v, threshold 150 %v, sub-pixel %.2f\n", dark, light, subpixel)
+}
+
+func TestCharacterMirror(t *testing.T) {
+	// An L with the foot to the right, off center on the canvas
+	char := character.NewCharacter(12, 16, nil)
+	for y := uint16(2); y <= 12; y++ {
+		char.Draw(3, y)
+	}
+	for x := uint16(4); x <= 8; x++ {
+		char.Draw(x, 12)
+	}
+
+	mirrored := CharacterMirrorHorizontal(char)
+	if mirrored.GetPixelCount() != char.GetPixelCount() {
+		t.Fatalf("Expected %d pixels after mirroring, got %d", char.GetPixelCount(), mirrored.GetPixelCount())
+	}
+	for x := uint16(0); x < 12; x++ {
+		for y := uint16(0); y < 16; y++ {
+			if mirrored.IsDrew(x, y) != char.IsDrew(11-x, y) {
+				t.Fatalf("Expected pixel (%d, %d) to mirror (%d, %d)", x, y, 11-x, y)
+			}
+		}
+	}
+	if mirrored.BoundingBox["minX"] != 3 || mirrored.BoundingBox["maxX"] != 8 || mirrored.BoundingBox["minY"] != 2 || mirrored.BoundingBox["maxY"] != 12 {
+		t.Errorf("Expected the mirrored bounding box x 3-8 y 2-12, got %v", mirrored.BoundingBox)
+	}
+
+	flipped := CharacterMirrorVertical(char)
+	for x := uint16(0); x < 12; x++ {
+		for y := uint16(0); y < 16; y++ {
+			if flipped.IsDrew(x, y) != char.IsDrew(x, 15-y) {
+				t.Fatalf("Expected pixel (%d, %d) to mirror (%d, %d)", x, y, x, 15-y)
+			}
+		}
+	}
+	if flipped.BoundingBox["minY"] != 3 || flipped.BoundingBox["maxY"] != 13 {
+		t.Errorf("Expected the flipped bounding box y 3-13, got %v", flipped.BoundingBox)
+	}
+
+	if twice := CharacterMirrorHorizontal(mirrored); twice.ContentHash() != char.ContentHash() {
+		t.Error("Expected mirroring twice to restore the character")
+	}
+}
+
+func TestCharacterAnchorCurvatureWindow(t *testing.T) {
+	// A one pixel diamond outline drawn in walking order, so the contour keeps that order, with a small notch
+	// every 20 pixels along each side
+	noisyDiamond := func(window int) int {
+		char := character.NewCharacter(100, 100, character.DefaultCharacterConfig())
+		char.Config.AnchorCurvatureWindow = window
+		side := func(x, y, dx, dy, inX, inY int) {
+			for i := 0; i < 40; i++ {
+				px, py := x+dx*i, y+dy*i
+				if i%20 == 10 {
+					px, py = px+2*inX, py+2*inY
+				}
+				char.Draw(uint16(px), uint16(py))
+			}
+		}
+		side(50, 10, 1, 1, -1, 0)
+		side(90, 50, -1, 1, 0, -1)
+		side(50, 90, -1, -1, 1, 0)
+		side(10, 50, 1, -1, 0, 1)
+
+		err := characterHelper.CharacterDetectAnchors(char)
+		if err != nil {
+			t.Fatalf("Anchor detection failed: %v", err)
+		}
+		corners := 0
+		for _, anchor := range char.AnchorPoints {
+			if anchor.Type == "corner" || anchor.Type == "sharp_corner" {
+				corners++
+			}
+		}
+		return corners
+	}
+
+	narrow := noisyDiamond(3)
+	if narrow != 8 {
+		t.Errorf("Expected a 3 point window to mark the 8 notches as corners, got %d", narrow)
+	}
+	wide := noisyDiamond(character.DefaultCharacterConfig().AnchorCurvatureWindow)
+	if wide != 0 {
+		t.Errorf("Expected the default window to smooth the notches out, got %d corners", wide)
+	}
+	if derived := noisyDiamond(0); derived != wide {
+		t.Errorf("Expected a zero window to derive the default from the medial axis epsilon, got %d corners instead of %d", derived, wide)
+	}
+
+	fmt.Printf("Noisy diamond has %d corners with a narrow window and %d with the default\n", narrow, wide)
+}
+
+func TestCharacterRotate(t *testing.T) {
+	// An L with the foot to the right
+	char := character.NewCharacter(20, 20, nil)
+	for y := uint16(4); y <= 14; y++ {
+		char.Draw(6, y)
+	}
+	for x := uint16(7); x <= 11; x++ {
+		char.Draw(x, 14)
+	}
+
+	if same := CharacterRotate(char, 0); same != char {
+		t.Error("Expected a zero angle to return the character itself")
+	}
+
+	// A clockwise quarter turn lays the stem flat with the foot hanging down from its left end
+	rotated := CharacterRotate(char, 90)
+	if rotated.GetPixelCount() != char.GetPixelCount() {
+		t.Fatalf("Expected %d pixels after a quarter turn, got %d", char.GetPixelCount(), rotated.GetPixelCount())
+	}
+	width, height := rotated.GetBoundingBoxWidth(), rotated.GetBoundingBoxHeight()
+	if width != char.GetBoundingBoxHeight() || height != char.GetBoundingBoxWidth() {
+		t.Errorf("Expected the bounding box to turn into %dx%d, got %dx%d", char.GetBoundingBoxHeight(), char.GetBoundingBoxWidth(), width, height)
+	}
+	minX, maxY := rotated.BoundingBox["minX"], rotated.BoundingBox["maxY"]
+	if !rotated.IsDrew(minX, maxY) {
+		t.Errorf("Expected the foot to hang from the left end of the stem, bounding box %v", rotated.BoundingBox)
+	}
+}
+
+func TestCharacterEstimateStrokeCount(t *testing.T) {
+	// A '+' of two strokes of the given width crossing at the center of a size canvas, turned by tilt degrees
+	plus := func(size int, width, tilt float64) *character.Character {
+		char := character.NewCharacter(uint16(size), uint16(size), nil)
+		center, arm := float64(size)/2, float64(size)*0.4
+		sin, cos := math.Sincos(tilt * math.Pi / 180)
+		for x := 0; x < size; x++ {
+			for y := 0; y < size; y++ {
+				dx, dy := float64(x)-center, float64(y)-center
+				u, v := dx*cos+dy*sin, -dx*sin+dy*cos
+				if (math.Abs(u) <= arm && math.Abs(v) < width/2) || (math.Abs(v) <= arm && math.Abs(u) < width/2) {
+					char.Draw(uint16(x), uint16(y))
+				}
+			}
+		}
+		return char
+	}
+
+	// Branches are counted, so the two pen strokes of a '+' are its 4 arms
+	for _, tt := range []struct {
+		size        int
+		width, tilt float64
+	}{{48, 6, 0}, {48, 4, 0}, {96, 9, 0}, {64, 6, 7}, {64, 6, -12}} {
+		if count := characterHelper.CharacterEstimateStrokeCount(plus(tt.size, tt.width, tt.tilt)); count != 4 {
+			t.Errorf("Expected 4 branches for a %d pixel '+' of %.0f pixel strokes tilted %.0f degrees, got %d", tt.size, tt.width, tt.tilt, count)
+		}
+	}
+
+	bar := character.NewCharacter(40, 40, nil)
+	for y := uint16(5); y < 35; y++ {
+		for x := uint16(17); x < 23; x++ {
+			bar.Draw(x, y)
+		}
+	}
+	if count := characterHelper.CharacterEstimateStrokeCount(bar); count != 1 {
+		t.Errorf("Expected 1 branch for a straight bar, got %d", count)
+	}
+
+	if count := characterHelper.CharacterEstimateStrokeCount(character.NewCharacter(10, 10, nil)); count != 0 {
+		t.Errorf("Expected 0 branches for a blank character, got %d", count)
+	}
+}
+
+func TestCharacterComplexity(t *testing.T) {
+	// Scored on the normalized canvas the way feature extraction sees the glyph
+	complexity := func(text string) float64 {
+		char := NormalizeToCanvas(test.CharacterFromImage(test.RenderText([]string{text}, 3)), NormalizedCanvasSize)
+		if err := characterHelper.CharacterComputeMedialAxis(char); err != nil {
+			t.Fatalf("CharacterComputeMedialAxis(%s) failed: %v", text, err)
+		}
+		return characterHelper.CharacterComplexity(char)
+	}
+
+	for _, text := range []string{".", "-", ","} {
+		if score := complexity(text); score >= 6 {
+			t.Errorf("Expected %q to score below 6, got %.2f", text, score)
+		}
+	}
+	if score := complexity("l"); score <= 10 {
+		t.Errorf("Expected 'l' to score above 10, got %.2f", score)
+	}
+	if score := complexity("A"); score < 20 {
+		t.Errorf("Expected 'A' to score 20 or more, got %.2f", score)
+	}
+
+	if score := characterHelper.CharacterComplexity(character.NewCharacter(10, 10, nil)); score != 0 {
+		t.Errorf("Expected a character without a medial axis to score 0, got %.2f", score)
+	}
+}
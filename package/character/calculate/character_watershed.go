@@ -0,0 +1,85 @@
+package characterCalculate
+
+import (
+	"container/heap"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	characterHelper "github.com/bsthun/glyphcanvas/package/character/helper"
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+// WatershedRegions splits char into stroke regions with a marker controlled watershed on its distance transform. Each
+// medial axis segment between junctions is a marker, and the ink is flooded from the stroke centers outward, deepest
+// pixels first, so two strokes meeting at a junction divide it along the valley between their ridges. The medial axis
+// is computed first when it is missing. An empty character gives no regions
+func WatershedRegions(char *character.Character) []*region.Region {
+	if char.IsEmpty() {
+		return []*region.Region{}
+	}
+	if len(char.MedialAxis) == 0 {
+		if err := characterHelper.CharacterComputeMedialAxis(char); err != nil {
+			return []*region.Region{char.ToRegion()}
+		}
+	}
+
+	regions := floodFromSkeleton(char, watershedFlood(char))
+	if regions == nil {
+		// Without a skeleton there are no markers, the ink is a single basin
+		return []*region.Region{char.ToRegion()}
+	}
+	return regions
+}
+
+// watershedFlood spreads labels through the ink from the deepest labeled pixels, in order of the distance transform
+func watershedFlood(char *character.Character) func(labels map[[2]int]int, seeds [][2]int) {
+	distance := characterHelper.CharacterDistanceTransform(char)
+	return func(labels map[[2]int]int, seeds [][2]int) {
+		queue := &watershedQueue{}
+		pushed := 0
+		push := func(key [2]int) {
+			heap.Push(queue, watershedPixel{key: key, depth: distance[key[0]][key[1]], order: pushed})
+			pushed++
+		}
+		for _, seed := range seeds {
+			push(seed)
+		}
+		for queue.Len() > 0 {
+			pixel := heap.Pop(queue).(watershedPixel)
+			forEachInkNeighbor(char, pixel.key, func(next [2]int) {
+				if _, ok := labels[next]; !ok {
+					labels[next] = labels[pixel.key]
+					push(next)
+				}
+			})
+		}
+	}
+}
+
+type watershedPixel struct {
+	key   [2]int
+	depth float64 // Distance to the background, deeper pixels flood first
+	order int     // Push order, keeps equal depths first in first out so the flood is deterministic
+}
+
+// watershedQueue is a max heap on depth
+type watershedQueue []watershedPixel
+
+func (q watershedQueue) Len() int { return len(q) }
+
+func (q watershedQueue) Less(i, j int) bool {
+	if q[i].depth != q[j].depth {
+		return q[i].depth > q[j].depth
+	}
+	return q[i].order < q[j].order
+}
+
+func (q watershedQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *watershedQueue) Push(pixel any) { *q = append(*q, pixel.(watershedPixel)) }
+
+func (q *watershedQueue) Pop() any {
+	old := *q
+	pixel := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return pixel
+}
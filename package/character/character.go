@@ -1,6 +1,12 @@
 package character
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"sort"
+
 	"github.com/bsthun/glyphcanvas/package/region"
 )
 
@@ -33,6 +39,10 @@ type Character struct {
 	Topology    map[string]interface{} `json:"topology"`
 	Moments     map[string]float64     `json:"moments"`
 	BoundingBox map[string]uint16      `json:"boundingBox"`
+	EdgeBox     *EdgeBox               `json:"edgeBox,omitempty"` // Sub-pixel ink extent, set by LoadFromImageSubpixel
+
+	// Non fatal failures recorded while analyzing, see RecordAnalysisError
+	AnalysisErrors []error `json:"-"`
 
 	// Configuration
 	Config *CharacterConfig `json:"config"`
@@ -114,6 +124,62 @@ func (c *Character) IsEmpty() bool {
 	return len(c.Draws) == 0
 }
 
+// ContentHash identifies the canvas size and foreground pixels, independent of draw order
+func (c *Character) ContentHash() string {
+	points := make([]*Point, 0, len(c.Draws))
+	seen := make(map[uint32]bool, len(c.Draws))
+	for _, point := range c.Draws {
+		key := uint32(point.X)<<16 | uint32(point.Y)
+		if seen[key] || !c.IsDrew(point.X, point.Y) {
+			continue
+		}
+		seen[key] = true
+		points = append(points, point)
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Y != points[j].Y {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+
+	hash := sha256.New()
+	buffer := make([]byte, 4)
+	binary.BigEndian.PutUint16(buffer[0:], c.SizeX)
+	binary.BigEndian.PutUint16(buffer[2:], c.SizeY)
+	hash.Write(buffer)
+	for _, point := range points {
+		binary.BigEndian.PutUint16(buffer[0:], point.X)
+		binary.BigEndian.PutUint16(buffer[2:], point.Y)
+		hash.Write(buffer)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func FromRegion(reg *region.Region, config *CharacterConfig) *Character {
+	char := NewCharacter(reg.GetSizeX(), reg.GetSizeY(), config)
+
+	for _, point := range reg.Draws {
+		if reg.IsDrew(point.X, point.Y) && !char.IsDrew(point.X, point.Y) {
+			char.Draw(point.X, point.Y)
+		}
+	}
+
+	return char
+}
+
+func (c *Character) ToRegion() *region.Region {
+	reg := region.NewRegion(c.SizeX, c.SizeY)
+
+	for _, point := range c.Draws {
+		reg.Draw(point.X, point.Y)
+	}
+
+	return reg
+}
+
 func (c *Character) updateBoundingBox(x, y uint16) {
 	if len(c.Draws) == 1 {
 		// First pixel
@@ -203,6 +269,31 @@ func (c *Character) GetAnchorPointsByType(anchorType string) []*AnchorPoint {
 	return result
 }
 
+func (c *Character) AnchorPointsInRect(r image.Rectangle) []*AnchorPoint {
+	var result []*AnchorPoint
+	for _, anchor := range c.AnchorPoints {
+		if image.Pt(int(anchor.Point.X), int(anchor.Point.Y)).In(r) {
+			result = append(result, anchor)
+		}
+	}
+	return result
+}
+
+func (c *Character) NearestAnchor(x, y uint16) *AnchorPoint {
+	var nearest *AnchorPoint
+	minDist := -1
+	for _, anchor := range c.AnchorPoints {
+		dx := int(anchor.Point.X) - int(x)
+		dy := int(anchor.Point.Y) - int(y)
+		dist := dx*dx + dy*dy
+		if minDist < 0 || dist < minDist {
+			minDist = dist
+			nearest = anchor
+		}
+	}
+	return nearest
+}
+
 func (c *Character) ClearAnalysisResults() {
 	c.AnchorPoints = []*AnchorPoint{}
 	c.Regions = []*region.Region{}
@@ -210,4 +301,12 @@ func (c *Character) ClearAnalysisResults() {
 	c.SkeletonBranches = make(map[string][]*Point)
 	c.Topology = make(map[string]interface{})
 	c.Moments = make(map[string]float64)
+	c.AnalysisErrors = nil
+}
+
+// RecordAnalysisError keeps an error that did not stop feature extraction so callers can inspect it later
+func (c *Character) RecordAnalysisError(err error) {
+	if err != nil {
+		c.AnalysisErrors = append(c.AnalysisErrors, err)
+	}
 }
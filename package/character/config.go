@@ -1,12 +1,20 @@
 package character
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
 
 type CharacterConfig struct {
 	// Anchor Detection Configuration
 	AnchorDetectionThreshold float64 `json:"anchorDetectionThreshold"` // Threshold for anchor point significance
 	MinAnchorDistance        float64 `json:"minAnchorDistance"`        // Minimum distance between anchor points
 	CurvatureThreshold       float64 `json:"curvatureThreshold"`       // Curvature threshold for anchor detection
+	AnchorCurvatureWindow    int     `json:"anchorCurvatureWindow"`    // Contour points on each side used to measure curvature, 0 derives it from MedialAxisEpsilon
+	SharpCornerMultiplier    float64 `json:"sharpCornerMultiplier"`    // Curvature above CurvatureThreshold times this makes a sharp_corner anchor, 0 uses 2
+	SmoothBendThreshold      float64 `json:"smoothBendThreshold"`      // Curvature above this but not CurvatureThreshold makes a smooth_bend anchor, 0 uses half of CurvatureThreshold
 
 	// Medial Axis Configuration
 	MedialAxisEpsilon        float64 `json:"medialAxisEpsilon"`        // Precision for medial axis computation
@@ -17,6 +25,9 @@ type CharacterConfig struct {
 	MinRegionSize        uint16  `json:"minRegionSize"`        // Minimum size for a valid region
 	RegionMergeThreshold float64 `json:"regionMergeThreshold"` // Threshold for merging adjacent regions
 	ConnectivityType     int     `json:"connectivityType"`     // 4-connectivity (0) or 8-connectivity (1)
+	BoundaryRayCount     int     `json:"boundaryRayCount"`     // Evenly spaced rays cast from a skeleton branch point to the stroke boundary, 0 uses 8
+	BoundaryRayStep      float64 `json:"boundaryRayStep"`      // Distance in pixels between samples along a boundary ray, 0 uses 1
+	SegmentationMethod   string  `json:"segmentationMethod"`   // One of the Segmentation methods, empty uses SegmentationLines
 
 	// Character Analysis Configuration
 	EnableStrokeAnalysis    bool `json:"enableStrokeAnalysis"`    // Enable stroke-based analysis
@@ -29,17 +40,27 @@ type CharacterConfig struct {
 	RectangularityThreshold float64 `json:"rectangularityThreshold"` // Threshold for rectangular region classification
 
 	// Performance Configuration
-	EnableParallelProcessing bool `json:"enableParallelProcessing"` // Enable parallel processing where applicable
-	MaxRegions               int  `json:"maxRegions"`               // Maximum number of regions to analyze
-	ComputationTimeout       int  `json:"computationTimeout"`       // Timeout in milliseconds for analysis
+	EnableParallelProcessing bool   `json:"enableParallelProcessing"` // Enable parallel processing where applicable
+	MaxRegions               int    `json:"maxRegions"`               // Maximum number of regions to analyze
+	ComputationTimeout       int    `json:"computationTimeout"`       // Timeout in milliseconds for analysis
+	MaxGlyphSize             uint16 `json:"maxGlyphSize"`             // Largest canvas side CharacterComprehensiveAnalysis accepts, 0 disables the limit
 }
 
+// Region decompositions CharacterConfig.SegmentationMethod selects between
+const (
+	SegmentationLines     = "lines"     // Cut along lines between anchors and from medial axis branch points
+	SegmentationSkeleton  = "skeleton"  // Cut the medial axis at junctions and grow each segment into a stroke region
+	SegmentationWatershed = "watershed" // Flood the distance transform from the medial axis segments
+)
+
 func DefaultCharacterConfig() *CharacterConfig {
 	return &CharacterConfig{
 		// Anchor Detection
 		AnchorDetectionThreshold: 0.7,
 		MinAnchorDistance:        3.0,
 		CurvatureThreshold:       0.5,
+		AnchorCurvatureWindow:    10,
+		SharpCornerMultiplier:    2.0,
 
 		// Medial Axis
 		MedialAxisEpsilon:        0.1,
@@ -50,6 +71,9 @@ func DefaultCharacterConfig() *CharacterConfig {
 		MinRegionSize:        4,
 		RegionMergeThreshold: 0.8,
 		ConnectivityType:     1, // 8-connectivity
+		BoundaryRayCount:     8,
+		BoundaryRayStep:      1.0,
+		SegmentationMethod:   SegmentationLines,
 
 		// Character Analysis
 		EnableStrokeAnalysis:    true,
@@ -65,7 +89,20 @@ func DefaultCharacterConfig() *CharacterConfig {
 		EnableParallelProcessing: true,
 		MaxRegions:               100,
 		ComputationTimeout:       5000, // 5 seconds
+		MaxGlyphSize:             256,
+	}
+}
+
+// Hash identifies the configuration values, a nil config hashes like DefaultCharacterConfig
+func (config *CharacterConfig) Hash() string {
+	if config == nil {
+		config = DefaultCharacterConfig()
 	}
+
+	// Every field is a plain value, so marshalling cannot fail and keeps declaration order
+	encoded, _ := json.Marshal(config)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
 }
 
 func (config *CharacterConfig) Validate() error {
@@ -75,6 +112,15 @@ func (config *CharacterConfig) Validate() error {
 	if config.MinAnchorDistance < 0 {
 		return fmt.Errorf("minAnchorDistance must be non-negative")
 	}
+	if config.AnchorCurvatureWindow < 0 {
+		return fmt.Errorf("anchorCurvatureWindow must be non-negative")
+	}
+	if config.SharpCornerMultiplier != 0 && config.SharpCornerMultiplier < 1 {
+		return fmt.Errorf("sharpCornerMultiplier must be 0 or at least 1")
+	}
+	if config.SmoothBendThreshold < 0 || config.SmoothBendThreshold > config.CurvatureThreshold {
+		return fmt.Errorf("smoothBendThreshold must be between 0 and curvatureThreshold")
+	}
 	if config.MedialAxisEpsilon <= 0 {
 		return fmt.Errorf("medialAxisEpsilon must be positive")
 	}
@@ -84,6 +130,17 @@ func (config *CharacterConfig) Validate() error {
 	if config.ConnectivityType != 0 && config.ConnectivityType != 1 {
 		return fmt.Errorf("connectivityType must be 0 (4-connectivity) or 1 (8-connectivity)")
 	}
+	if config.BoundaryRayCount < 0 {
+		return fmt.Errorf("boundaryRayCount must be non-negative")
+	}
+	if config.BoundaryRayStep < 0 {
+		return fmt.Errorf("boundaryRayStep must be non-negative")
+	}
+	switch config.SegmentationMethod {
+	case "", SegmentationLines, SegmentationSkeleton, SegmentationWatershed:
+	default:
+		return fmt.Errorf("segmentationMethod must be %q, %q or %q", SegmentationLines, SegmentationSkeleton, SegmentationWatershed)
+	}
 	if config.MaxRegions <= 0 {
 		return fmt.Errorf("maxRegions must be positive")
 	}
@@ -0,0 +1,10 @@
+package character
+
+import "errors"
+
+var (
+	ErrEmptyCharacter  = errors.New("character is empty")
+	ErrImageDecode     = errors.New("image decode failed")
+	ErrAnalysisTimeout = errors.New("analysis timed out")
+	ErrGlyphTooLarge   = errors.New("glyph exceeds max size")
+)
@@ -0,0 +1,244 @@
+package characterHelper
+
+import (
+	"math"
+	"sort"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+const (
+	dashMaxAngle      = 20.0 // Largest angle in degrees between a dash's axis and the line to its neighbor
+	dashMinAnisotropy = 0.5  // Components below this are dots, which have no axis to line up with
+)
+
+type dashComponent struct {
+	points     [][2]int
+	cx, cy     float64
+	angle      float64 // Principal axis in [0, π)
+	elongated  bool
+	thickness  float64
+	minX, minY int
+	maxX, maxY int
+}
+
+// CharacterBridgeDashes joins 8-connected components lying on a common line with at most maxGap background pixels
+// between them, as in dashed or dotted strokes, by drawing a bridge as thick as the thinner component. Nearest
+// gaps are bridged first and components already joined are not bridged again. It returns the number of bridges drawn.
+func CharacterBridgeDashes(char *character.Character, maxGap int) int {
+	if maxGap <= 0 || char.IsEmpty() {
+		return 0
+	}
+
+	components := labelDashComponents(char)
+	if len(components) < 2 {
+		return 0
+	}
+
+	type candidate struct {
+		i, j     int
+		distance float64
+		from, to [2]int
+	}
+	var candidates []candidate
+	for i := range components {
+		for j := i + 1; j < len(components); j++ {
+			a, b := components[i], components[j]
+			if a.minX-b.maxX > maxGap+1 || b.minX-a.maxX > maxGap+1 || a.minY-b.maxY > maxGap+1 || b.minY-a.maxY > maxGap+1 {
+				continue
+			}
+			if !dashesAligned(a, b) {
+				continue
+			}
+
+			distance, from, to := nearestDashPoints(a, b)
+			// Pixels d apart leave d-1 background pixels between them
+			if distance-1 > float64(maxGap)+1e-9 {
+				continue
+			}
+			candidates = append(candidates, candidate{i: i, j: j, distance: distance, from: from, to: to})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	parent := make([]int, len(components))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	bridges := 0
+	for _, c := range candidates {
+		rootI, rootJ := find(c.i), find(c.j)
+		if rootI == rootJ {
+			continue
+		}
+		parent[rootJ] = rootI
+
+		a, b := components[c.i], components[c.j]
+		thickness := math.Min(a.thickness, b.thickness)
+		// Run the bridge along the line through both centroids so it stays centered on the stroke
+		from, to := projectOnDashLine(a, b, c.from), projectOnDashLine(a, b, c.to)
+		drawDashBridge(char, from, to, max(1, int(math.Round(thickness))))
+		bridges++
+	}
+
+	return bridges
+}
+
+func labelDashComponents(char *character.Character) []*dashComponent {
+	visited := make(map[[2]int]bool)
+	var components []*dashComponent
+
+	for _, start := range char.Draws {
+		key := [2]int{int(start.X), int(start.Y)}
+		if visited[key] || !char.IsDrew(start.X, start.Y) {
+			continue
+		}
+
+		component := &dashComponent{minX: key[0], minY: key[1], maxX: key[0], maxY: key[1]}
+		stack := [][2]int{key}
+		visited[key] = true
+		for len(stack) > 0 {
+			point := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component.points = append(component.points, point)
+			component.minX, component.maxX = min(component.minX, point[0]), max(component.maxX, point[0])
+			component.minY, component.maxY = min(component.minY, point[1]), max(component.maxY, point[1])
+
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					next := [2]int{point[0] + dx, point[1] + dy}
+					if next[0] < 0 || next[1] < 0 || next[0] >= int(char.SizeX) || next[1] >= int(char.SizeY) {
+						continue
+					}
+					if !visited[next] && char.IsDrew(uint16(next[0]), uint16(next[1])) {
+						visited[next] = true
+						stack = append(stack, next)
+					}
+				}
+			}
+		}
+
+		measureDashComponent(component)
+		components = append(components, component)
+	}
+
+	return components
+}
+
+func measureDashComponent(component *dashComponent) {
+	count := float64(len(component.points))
+	for _, point := range component.points {
+		component.cx += float64(point[0])
+		component.cy += float64(point[1])
+	}
+	component.cx /= count
+	component.cy /= count
+
+	mu20, mu02, mu11 := 0.0, 0.0, 0.0
+	for _, point := range component.points {
+		dx, dy := float64(point[0])-component.cx, float64(point[1])-component.cy
+		mu20 += dx * dx
+		mu02 += dy * dy
+		mu11 += dx * dy
+	}
+
+	component.angle = 0.5 * math.Atan2(2*mu11, mu20-mu02)
+	if component.angle < 0 {
+		component.angle += math.Pi
+	}
+	if mu20+mu02 > 0 {
+		component.elongated = math.Sqrt((mu20-mu02)*(mu20-mu02)+4*mu11*mu11)/(mu20+mu02) >= dashMinAnisotropy
+	}
+
+	// Pixels over the extent along the axis approximates the stroke width, a dot is as thick as it is wide
+	component.thickness = math.Sqrt(count)
+	if component.elongated {
+		sin, cos := math.Sincos(component.angle)
+		low, high := math.Inf(1), math.Inf(-1)
+		for _, point := range component.points {
+			along := (float64(point[0])-component.cx)*cos + (float64(point[1])-component.cy)*sin
+			low, high = math.Min(low, along), math.Max(high, along)
+		}
+		component.thickness = count / (high - low + 1)
+	}
+}
+
+// dashesAligned reports whether the line through both centroids runs along every elongated component of the pair
+func dashesAligned(a, b *dashComponent) bool {
+	dx, dy := b.cx-a.cx, b.cy-a.cy
+	if dx == 0 && dy == 0 {
+		return false
+	}
+	direction := math.Atan2(dy, dx)
+	if direction < 0 {
+		direction += math.Pi
+	}
+
+	for _, component := range []*dashComponent{a, b} {
+		if !component.elongated {
+			continue
+		}
+		difference := math.Abs(direction - component.angle)
+		difference = math.Min(difference, math.Pi-difference)
+		if difference > dashMaxAngle*math.Pi/180 {
+			return false
+		}
+	}
+	return true
+}
+
+func nearestDashPoints(a, b *dashComponent) (float64, [2]int, [2]int) {
+	best := math.Inf(1)
+	var from, to [2]int
+	for _, p := range a.points {
+		for _, q := range b.points {
+			if distance := math.Hypot(float64(q[0]-p[0]), float64(q[1]-p[1])); distance < best {
+				best, from, to = distance, p, q
+			}
+		}
+	}
+	return best, from, to
+}
+
+func projectOnDashLine(a, b *dashComponent, point [2]int) [2]float64 {
+	dx, dy := b.cx-a.cx, b.cy-a.cy
+	along := ((float64(point[0])-a.cx)*dx + (float64(point[1])-a.cy)*dy) / (dx*dx + dy*dy)
+	return [2]float64{a.cx + along*dx, a.cy + along*dy}
+}
+
+// drawDashBridge draws a stroke of the given width centered on the segment between both points
+func drawDashBridge(char *character.Character, from, to [2]float64, width int) {
+	dx, dy := to[0]-from[0], to[1]-from[1]
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	normalX, normalY := -dy/length, dx/length
+
+	for step := 0.0; step <= length; step += 0.5 {
+		x := from[0] + dx*step/length
+		y := from[1] + dy*step/length
+		for i := 0; i < width; i++ {
+			offset := float64(i) - float64(width-1)/2
+			px := int(math.Round(x + normalX*offset))
+			py := int(math.Round(y + normalY*offset))
+			if px < 0 || py < 0 || px >= int(char.SizeX) || py >= int(char.SizeY) {
+				continue
+			}
+			if !char.IsDrew(uint16(px), uint16(py)) {
+				char.Draw(uint16(px), uint16(py))
+			}
+		}
+	}
+}
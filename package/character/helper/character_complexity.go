@@ -0,0 +1,20 @@
+package characterHelper
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// CharacterComplexity scores how much structure the skeleton of char carries, the medial axis length measured in mean
+// stroke widths plus two for each junction. Single short strokes such as '.', '-' or ',' score below 6, letters like
+// 'l' score above 10 and looped or branching glyphs 20 or more. The medial axis must be computed first, without it
+// the score is 0.
+func CharacterComplexity(char *character.Character) float64 {
+	if len(char.MedialAxis) == 0 || char.IsEmpty() {
+		return 0
+	}
+
+	// Ink per skeleton pixel is the mean width of the strokes
+	strokeWidth := float64(char.GetPixelCount()) / float64(len(char.MedialAxis))
+
+	return float64(len(char.MedialAxis))/strokeWidth + 2*float64(len(char.SkeletonJunctions()))
+}
@@ -1,38 +1,60 @@
 package characterHelper
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/bsthun/glyphcanvas/package/character"
 	"github.com/bsthun/glyphcanvas/package/region"
 	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
 )
 
+// CharacterComprehensiveAnalysis runs every analysis step on char. A canvas side above Config.MaxGlyphSize returns
+// ErrGlyphTooLarge, the quadratic steps would effectively hang on a page passed in by mistake. Feature extraction
+// resamples glyphs onto characterCalculate.NormalizedCanvasSize first, so it never reaches the limit.
 func CharacterComprehensiveAnalysis(char *character.Character) error {
 	if char.IsEmpty() {
 		return nil
 	}
 
-	// Step 1: Basic character analysis
-	if err := performBasicCharacterAnalysis(char); err != nil {
-		return err
+	if char.Config != nil && char.Config.MaxGlyphSize > 0 && (char.SizeX > char.Config.MaxGlyphSize || char.SizeY > char.Config.MaxGlyphSize) {
+		return fmt.Errorf("character %dx%d, limit %d, normalize it first: %w", char.SizeX, char.SizeY, char.Config.MaxGlyphSize, character.ErrGlyphTooLarge)
 	}
 
-	// Step 2: Break down character into regions (basic implementation)
-	regions := []*region.Region{createRegionFromCharacter(char)}
-	char.Regions = regions
+	// Every result is recomputed below, drop stale topology and region entries from an earlier run
+	char.ClearAnalysisResults()
 
-	// Step 3: Analyze each region using existing region analysis tools
-	if err := analyzeCharacterRegions(char); err != nil {
-		return err
+	var deadline time.Time
+	if char.Config != nil && char.Config.ComputationTimeout > 0 {
+		deadline = time.Now().Add(time.Duration(char.Config.ComputationTimeout) * time.Millisecond)
 	}
 
-	// Step 4: Compute character-level metrics based on region analysis
-	if err := computeCharacterLevelMetrics(char); err != nil {
-		return err
+	steps := []struct {
+		name string
+		run  func(*character.Character) error
+	}{
+		// Step 1: Basic character analysis
+		{"basic analysis", performBasicCharacterAnalysis},
+		// Step 2: Break down character into regions (basic implementation)
+		{"region breakdown", func(char *character.Character) error {
+			char.Regions = []*region.Region{char.ToRegion()}
+			return nil
+		}},
+		// Step 3: Analyze each region using existing region analysis tools
+		{"region analysis", analyzeCharacterRegions},
+		// Step 4: Compute character-level metrics based on region analysis
+		{"character metrics", computeCharacterLevelMetrics},
+		// Step 5: Classify character geometric properties
+		{"geometry classification", classifyCharacterGeometry},
 	}
 
-	// Step 5: Classify character geometric properties
-	if err := classifyCharacterGeometry(char); err != nil {
-		return err
+	for _, step := range steps {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("before %s after %dms: %w", step.name, char.Config.ComputationTimeout, character.ErrAnalysisTimeout)
+		}
+		if err := step.run(char); err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
 	}
 
 	return nil
@@ -86,7 +108,7 @@ func analyzeIndividualRegion(reg *region.Region, regionIndex int, char *characte
 	circularity := regionHelper.RegionComputeCircularity(huInvariants)
 	storeRegionAnalysis(char, regionIndex, "circularity", circularity)
 
-	linearity := regionHelper.RegionComputeLinearity(huInvariants)
+	linearity := regionHelper.RegionComputeLinearity(moments)
 	storeRegionAnalysis(char, regionIndex, "linearity", linearity)
 
 	rectangularity := regionHelper.RegionComputeRectangularity(huInvariants)
@@ -172,7 +194,7 @@ func computeCharacterLevelMetrics(char *character.Character) error {
 
 	// Character complexity metrics
 	aggregatedMetrics["anchorPointDensity"] = float64(len(char.AnchorPoints)) / totalArea
-	aggregatedMetrics["medialAxisComplexity"] = computeMedialAxisComplexity(char)
+	aggregatedMetrics["medialAxisComplexity"] = CharacterComplexity(char)
 	aggregatedMetrics["skeletonBranchCount"] = len(char.SkeletonBranches)
 
 	char.Topology["characterMetrics"] = aggregatedMetrics
@@ -180,35 +202,6 @@ func computeCharacterLevelMetrics(char *character.Character) error {
 	return nil
 }
 
-func computeMedialAxisComplexity(char *character.Character) float64 {
-	if len(char.MedialAxis) == 0 {
-		return 0.0
-	}
-
-	// Complexity based on:
-	// 1. Number of medial axis points
-	// 2. Number of skeleton branches
-	// 3. Total medial axis length
-	// 4. Branching factor
-
-	pointCount := float64(len(char.MedialAxis))
-	branchCount := float64(len(char.SkeletonBranches))
-	totalLength := 0.0
-
-	if medialAxisLength, ok := char.Topology["medialAxisLength"].(float64); ok {
-		totalLength = medialAxisLength
-	}
-
-	// Normalized complexity score
-	boundingArea := float64(char.GetBoundingBoxWidth() * char.GetBoundingBoxHeight())
-	if boundingArea == 0 {
-		return 0.0
-	}
-
-	complexity := (pointCount + branchCount*2 + totalLength) / boundingArea
-	return complexity
-}
-
 func classifyCharacterGeometry(char *character.Character) error {
 	if char.Topology["characterMetrics"] == nil {
 		return nil
@@ -242,11 +235,12 @@ func classifyCharacterGeometry(char *character.Character) error {
 		}
 	}
 
-	// Classify character complexity
+	// Classify character complexity on the CharacterComplexity scale, plain strokes score below 8 and looped or
+	// branching glyphs 20 or more
 	if complexity, ok := metrics["medialAxisComplexity"].(float64); ok {
-		if complexity < 0.1 {
+		if complexity < 8 {
 			classification["complexityLevel"] = "simple"
-		} else if complexity < 0.3 {
+		} else if complexity < 20 {
 			classification["complexityLevel"] = "moderate"
 		} else {
 			classification["complexityLevel"] = "complex"
@@ -281,9 +275,13 @@ func classifyCharacterGeometry(char *character.Character) error {
 	if anchorTypes["junction"] > 2 {
 		classification["hasMultipleJunctions"] = true
 	}
-	if anchorTypes["corner"] > 4 {
+	if anchorTypes["corner"]+anchorTypes["sharp_corner"] > 4 {
 		classification["hasManyCornerscharacter"] = true
 	}
+	// Gentle turns without corners are what set curved strokes apart from angular ones
+	if anchorTypes["smooth_bend"] > anchorTypes["corner"]+anchorTypes["sharp_corner"] {
+		classification["mostlySmoothBends"] = true
+	}
 
 	char.Topology["characterClassification"] = classification
 
@@ -291,63 +289,7 @@ func classifyCharacterGeometry(char *character.Character) error {
 }
 
 func computeCharacterMoments(char *character.Character) map[string]float64 {
-	moments := make(map[string]float64)
-
-	// Convert character to region-like structure for moment computation
-	m00, m10, m01, m11, m20, m02, m21, m12, m30, m03 := 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0
-
-	for _, point := range char.Draws {
-		fx := float64(point.X)
-		fy := float64(point.Y)
-
-		m00 += 1
-		m10 += fx
-		m01 += fy
-		m11 += fx * fy
-		m20 += fx * fx
-		m02 += fy * fy
-		m21 += fx * fx * fy
-		m12 += fx * fy * fy
-		m30 += fx * fx * fx
-		m03 += fy * fy * fy
-	}
-
-	moments["m00"] = m00
-	moments["m10"] = m10
-	moments["m01"] = m01
-	moments["m11"] = m11
-	moments["m20"] = m20
-	moments["m02"] = m02
-	moments["m21"] = m21
-	moments["m12"] = m12
-	moments["m30"] = m30
-	moments["m03"] = m03
-
-	// Central moments
-	if m00 > 0 {
-		cx := m10 / m00
-		cy := m01 / m00
-		moments["cx"] = cx
-		moments["cy"] = cy
-
-		mu20 := m20 - cx*m10
-		mu02 := m02 - cy*m01
-		mu11 := m11 - cx*m01
-		mu30 := m30 - 3*cx*m20 + 2*cx*cx*m10
-		mu21 := m21 - 2*cx*m11 - cy*m20 + 2*cx*cx*m01
-		mu12 := m12 - 2*cy*m11 - cx*m02 + 2*cy*cy*m10
-		mu03 := m03 - 3*cy*m02 + 2*cy*cy*m01
-
-		moments["mu20"] = mu20
-		moments["mu02"] = mu02
-		moments["mu11"] = mu11
-		moments["mu30"] = mu30
-		moments["mu21"] = mu21
-		moments["mu12"] = mu12
-		moments["mu03"] = mu03
-	}
-
-	return moments
+	return regionHelper.RegionComputeMoments(char.ToRegion())
 }
 
 func CharacterGetAnalysisSummary(char *character.Character) map[string]interface{} {
@@ -389,13 +331,3 @@ func CharacterGetAnalysisSummary(char *character.Character) map[string]interface
 
 	return summary
 }
-
-func createRegionFromCharacter(char *character.Character) *region.Region {
-	reg := region.NewRegion(char.SizeX, char.SizeY)
-
-	for _, point := range char.Draws {
-		reg.Draw(point.X, point.Y)
-	}
-
-	return reg
-}
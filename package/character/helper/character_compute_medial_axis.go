@@ -3,6 +3,7 @@ package characterHelper
 import (
 	"github.com/bsthun/glyphcanvas/package/character"
 	"math"
+	"sort"
 )
 
 func CharacterComputeMedialAxis(char *character.Character) error {
@@ -15,10 +16,10 @@ func CharacterComputeMedialAxis(char *character.Character) error {
 	char.SkeletonBranches = make(map[string][]*character.Point)
 
 	// Step 1: Compute distance transform
-	distanceField := computeDistanceTransform(char)
+	distanceField := CharacterDistanceTransform(char)
 
-	// Step 2: Extract medial axis points using ridge detection
-	medialPoints := extractMedialAxisPoints(char, distanceField)
+	// Step 2: Thin the foreground to a connected one pixel skeleton
+	medialPoints := extractMedialAxisPoints(char)
 
 	// Step 3: Order medial axis points into skeleton branches
 	char.MedialAxis = medialPoints
@@ -30,127 +31,84 @@ func CharacterComputeMedialAxis(char *character.Character) error {
 	return nil
 }
 
-func computeDistanceTransform(char *character.Character) [][]float64 {
+// extractMedialAxisPoints thins the foreground to a one pixel wide skeleton with Zhang-Suen thinning, which keeps
+// strokes connected through junctions where distance ridges break off. Every remaining pixel is kept, leaving out
+// shallow ones would cut the skeleton of a thin stroke apart.
+func extractMedialAxisPoints(char *character.Character) []*character.Point {
 	sizeX := int(char.SizeX)
 	sizeY := int(char.SizeY)
 
-	// Initialize distance field
-	distField := make([][]float64, sizeX)
-	for x := 0; x < sizeX; x++ {
-		distField[x] = make([]float64, sizeY)
-		for y := 0; y < sizeY; y++ {
-			if char.IsDrew(uint16(x), uint16(y)) {
-				distField[x][y] = math.Inf(1) // Initialize to infinity for foreground
-			} else {
-				distField[x][y] = 0 // Background pixels have distance 0
-			}
+	skeleton := make([][]bool, sizeX)
+	for x := range skeleton {
+		skeleton[x] = make([]bool, sizeY)
+		for y := range skeleton[x] {
+			skeleton[x][y] = char.IsDrew(uint16(x), uint16(y))
 		}
 	}
 
-	// Forward pass
-	for x := 0; x < sizeX; x++ {
-		for y := 0; y < sizeY; y++ {
-			if char.IsDrew(uint16(x), uint16(y)) {
-				minDist := distField[x][y]
-
-				// Check neighbors
-				neighbors := [][]int{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}}
-				for _, neighbor := range neighbors {
-					nx, ny := x+neighbor[0], y+neighbor[1]
-					if nx >= 0 && nx < sizeX && ny >= 0 && ny < sizeY {
-						dist := distField[nx][ny]
-						if neighbor[0] != 0 && neighbor[1] != 0 {
-							dist += math.Sqrt2 // Diagonal distance
-						} else {
-							dist += 1.0 // Manhattan distance
-						}
-						if dist < minDist {
-							minDist = dist
-						}
-					}
-				}
-				distField[x][y] = minDist
-			}
-		}
+	at := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < sizeX && y < sizeY && skeleton[x][y]
 	}
 
-	// Backward pass
-	for x := sizeX - 1; x >= 0; x-- {
-		for y := sizeY - 1; y >= 0; y-- {
-			if char.IsDrew(uint16(x), uint16(y)) {
-				minDist := distField[x][y]
-
-				// Check neighbors
-				neighbors := [][]int{{1, 1}, {1, 0}, {1, -1}, {0, 1}}
-				for _, neighbor := range neighbors {
-					nx, ny := x+neighbor[0], y+neighbor[1]
-					if nx >= 0 && nx < sizeX && ny >= 0 && ny < sizeY {
-						dist := distField[nx][ny]
-						if neighbor[0] != 0 && neighbor[1] != 0 {
-							dist += math.Sqrt2 // Diagonal distance
-						} else {
-							dist += 1.0 // Manhattan distance
-						}
-						if dist < minDist {
-							minDist = dist
-						}
+	// Clockwise from north: P2..P9 in the usual notation
+	dx := [8]int{0, 1, 1, 1, 0, -1, -1, -1}
+	dy := [8]int{-1, -1, 0, 1, 1, 1, 0, -1}
+
+	for changed := true; changed; {
+		changed = false
+		for pass := 0; pass < 2; pass++ {
+			var remove [][2]int
+			for x := 0; x < sizeX; x++ {
+				for y := 0; y < sizeY; y++ {
+					if !skeleton[x][y] {
+						continue
 					}
-				}
-				distField[x][y] = minDist
-			}
-		}
-	}
-
-	return distField
-}
-
-func extractMedialAxisPoints(char *character.Character, distField [][]float64) []*character.Point {
-	var medialPoints []*character.Point
-	threshold := char.Config.MedialAxisEpsilon
 
-	sizeX := int(char.SizeX)
-	sizeY := int(char.SizeY)
-
-	for x := 1; x < sizeX-1; x++ {
-		for y := 1; y < sizeY-1; y++ {
-			if !char.IsDrew(uint16(x), uint16(y)) {
-				continue
-			}
-
-			currentDist := distField[x][y]
-			if currentDist < threshold {
-				continue
-			}
-
-			// Check if this is a local maximum (ridge point)
-			isLocalMax := true
-			maxNeighborDist := 0.0
-
-			for dx := -1; dx <= 1; dx++ {
-				for dy := -1; dy <= 1; dy++ {
-					if dx == 0 && dy == 0 {
+					var ring [8]bool
+					count := 0
+					for i := range ring {
+						ring[i] = at(x+dx[i], y+dy[i])
+						if ring[i] {
+							count++
+						}
+					}
+					if count < 2 || count > 6 {
 						continue
 					}
 
-					nx, ny := x+dx, y+dy
-					neighborDist := distField[nx][ny]
-
-					if neighborDist > maxNeighborDist {
-						maxNeighborDist = neighborDist
+					transitions := 0
+					for i := range ring {
+						if !ring[i] && ring[(i+1)%8] {
+							transitions++
+						}
+					}
+					if transitions != 1 {
+						continue
 					}
 
-					if neighborDist > currentDist {
-						isLocalMax = false
+					north, east, south, west := ring[0], ring[2], ring[4], ring[6]
+					if pass == 0 && (north && east && south || east && south && west) {
+						continue
+					}
+					if pass == 1 && (north && east && west || north && south && west) {
+						continue
 					}
+					remove = append(remove, [2]int{x, y})
 				}
 			}
 
-			// Also check if this point is significant enough
-			if isLocalMax && currentDist >= maxNeighborDist*0.9 {
-				medialPoints = append(medialPoints, &character.Point{
-					X: uint16(x),
-					Y: uint16(y),
-				})
+			for _, point := range remove {
+				skeleton[point[0]][point[1]] = false
+			}
+			changed = changed || len(remove) > 0
+		}
+	}
+
+	var medialPoints []*character.Point
+	for x := 0; x < sizeX; x++ {
+		for y := 0; y < sizeY; y++ {
+			if skeleton[x][y] {
+				medialPoints = append(medialPoints, &character.Point{X: uint16(x), Y: uint16(y)})
 			}
 		}
 	}
@@ -255,10 +213,16 @@ func pruneShortBranches(char *character.Character) {
 
 	char.SkeletonBranches = filteredBranches
 
-	// Update medial axis to only include points from retained branches
+	// Update medial axis to only include points from retained branches, in branch key order to keep it reproducible
+	branchKeys := make([]string, 0, len(char.SkeletonBranches))
+	for branchKey := range char.SkeletonBranches {
+		branchKeys = append(branchKeys, branchKey)
+	}
+	sort.Strings(branchKeys)
+
 	var filteredMedialAxis []*character.Point
-	for _, branch := range char.SkeletonBranches {
-		filteredMedialAxis = append(filteredMedialAxis, branch...)
+	for _, branchKey := range branchKeys {
+		filteredMedialAxis = append(filteredMedialAxis, char.SkeletonBranches[branchKey]...)
 	}
 	char.MedialAxis = filteredMedialAxis
 }
@@ -319,7 +283,7 @@ func analyzeConnectivity(char *character.Character) map[string]interface{} {
 	// Euler characteristic: V - E + F = 2 - 2g (for genus g)
 	// For binary images: χ = C - H where C = connected components, H = holes
 
-	connectedComponents := countConnectedComponents(char)
+	connectedComponents := len(CharacterConnectedComponents(char))
 	holes := countHoles(char)
 
 	connectivity["connectedComponents"] = connectedComponents
@@ -329,60 +293,6 @@ func analyzeConnectivity(char *character.Character) map[string]interface{} {
 	return connectivity
 }
 
-func countConnectedComponents(char *character.Character) int {
-	visited := make(map[string]bool)
-	components := 0
-
-	for _, point := range char.Draws {
-		pointKey := getPointKey(point)
-		if visited[pointKey] {
-			continue
-		}
-
-		// Start a new connected component
-		components++
-		floodFillComponent(char, point, visited)
-	}
-
-	return components
-}
-
-func floodFillComponent(char *character.Character, startPoint *character.Point, visited map[string]bool) {
-	stack := []*character.Point{startPoint}
-
-	for len(stack) > 0 {
-		current := stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
-
-		pointKey := getPointKey(current)
-		if visited[pointKey] {
-			continue
-		}
-
-		visited[pointKey] = true
-
-		// Add 8-connected neighbors
-		x, y := int16(current.X), int16(current.Y)
-		for dx := int16(-1); dx <= 1; dx++ {
-			for dy := int16(-1); dy <= 1; dy++ {
-				if dx == 0 && dy == 0 {
-					continue
-				}
-
-				nx, ny := x+dx, y+dy
-				if nx >= 0 && ny >= 0 && nx < int16(char.SizeX) && ny < int16(char.SizeY) {
-					if char.IsDrew(uint16(nx), uint16(ny)) {
-						neighborKey := getPointKey(&character.Point{X: uint16(nx), Y: uint16(ny)})
-						if !visited[neighborKey] {
-							stack = append(stack, &character.Point{X: uint16(nx), Y: uint16(ny)})
-						}
-					}
-				}
-			}
-		}
-	}
-}
-
 func countHoles(char *character.Character) int {
 	// Count holes using background connected components that are surrounded by foreground
 	visited := make(map[string]bool)
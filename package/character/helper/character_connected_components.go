@@ -0,0 +1,44 @@
+package characterHelper
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// CharacterConnectedComponents labels the 8-connected ink of char. Components are listed in the order their first
+// pixel appears in char.Draws, each holding its pixels in the order the flood fill reached them.
+func CharacterConnectedComponents(char *character.Character) [][]*character.Point {
+	var components [][]*character.Point
+	visited := make(map[[2]int]bool, len(char.Draws))
+	for _, start := range char.Draws {
+		key := [2]int{int(start.X), int(start.Y)}
+		if visited[key] || !char.IsDrew(start.X, start.Y) {
+			continue
+		}
+
+		var component []*character.Point
+		stack := []*character.Point{start}
+		visited[key] = true
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, current)
+
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					nx, ny := int(current.X)+dx, int(current.Y)+dy
+					if nx < 0 || ny < 0 || nx >= int(char.SizeX) || ny >= int(char.SizeY) {
+						continue
+					}
+					next := [2]int{nx, ny}
+					if !visited[next] && char.IsDrew(uint16(nx), uint16(ny)) {
+						visited[next] = true
+						stack = append(stack, &character.Point{X: uint16(nx), Y: uint16(ny)})
+					}
+				}
+			}
+		}
+		components = append(components, component)
+	}
+
+	return components
+}
@@ -11,7 +11,8 @@ func CharacterDetectAnchors(char *character.Character) error {
 		return nil
 	}
 
-	char.ClearAnalysisResults()
+	// Only anchors are replaced, results of the other analyses stay valid
+	char.AnchorPoints = []*character.AnchorPoint{}
 
 	// Step 1: Detect contour points (edge pixels)
 	contourPoints := extractContourPoints(char)
@@ -20,7 +21,7 @@ func CharacterDetectAnchors(char *character.Character) error {
 	}
 
 	// Step 2: Compute curvature for each contour point
-	curvatures := computeCurvatures(contourPoints, char.Config.MedialAxisEpsilon)
+	curvatures := computeCurvatures(contourPoints, anchorCurvatureWindow(char.Config), char.Config.MedialAxisEpsilon)
 
 	// Step 3: Detect anchor points based on curvature and topology
 	detectCurvatureAnchors(char, contourPoints, curvatures)
@@ -76,17 +77,23 @@ func extractContourPoints(char *character.Character) []*character.Point {
 	return contour
 }
 
-func computeCurvatures(contour []*character.Point, epsilon float64) []float64 {
+// anchorCurvatureWindow returns the configured curvature window, a config without one keeps the old window of
+// 1/MedialAxisEpsilon points, at least 3
+func anchorCurvatureWindow(config *character.CharacterConfig) int {
+	if config.AnchorCurvatureWindow > 0 {
+		return config.AnchorCurvatureWindow
+	}
+	return int(math.Max(3, 1.0/config.MedialAxisEpsilon))
+}
+
+func computeCurvatures(contour []*character.Point, window int, epsilon float64) []float64 {
 	n := len(contour)
 	curvatures := make([]float64, n)
 
-	for i := 0; i < n; i++ {
-		// Use a local window to compute curvature
-		windowSize := int(math.Max(3, 1.0/epsilon))
-		if windowSize > n/3 {
-			windowSize = n / 3
-		}
+	// Use a local window to compute curvature
+	windowSize := min(window, n/3)
 
+	for i := 0; i < n; i++ {
 		prev := (i - windowSize + n) % n
 		next := (i + windowSize) % n
 
@@ -138,14 +145,18 @@ func computeCurvatures(contour []*character.Point, epsilon float64) []float64 {
 	return curvatures
 }
 
+// detectCurvatureAnchors adds an anchor at each local curvature maximum of the contour. Curvature above the sharp
+// corner threshold makes a sharp_corner, above CurvatureThreshold a corner and above the smooth bend threshold a
+// smooth_bend, a gentle turn of the outline that calling a corner would overstate.
 func detectCurvatureAnchors(char *character.Character, contour []*character.Point, curvatures []float64) {
 	threshold := char.Config.CurvatureThreshold
+	sharpThreshold, bendThreshold := anchorCurvatureThresholds(char.Config)
 
 	for i, point := range contour {
 		curvature := curvatures[i]
 
-		if curvature > threshold {
-			// Check if this is a local maximum
+		if curvature > bendThreshold {
+			// Check if this is a local maximum, a flat run of equal curvature is represented by its first point
 			isLocalMax := true
 			windowSize := 3
 
@@ -154,7 +165,7 @@ func detectCurvatureAnchors(char *character.Character, contour []*character.Poin
 					continue
 				}
 				idx := (i + j + len(curvatures)) % len(curvatures)
-				if curvatures[idx] >= curvature {
+				if curvatures[idx] > curvature || (curvatures[idx] == curvature && j < 0) {
 					isLocalMax = false
 					break
 				}
@@ -162,9 +173,11 @@ func detectCurvatureAnchors(char *character.Character, contour []*character.Poin
 
 			if isLocalMax {
 				// Determine anchor type based on curvature strength
-				anchorType := "corner"
-				if curvature > threshold*2 {
+				anchorType := "smooth_bend"
+				if curvature > sharpThreshold {
 					anchorType = "sharp_corner"
+				} else if curvature > threshold {
+					anchorType = "corner"
 				}
 
 				strength := math.Min(curvature/math.Pi, 1.0)
@@ -176,6 +189,20 @@ func detectCurvatureAnchors(char *character.Character, contour []*character.Poin
 	}
 }
 
+// anchorCurvatureThresholds returns the curvature above which an anchor is a sharp corner and above which it is at
+// least a smooth bend, a config without them keeps sharp corners at twice CurvatureThreshold and bends at half of it
+func anchorCurvatureThresholds(config *character.CharacterConfig) (float64, float64) {
+	multiplier := config.SharpCornerMultiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	bend := config.SmoothBendThreshold
+	if bend <= 0 {
+		bend = config.CurvatureThreshold / 2
+	}
+	return config.CurvatureThreshold * multiplier, bend
+}
+
 func detectJunctionAnchors(char *character.Character) {
 	// Detect junction points where multiple strokes meet
 	// This uses a simplified approach - count connected components in local neighborhoods
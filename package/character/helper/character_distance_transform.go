@@ -0,0 +1,83 @@
+package characterHelper
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+	"math"
+)
+
+// CharacterDistanceTransform returns the chamfer distance from every pixel to the nearest background pixel, indexed
+// [x][y] over the whole canvas. Background pixels are 0, steps count 1 straight and √2 diagonally. The canvas edge is
+// not background, ink touching it measures from the nearest blank pixel inside.
+func CharacterDistanceTransform(char *character.Character) [][]float64 {
+	sizeX := int(char.SizeX)
+	sizeY := int(char.SizeY)
+
+	// Initialize distance field
+	distField := make([][]float64, sizeX)
+	for x := 0; x < sizeX; x++ {
+		distField[x] = make([]float64, sizeY)
+		for y := 0; y < sizeY; y++ {
+			if char.IsDrew(uint16(x), uint16(y)) {
+				distField[x][y] = math.Inf(1) // Initialize to infinity for foreground
+			} else {
+				distField[x][y] = 0 // Background pixels have distance 0
+			}
+		}
+	}
+
+	// Forward pass
+	for x := 0; x < sizeX; x++ {
+		for y := 0; y < sizeY; y++ {
+			if char.IsDrew(uint16(x), uint16(y)) {
+				minDist := distField[x][y]
+
+				// Check neighbors
+				neighbors := [][]int{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}}
+				for _, neighbor := range neighbors {
+					nx, ny := x+neighbor[0], y+neighbor[1]
+					if nx >= 0 && nx < sizeX && ny >= 0 && ny < sizeY {
+						dist := distField[nx][ny]
+						if neighbor[0] != 0 && neighbor[1] != 0 {
+							dist += math.Sqrt2 // Diagonal distance
+						} else {
+							dist += 1.0 // Manhattan distance
+						}
+						if dist < minDist {
+							minDist = dist
+						}
+					}
+				}
+				distField[x][y] = minDist
+			}
+		}
+	}
+
+	// Backward pass
+	for x := sizeX - 1; x >= 0; x-- {
+		for y := sizeY - 1; y >= 0; y-- {
+			if char.IsDrew(uint16(x), uint16(y)) {
+				minDist := distField[x][y]
+
+				// Check neighbors
+				neighbors := [][]int{{1, 1}, {1, 0}, {1, -1}, {0, 1}}
+				for _, neighbor := range neighbors {
+					nx, ny := x+neighbor[0], y+neighbor[1]
+					if nx >= 0 && nx < sizeX && ny >= 0 && ny < sizeY {
+						dist := distField[nx][ny]
+						if neighbor[0] != 0 && neighbor[1] != 0 {
+							dist += math.Sqrt2 // Diagonal distance
+						} else {
+							dist += 1.0 // Manhattan distance
+						}
+						if dist < minDist {
+							minDist = dist
+						}
+					}
+				}
+				distField[x][y] = minDist
+			}
+		}
+	}
+
+	return distField
+}
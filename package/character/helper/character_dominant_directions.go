@@ -0,0 +1,85 @@
+package characterHelper
+
+import (
+	"math"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+const dominantDirectionWindow = 3
+
+// CharacterDominantDirections returns a normalized histogram of skeleton stroke orientations over [0, π) in bins bins, bin 0 centered on horizontal.
+// Each medial axis point contributes the principal direction of the skeleton points around it, weighted by how line like
+// that neighborhood is so junctions and blobs add little.
+func CharacterDominantDirections(char *character.Character, bins int) []float64 {
+	if bins <= 0 || char.IsEmpty() {
+		return nil
+	}
+	histogram := make([]float64, bins)
+
+	if len(char.MedialAxis) == 0 {
+		if err := CharacterComputeMedialAxis(char); err != nil {
+			return histogram
+		}
+	}
+
+	skeleton := make(map[[2]int]bool, len(char.MedialAxis))
+	for _, point := range char.MedialAxis {
+		skeleton[[2]int{int(point.X), int(point.Y)}] = true
+	}
+
+	total := 0.0
+	for _, point := range char.MedialAxis {
+		px, py := int(point.X), int(point.Y)
+
+		// Second moments of the skeleton points in the window around this point
+		count := 0.0
+		sumX, sumY := 0.0, 0.0
+		sumXX, sumYY, sumXY := 0.0, 0.0, 0.0
+		for dx := -dominantDirectionWindow; dx <= dominantDirectionWindow; dx++ {
+			for dy := -dominantDirectionWindow; dy <= dominantDirectionWindow; dy++ {
+				if !skeleton[[2]int{px + dx, py + dy}] {
+					continue
+				}
+				fx, fy := float64(dx), float64(dy)
+				count++
+				sumX += fx
+				sumY += fy
+				sumXX += fx * fx
+				sumYY += fy * fy
+				sumXY += fx * fy
+			}
+		}
+		if count < 3 {
+			continue
+		}
+
+		mu20 := sumXX/count - (sumX/count)*(sumX/count)
+		mu02 := sumYY/count - (sumY/count)*(sumY/count)
+		mu11 := sumXY/count - (sumX/count)*(sumY/count)
+
+		spread := math.Sqrt((mu20-mu02)*(mu20-mu02) + 4*mu11*mu11)
+		if mu20+mu02 == 0 {
+			continue
+		}
+		anisotropy := spread / (mu20 + mu02)
+
+		angle := 0.5 * math.Atan2(2*mu11, mu20-mu02)
+		if angle < 0 {
+			angle += math.Pi
+		}
+		// Bins are centered on their angle so horizontal and vertical strokes do not straddle a boundary
+		bin := int(math.Round(angle/math.Pi*float64(bins))) % bins
+
+		histogram[bin] += anisotropy
+		total += anisotropy
+	}
+
+	if total > 0 {
+		for i := range histogram {
+			histogram[i] /= total
+		}
+	}
+
+	return histogram
+}
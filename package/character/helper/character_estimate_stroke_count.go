@@ -0,0 +1,34 @@
+package characterHelper
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// CharacterEstimateStrokeCount approximates the stroke count by the branches of the skeleton graph, the runs of medial
+// axis between endpoints and junctions. Branches are counted rather than pen strokes, so a '+' counts 4 and an 'O'
+// counts 1. Branches shorter than the mean stroke width are spurs or the bridge between two junctions of one crossing
+// and are not counted. The medial axis is computed when missing, a blank character counts 0.
+func CharacterEstimateStrokeCount(char *character.Character) int {
+	if char.IsEmpty() {
+		return 0
+	}
+
+	if len(char.MedialAxis) == 0 {
+		if err := CharacterComputeMedialAxis(char); err != nil || len(char.MedialAxis) == 0 {
+			return 0
+		}
+	}
+
+	// Ink per skeleton pixel is the mean width of the strokes
+	strokeWidth := float64(char.GetPixelCount()) / float64(len(char.MedialAxis))
+
+	count := 0
+	for _, branch := range char.SkeletonSegments() {
+		if float64(len(branch)) >= strokeWidth {
+			count++
+		}
+	}
+
+	// A glyph thinned to junctions only, such as a dot, is still one stroke
+	return max(count, 1)
+}
@@ -0,0 +1,49 @@
+package characterHelper
+
+import (
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// CharacterStrokeWidthMap returns the stroke width in pixels at every medial axis point, computing the medial axis
+// first when it is missing. The width is read off the distance transform, a skeleton point d from the background
+// lies on a stroke about 2d-1 pixels wide.
+func CharacterStrokeWidthMap(char *character.Character) map[character.Point]float64 {
+	if char.IsEmpty() {
+		return map[character.Point]float64{}
+	}
+
+	if len(char.MedialAxis) == 0 {
+		if err := CharacterComputeMedialAxis(char); err != nil {
+			return map[character.Point]float64{}
+		}
+	}
+
+	distanceField := CharacterDistanceTransform(char)
+	widths := make(map[character.Point]float64, len(char.MedialAxis))
+	for _, point := range char.MedialAxis {
+		widths[*point] = max(1, 2*distanceField[point.X][point.Y]-1)
+	}
+
+	return widths
+}
+
+// CharacterStrokeWidthStats returns the mean and variance of the stroke widths along the medial axis, a uniform pen
+// has a variance near zero while tapering or calligraphic strokes spread out
+func CharacterStrokeWidthStats(char *character.Character) (mean, variance float64) {
+	widths := CharacterStrokeWidthMap(char)
+	if len(widths) == 0 {
+		return 0, 0
+	}
+
+	for _, width := range widths {
+		mean += width
+	}
+	mean /= float64(len(widths))
+
+	for _, width := range widths {
+		variance += (width - mean) * (width - mean)
+	}
+	variance /= float64(len(widths))
+
+	return mean, variance
+}
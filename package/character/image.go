@@ -0,0 +1,128 @@
+package character
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// DefaultForegroundThreshold is the gray level below which a pixel counts as ink
+const DefaultForegroundThreshold = 128
+
+// LoadFromImage creates a character the size of img, drawing every pixel darker than threshold
+func LoadFromImage(img image.Image, threshold uint8, config *CharacterConfig) *Character {
+	bounds := img.Bounds()
+	char := NewCharacter(uint16(bounds.Dx()), uint16(bounds.Dy()), config)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			if c.Y < threshold {
+				char.Draw(uint16(x-bounds.Min.X), uint16(y-bounds.Min.Y))
+			}
+		}
+	}
+
+	return char
+}
+
+// EdgeBox is the ink extent in pixel edge coordinates, a pixel x spans [x, x+1], so the width is MaxX - MinX
+type EdgeBox struct {
+	MinX float64 `json:"minX"`
+	MinY float64 `json:"minY"`
+	MaxX float64 `json:"maxX"`
+	MaxY float64 `json:"maxY"`
+}
+
+func (b *EdgeBox) Width() float64 {
+	return b.MaxX - b.MinX
+}
+
+func (b *EdgeBox) Height() float64 {
+	return b.MaxY - b.MinY
+}
+
+// LoadFromImageSubpixel creates a character the size of img, drawing every pixel at least half covered by ink, and
+// places the stroke edges on the 50% coverage contour interpolated between gray levels. The bitmap matches
+// LoadFromImage at DefaultForegroundThreshold, EdgeBox holds the sub-pixel extent, so anti-aliasing no longer moves
+// the box by whole pixels depending on the threshold
+func LoadFromImageSubpixel(img image.Image, config *CharacterConfig) *Character {
+	char := LoadFromImage(img, DefaultForegroundThreshold, config)
+	if !char.IsEmpty() {
+		char.EdgeBox = SubpixelEdgeBox(img)
+	}
+	return char
+}
+
+// SubpixelEdgeBox returns the extent of the 50% coverage contour of img relative to its bounds, nil when no pixel is
+// at least half covered by ink
+func SubpixelEdgeBox(img image.Image) *EdgeBox {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	coverage := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.GrayModel.Convert(img.At(x+bounds.Min.X, y+bounds.Min.Y)).(color.Gray)
+			coverage[y*width+x] = 1 - float64(c.Y)/255
+		}
+	}
+
+	box := &EdgeBox{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for y := 0; y < height; y++ {
+		if first, last, ok := coverageCrossings(width, func(x int) float64 { return coverage[y*width+x] }); ok {
+			box.MinX = math.Min(box.MinX, first)
+			box.MaxX = math.Max(box.MaxX, last)
+		}
+	}
+	for x := 0; x < width; x++ {
+		if first, last, ok := coverageCrossings(height, func(y int) float64 { return coverage[y*width+x] }); ok {
+			box.MinY = math.Min(box.MinY, first)
+			box.MaxY = math.Max(box.MaxY, last)
+		}
+	}
+	if math.IsInf(box.MinX, 1) || math.IsInf(box.MinY, 1) {
+		return nil
+	}
+
+	return box
+}
+
+// coverageCrossings finds the first and last place along a row or column of n pixels where the coverage, zero beyond
+// the image, crosses one half. An edge pixel is as dark as the share of it the stroke covers, so coverage ramps by one
+// per pixel across a straight edge and the sample nearest one half places it exactly
+func coverageCrossings(n int, at func(i int) float64) (float64, float64, bool) {
+	sample := func(i int) float64 {
+		if i < 0 || i >= n {
+			return 0
+		}
+		return at(i)
+	}
+	crossing := func(outside, inside int) float64 {
+		nearest, value := inside, sample(inside)
+		if a := sample(outside); 0.5-a < value-0.5 {
+			nearest, value = outside, a
+		}
+		return float64(nearest) + 0.5 + float64(inside-outside)*(0.5-value)
+	}
+
+	first := -1
+	for i := 0; i < n; i++ {
+		if sample(i) >= 0.5 {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return 0, 0, false
+	}
+	last := first
+	for i := n - 1; i > first; i-- {
+		if sample(i) >= 0.5 {
+			last = i
+			break
+		}
+	}
+
+	return crossing(first-1, first), crossing(last+1, last), true
+}
@@ -0,0 +1,185 @@
+package character
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WritePBM writes the foreground as a plain (P1) portable bitmap, 1 is ink
+func (c *Character) WritePBM(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	if _, err := fmt.Fprintf(writer, "P1\n%d %d\n", c.SizeX, c.SizeY); err != nil {
+		return err
+	}
+
+	for y := uint16(0); y < c.SizeY; y++ {
+		for x := uint16(0); x < c.SizeX; x++ {
+			// Keep lines within the 70 character limit of the format
+			if x > 0 {
+				separator := byte(' ')
+				if x%35 == 0 {
+					separator = '\n'
+				}
+				if err := writer.WriteByte(separator); err != nil {
+					return err
+				}
+			}
+
+			value := byte('0')
+			if c.IsDrew(x, y) {
+				value = '1'
+			}
+			if err := writer.WriteByte(value); err != nil {
+				return err
+			}
+		}
+		if err := writer.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// ReadPBM reads a plain (P1) or raw (P4) portable bitmap into a new character, failures wrap ErrImageDecode
+func ReadPBM(r io.Reader) (*Character, error) {
+	char, err := readPBM(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrImageDecode, err)
+	}
+	return char, nil
+}
+
+func readPBM(r io.Reader) (*Character, error) {
+	reader := bufio.NewReader(r)
+
+	magic, err := readPBMToken(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pbm header: %w", err)
+	}
+	if magic != "P1" && magic != "P4" {
+		return nil, fmt.Errorf("unsupported pbm format %q", magic)
+	}
+
+	width, err := readPBMInt(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pbm width: %w", err)
+	}
+	height, err := readPBMInt(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pbm height: %w", err)
+	}
+	if width > 65535 || height > 65535 {
+		return nil, fmt.Errorf("pbm dimensions %dx%d exceed maximum size", width, height)
+	}
+
+	char := NewCharacter(uint16(width), uint16(height), nil)
+
+	if magic == "P1" {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				value, err := readPBMPixel(reader)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read pixel (%d,%d): %w", x, y, err)
+				}
+				if value {
+					char.Draw(uint16(x), uint16(y))
+				}
+			}
+		}
+		return char, nil
+	}
+
+	// Raw format has exactly one whitespace byte after the header, rows are padded to whole bytes
+	rowBytes := (width + 7) / 8
+	row := make([]byte, rowBytes)
+	for y := 0; y < height; y++ {
+		if _, err := io.ReadFull(reader, row); err != nil {
+			return nil, fmt.Errorf("failed to read row %d: %w", y, err)
+		}
+		for x := 0; x < width; x++ {
+			if row[x/8]&(0x80>>(x%8)) != 0 {
+				char.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+
+	return char, nil
+}
+
+func readPBMToken(reader *bufio.Reader) (string, error) {
+	token := []byte{}
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(token) > 0 {
+				return string(token), nil
+			}
+			return "", err
+		}
+
+		if b == '#' {
+			if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+				return "", err
+			}
+			if len(token) > 0 {
+				return string(token), nil
+			}
+			continue
+		}
+
+		if isPBMSpace(b) {
+			if len(token) > 0 {
+				return string(token), nil
+			}
+			continue
+		}
+
+		token = append(token, b)
+	}
+}
+
+func readPBMInt(reader *bufio.Reader) (int, error) {
+	token, err := readPBMToken(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.Atoi(token)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid number %q", token)
+	}
+
+	return value, nil
+}
+
+func readPBMPixel(reader *bufio.Reader) (bool, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case b == '0':
+			return false, nil
+		case b == '1':
+			return true, nil
+		case b == '#':
+			if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+				return false, err
+			}
+		case isPBMSpace(b):
+			continue
+		default:
+			return false, fmt.Errorf("unexpected byte %q", b)
+		}
+	}
+}
+
+func isPBMSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f'
+}
@@ -0,0 +1,245 @@
+package character
+
+import "sort"
+
+// SkeletonEndpoints returns the stroke ends of the medial axis, skeleton pixels with at most two neighbors forming a
+// single run around them, in row-major order. The medial axis must be computed first, without it the result is empty.
+func (c *Character) SkeletonEndpoints() []*Point {
+	skeleton := c.skeletonSet()
+	var endpoints []*Point
+	for key := range skeleton {
+		if skeletonCrossings(skeleton, key) == 1 && skeletonDegree(skeleton, key) <= 2 {
+			endpoints = append(endpoints, &Point{X: uint16(key >> 16), Y: uint16(key)})
+		}
+	}
+	sortPointsRowMajor(endpoints)
+	return endpoints
+}
+
+// SkeletonJunctions returns the points where three or more strokes of the medial axis meet, in row-major order.
+// Adjacent junction pixels are one junction, reported by the pixel nearest their center. Strokes crossing at an angle
+// often thin to a 2x2 block in which no single pixel sees three runs, such a block is reported as a junction too, so
+// an 'x' or 'k' has one more junction and a higher characterHelper.CharacterComplexity than a per-pixel test gives.
+func (c *Character) SkeletonJunctions() []*Point {
+	skeleton := c.skeletonSet()
+	junction := skeletonJunctionSet(skeleton)
+
+	var junctions []*Point
+	visited := map[uint32]bool{}
+	for _, start := range sortedSkeletonKeys(junction) {
+		if visited[start] {
+			continue
+		}
+
+		cluster := []uint32{start}
+		visited[start] = true
+		for i := 0; i < len(cluster); i++ {
+			for _, next := range skeletonNeighbors(cluster[i]) {
+				if junction[next] && !visited[next] {
+					visited[next] = true
+					cluster = append(cluster, next)
+				}
+			}
+		}
+
+		sumX, sumY := 0.0, 0.0
+		for _, key := range cluster {
+			sumX += float64(key >> 16)
+			sumY += float64(key & 0xFFFF)
+		}
+		centerX, centerY := sumX/float64(len(cluster)), sumY/float64(len(cluster))
+
+		best, bestDistance := cluster[0], -1.0
+		for _, key := range cluster {
+			dx, dy := float64(key>>16)-centerX, float64(key&0xFFFF)-centerY
+			if distance := dx*dx + dy*dy; bestDistance < 0 || distance < bestDistance {
+				best, bestDistance = key, distance
+			}
+		}
+		junctions = append(junctions, &Point{X: uint16(best >> 16), Y: uint16(best)})
+	}
+
+	sortPointsRowMajor(junctions)
+	return junctions
+}
+
+func (c *Character) skeletonSet() map[uint32]bool {
+	skeleton := make(map[uint32]bool, len(c.MedialAxis))
+	for _, point := range c.MedialAxis {
+		skeleton[uint32(point.X)<<16|uint32(point.Y)] = true
+	}
+	return skeleton
+}
+
+// skeletonJunctionSet marks the skeleton pixels where three or more strokes meet. Strokes crossing at an angle can thin
+// to a 2x2 block whose pixels each see only two runs, such a block is a junction when three or more runs leave it.
+func skeletonJunctionSet(skeleton map[uint32]bool) map[uint32]bool {
+	junction := map[uint32]bool{}
+	for key := range skeleton {
+		if skeletonCrossings(skeleton, key) >= 3 {
+			junction[key] = true
+		}
+	}
+
+	for key := range skeleton {
+		// key is the top left pixel of the block
+		x, y := key>>16, key&0xFFFF
+		if x == 0xFFFF || y == 0xFFFF {
+			continue
+		}
+		block := [4]uint32{key, (x+1)<<16 | y, x<<16 | (y + 1), (x+1)<<16 | (y + 1)}
+		if !skeleton[block[1]] || !skeleton[block[2]] || !skeleton[block[3]] {
+			continue
+		}
+		if skeletonBlockExits(skeleton, block) >= 3 {
+			for _, pixel := range block {
+				junction[pixel] = true
+			}
+		}
+	}
+
+	return junction
+}
+
+// skeletonBlockExits counts the separate runs of skeleton pixels touching block from outside it
+func skeletonBlockExits(skeleton map[uint32]bool, block [4]uint32) int {
+	inBlock := map[uint32]bool{block[0]: true, block[1]: true, block[2]: true, block[3]: true}
+	ring := map[uint32]bool{}
+	for _, pixel := range block {
+		for _, next := range skeletonNeighbors(pixel) {
+			if skeleton[next] && !inBlock[next] {
+				ring[next] = true
+			}
+		}
+	}
+
+	exits := 0
+	visited := map[uint32]bool{}
+	for start := range ring {
+		if visited[start] {
+			continue
+		}
+		exits++
+		stack := []uint32{start}
+		visited[start] = true
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, next := range skeletonNeighbors(current) {
+				if ring[next] && !visited[next] {
+					visited[next] = true
+					stack = append(stack, next)
+				}
+			}
+		}
+	}
+	return exits
+}
+
+// skeletonNeighbors lists the 8 neighbors of key clockwise from north, those off the canvas wrap to keys no skeleton holds
+func skeletonNeighbors(key uint32) [8]uint32 {
+	x, y := int(key>>16), int(key&0xFFFF)
+	dx := [8]int{0, 1, 1, 1, 0, -1, -1, -1}
+	dy := [8]int{-1, -1, 0, 1, 1, 1, 0, -1}
+
+	var neighbors [8]uint32
+	for i := range neighbors {
+		nx, ny := x+dx[i], y+dy[i]
+		if nx < 0 || ny < 0 || nx > 0xFFFF || ny > 0xFFFF {
+			neighbors[i] = ^uint32(0)
+			continue
+		}
+		neighbors[i] = uint32(nx)<<16 | uint32(ny)
+	}
+	return neighbors
+}
+
+// skeletonCrossings counts the separate runs of skeleton pixels around key, so a staircase step counts as one stroke
+func skeletonCrossings(skeleton map[uint32]bool, key uint32) int {
+	neighbors := skeletonNeighbors(key)
+	crossings := 0
+	for i := range neighbors {
+		if !skeleton[neighbors[i]] && skeleton[neighbors[(i+1)%8]] {
+			crossings++
+		}
+	}
+	return crossings
+}
+
+func skeletonDegree(skeleton map[uint32]bool, key uint32) int {
+	degree := 0
+	for _, neighbor := range skeletonNeighbors(key) {
+		if skeleton[neighbor] {
+			degree++
+		}
+	}
+	return degree
+}
+
+func sortedSkeletonKeys(set map[uint32]bool) []uint32 {
+	keys := make([]uint32, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i]&0xFFFF != keys[j]&0xFFFF {
+			return keys[i]&0xFFFF < keys[j]&0xFFFF
+		}
+		return keys[i]>>16 < keys[j]>>16
+	})
+	return keys
+}
+
+func sortPointsRowMajor(points []*Point) {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Y != points[j].Y {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+}
+
+// SkeletonSegments splits the medial axis at its junctions into the connected runs of skeleton pixels between them.
+// Junction pixels and the skeleton pixels touching them belong to no run, otherwise the arms of a '+' would still meet
+// diagonally around the center. Each run lists its pixels in row-major order and runs are ordered by their first pixel.
+// The medial axis must be computed first, without it the result is empty.
+func (c *Character) SkeletonSegments() [][]*Point {
+	skeleton := c.skeletonSet()
+	junction := map[uint32]bool{}
+	for key := range skeletonJunctionSet(skeleton) {
+		junction[key] = true
+		for _, next := range skeletonNeighbors(key) {
+			if skeleton[next] {
+				junction[next] = true
+			}
+		}
+	}
+
+	var segments [][]*Point
+	visited := map[uint32]bool{}
+	for _, start := range sortedSkeletonKeys(skeleton) {
+		if visited[start] || junction[start] {
+			continue
+		}
+
+		run := []uint32{start}
+		visited[start] = true
+		for i := 0; i < len(run); i++ {
+			for _, next := range skeletonNeighbors(run[i]) {
+				if skeleton[next] && !junction[next] && !visited[next] {
+					visited[next] = true
+					run = append(run, next)
+				}
+			}
+		}
+
+		segment := make([]*Point, len(run))
+		for i, key := range run {
+			segment[i] = &Point{X: uint16(key >> 16), Y: uint16(key)}
+		}
+		sortPointsRowMajor(segment)
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
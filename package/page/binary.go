@@ -0,0 +1,172 @@
+package page
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+type BinaryImage struct {
+	Width   int
+	Height  int
+	stride  int
+	bits    []uint64
+	offsetX int
+	offsetY int
+}
+
+func NewBinaryImage(img image.Image, threshold uint8) *BinaryImage {
+	bounds := img.Bounds()
+	b := newBinaryImage(bounds.Dx(), bounds.Dy())
+
+	switch src := img.(type) {
+	case *image.Gray:
+		for y := 0; y < b.Height; y++ {
+			row := src.Pix[y*src.Stride : y*src.Stride+b.Width]
+			for x, v := range row {
+				if v < threshold {
+					b.Set(x, y, true)
+				}
+			}
+		}
+	case *image.RGBA:
+		for y := 0; y < b.Height; y++ {
+			row := src.Pix[y*src.Stride : y*src.Stride+b.Width*4]
+			for x := 0; x < b.Width; x++ {
+				// Same weights as color.GrayModel on 16-bit channels
+				r := uint32(row[x*4]) * 0x101
+				g := uint32(row[x*4+1]) * 0x101
+				bl := uint32(row[x*4+2]) * 0x101
+				gray := uint8((19595*r + 38470*g + 7471*bl + 1<<15) >> 24)
+				if gray < threshold {
+					b.Set(x, y, true)
+				}
+			}
+		}
+	default:
+		for y := 0; y < b.Height; y++ {
+			for x := 0; x < b.Width; x++ {
+				c := color.GrayModel.Convert(img.At(x+bounds.Min.X, y+bounds.Min.Y)).(color.Gray)
+				if c.Y < threshold {
+					b.Set(x, y, true)
+				}
+			}
+		}
+	}
+
+	return b
+}
+
+// Gray renders the image as black ink on a white background
+func (b *BinaryImage) Gray() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, b.Width, b.Height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			if b.Get(x, y) {
+				img.Pix[y*img.Stride+x] = 0
+			}
+		}
+	}
+	return img
+}
+
+func newBinaryImage(width, height int) *BinaryImage {
+	stride := (width + 63) / 64
+	return &BinaryImage{
+		Width:  width,
+		Height: height,
+		stride: stride,
+		bits:   make([]uint64, stride*height),
+	}
+}
+
+func (b *BinaryImage) Get(x, y int) bool {
+	if x < 0 || x >= b.Width || y < 0 || y >= b.Height {
+		return false
+	}
+	px := x + b.offsetX
+	return b.bits[(y+b.offsetY)*b.stride+px/64]&(1<<(px%64)) != 0
+}
+
+func (b *BinaryImage) Set(x, y int, value bool) {
+	if x < 0 || x >= b.Width || y < 0 || y >= b.Height {
+		return
+	}
+	px := x + b.offsetX
+	idx := (y+b.offsetY)*b.stride + px/64
+	if value {
+		b.bits[idx] |= 1 << (px % 64)
+	} else {
+		b.bits[idx] &^= 1 << (px % 64)
+	}
+}
+
+// Sub returns a view sharing the same storage, clipped to the image bounds
+func (b *BinaryImage) Sub(x, y, width, height int) *BinaryImage {
+	if x < 0 {
+		width += x
+		x = 0
+	}
+	if y < 0 {
+		height += y
+		y = 0
+	}
+	if x+width > b.Width {
+		width = b.Width - x
+	}
+	if y+height > b.Height {
+		height = b.Height - y
+	}
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+
+	return &BinaryImage{
+		Width:   width,
+		Height:  height,
+		stride:  b.stride,
+		bits:    b.bits,
+		offsetX: b.offsetX + x,
+		offsetY: b.offsetY + y,
+	}
+}
+
+func (b *BinaryImage) RowCount(y int) int {
+	if y < 0 || y >= b.Height || b.Width == 0 {
+		return 0
+	}
+
+	row := b.bits[(y+b.offsetY)*b.stride : (y+b.offsetY+1)*b.stride]
+	start := b.offsetX
+	end := b.offsetX + b.Width
+
+	count := 0
+	for word := start / 64; word <= (end-1)/64; word++ {
+		mask := ^uint64(0)
+		if word == start/64 {
+			mask &= ^uint64(0) << (start % 64)
+		}
+		if word == (end-1)/64 && end%64 != 0 {
+			mask &= ^uint64(0) >> (64 - end%64)
+		}
+		count += bits.OnesCount64(row[word] & mask)
+	}
+
+	return count
+}
+
+func (b *BinaryImage) ColumnCount(x int) int {
+	count := 0
+	for y := 0; y < b.Height; y++ {
+		if b.Get(x, y) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,190 @@
+package page
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func TestBinaryImageSub(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 150, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 150; x++ {
+			v := uint8(255)
+			if (x+y)%3 == 0 {
+				v = 0
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+
+	binary := NewBinaryImage(img, 128)
+	sub := binary.Sub(60, 5, 80, 10)
+
+	for y := 0; y < sub.Height; y++ {
+		expected := 0
+		for x := 0; x < sub.Width; x++ {
+			want := (x+60+y+5)%3 == 0
+			if sub.Get(x, y) != want {
+				t.Fatalf("Get(%d, %d) = %v, want %v", x, y, sub.Get(x, y), want)
+			}
+			if want {
+				expected++
+			}
+		}
+		if sub.RowCount(y) != expected {
+			t.Errorf("RowCount(%d) = %v, want %v", y, sub.RowCount(y), expected)
+		}
+	}
+
+	if sub.Get(-1, 0) || sub.Get(sub.Width, 0) {
+		t.Errorf("expected out-of-view pixels to be unset")
+	}
+}
+
+func TestBinaryImageMatchesGrayModel(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: uint8(x * y), A: 255})
+		}
+	}
+
+	binary := NewBinaryImage(img, 128)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			if binary.Get(x, y) != (gray.Y < 128) {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, binary.Get(x, y), gray.Y < 128)
+			}
+		}
+	}
+}
+
+func TestBinaryImageDeskew(t *testing.T) {
+	straight := NewBinaryImage(test.RenderText([]string{"DESKEW THE SCANNED", "PAGE BEFORE LINES"}, 2), 128)
+	if angle := straight.EstimateSkew(5, 0.25); angle != 0 {
+		t.Errorf("straight text skew = %v, want 0", angle)
+	}
+
+	skewed := straight.Rotate(3)
+	angle := skewed.EstimateSkew(5, 0.25)
+	if math.Abs(angle-3) > 0.5 {
+		t.Fatalf("skew = %v, want about 3", angle)
+	}
+	if residual := skewed.Rotate(-angle).EstimateSkew(5, 0.25); math.Abs(residual) > 0.5 {
+		t.Errorf("residual skew after deskew = %v, want about 0", residual)
+	}
+}
+
+func TestBinaryImageDenoise(t *testing.T) {
+	b := newBinaryImage(20, 20)
+	b.Set(2, 2, true)
+	b.Set(10, 10, true)
+	b.Set(11, 11, true)
+
+	if removed := b.Denoise(); removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if b.Get(2, 2) || !b.Get(10, 10) || !b.Get(11, 11) {
+		t.Errorf("denoise cleared the wrong pixels")
+	}
+}
+
+func TestPreprocessorChain(t *testing.T) {
+	// Faint ink on a gray background, too light for the default threshold, plus one speck
+	text := test.RenderText([]string{"FAINT"}, 2)
+	bounds := text.Bounds()
+	faint := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			level := uint8(220)
+			if text.GrayAt(x, y).Y < 128 {
+				level = 150
+			}
+			faint.Set(x, y, color.RGBA{R: level, G: level, B: level, A: 255})
+		}
+	}
+	faint.Set(2, 2, color.RGBA{R: 150, G: 150, B: 150, A: 255})
+
+	if ink := NewBinaryImage(faint, 128).RowCount(bounds.Dy() / 2); ink != 0 {
+		t.Fatalf("expected the faint ink to be missed by the default threshold, got %d pixels", ink)
+	}
+
+	cleaned := NewPreprocessor(OtsuFilter(), DenoiseFilter(0)).Apply(faint)
+	if cleaned.Bounds() != bounds {
+		t.Fatalf("bounds = %v, want %v", cleaned.Bounds(), bounds)
+	}
+
+	binary := NewBinaryImage(cleaned, 128)
+	reference := NewBinaryImage(text, 128)
+	if binary.Get(2, 2) {
+		t.Errorf("expected the speck removed")
+	}
+	for y := 0; y < binary.Height; y++ {
+		if got, want := binary.RowCount(y), reference.RowCount(y); got != want {
+			t.Errorf("row %d has %d ink pixels, want %d", y, got, want)
+		}
+	}
+
+	// A gray frame darker than the threshold is a border, the light text inside it is kept
+	framed := image.NewGray(image.Rect(0, 0, 60, 40))
+	for i := range framed.Pix {
+		framed.Pix[i] = 255
+	}
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 60; x++ {
+			if x < 3 || y < 3 || x >= 57 || y >= 37 {
+				framed.Pix[y*framed.Stride+x] = 90
+			} else if y >= 15 && y < 25 && x >= 20 && x < 24 {
+				framed.Pix[y*framed.Stride+x] = 150
+			}
+		}
+	}
+	unframed := NewBinaryImage(NewPreprocessor(BorderFilter(200)).Apply(framed), 128)
+	if unframed.RowCount(1) != 0 || unframed.ColumnCount(1) != 0 {
+		t.Errorf("expected the frame cleared, row 1 has %d and column 1 has %d ink pixels", unframed.RowCount(1), unframed.ColumnCount(1))
+	}
+	if unframed.RowCount(20) != 4 {
+		t.Errorf("expected the 4 pixel stroke kept at the configured threshold, row 20 has %d ink pixels", unframed.RowCount(20))
+	}
+
+	var nothing *Preprocessor
+	if nothing.Apply(faint) != image.Image(faint) {
+		t.Errorf("expected a nil preprocessor to return the image unchanged")
+	}
+}
+
+func BenchmarkPageDetect(b *testing.B) {
+	// Synthetic A4 page at 300 DPI with rows of block glyphs
+	img := image.NewGray(image.Rect(0, 0, 2480, 3508))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for lineY := 200; lineY+40 < 3300; lineY += 60 {
+		for charX := 200; charX+20 < 2280; charX += 28 {
+			if (charX/28)%8 == 0 {
+				continue
+			}
+			for y := lineY; y < lineY+30; y++ {
+				for x := charX; x < charX+20; x++ {
+					if x-charX < 4 || y-lineY < 4 || y-lineY >= 26 {
+						img.Pix[y*img.Stride+x] = 0
+					}
+				}
+			}
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := NewPage(img, nil)
+		_ = p.DetectTextAreas()
+		_ = p.DetectLines()
+		_ = p.DetectWords()
+		_ = p.DetectCharacters()
+	}
+}
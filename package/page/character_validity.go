@@ -0,0 +1,46 @@
+package page
+
+func (c *CharacterBounds) LooksValid() bool {
+	return c.LooksValidWith(DefaultDetectionConfig())
+}
+
+func (c *CharacterBounds) LooksValidWith(config *DetectionConfig) bool {
+	if c.Width < config.MinCharWidth || c.Height < config.MinCharHeight {
+		return false
+	}
+
+	aspect := float64(c.Width) / float64(c.Height)
+	if aspect < config.MinCharAspect || aspect > config.MaxCharAspect {
+		return false
+	}
+
+	if char := c.GetCharacter(); char != nil {
+		density := float64(char.GetPixelCount()) / float64(c.Width*c.Height)
+		if density < config.MinCharDensity {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Page) MarkSuspectCharacters() int {
+	count := 0
+	for _, char := range p.Chars {
+		char.Suspect = !char.LooksValidWith(p.Config)
+		if char.Suspect {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *Page) ValidCharacters() []*CharacterBounds {
+	var chars []*CharacterBounds
+	for _, char := range p.Chars {
+		if char.LooksValidWith(p.Config) {
+			chars = append(chars, char)
+		}
+	}
+	return chars
+}
@@ -0,0 +1,81 @@
+package page
+
+import "fmt"
+
+type DetectionConfig struct {
+	// Text Area Configuration
+	AreaThresholdDivisor int `json:"areaThresholdDivisor"` // Row needs more than width/divisor ink pixels to be text
+	MinAreaHeight        int `json:"minAreaHeight"`        // Minimum height for a text area
+
+	// Line Configuration
+	LineThresholdDivisor int `json:"lineThresholdDivisor"` // Row needs more than areaWidth/divisor ink pixels to be part of a line
+	MinLineHeight        int `json:"minLineHeight"`        // Minimum height for a text line
+
+	// Word Configuration
+	WordColumnThreshold int `json:"wordColumnThreshold"` // Column needs more than this many ink pixels to be part of a word
+	MinWordWidth        int `json:"minWordWidth"`        // Minimum width for a word
+	WordGap             int `json:"wordGap"`             // Number of empty columns that end a word
+
+	// Character Configuration
+	MinCharWidth  int `json:"minCharWidth"`  // Minimum width for a connected component to be a character
+	MinCharHeight int `json:"minCharHeight"` // Minimum height for a connected component to be a character
+
+	// Glyph Validity Configuration
+	MinCharAspect  float64 `json:"minCharAspect"`  // Minimum width/height ratio for a plausible glyph
+	MaxCharAspect  float64 `json:"maxCharAspect"`  // Maximum width/height ratio for a plausible glyph
+	MinCharDensity float64 `json:"minCharDensity"` // Minimum ink ratio within the glyph bounds
+}
+
+func DefaultDetectionConfig() *DetectionConfig {
+	return &DetectionConfig{
+		// Text Area
+		AreaThresholdDivisor: 50,
+		MinAreaHeight:        11,
+
+		// Line
+		LineThresholdDivisor: 100,
+		MinLineHeight:        6,
+
+		// Word
+		WordColumnThreshold: 1,
+		MinWordWidth:        4,
+		WordGap:             1,
+
+		// Character
+		MinCharWidth:  3,
+		MinCharHeight: 4,
+
+		// Glyph Validity
+		MinCharAspect:  0.05,
+		MaxCharAspect:  10.0,
+		MinCharDensity: 0.05,
+	}
+}
+
+func (config *DetectionConfig) Validate() error {
+	if config.AreaThresholdDivisor <= 0 {
+		return fmt.Errorf("areaThresholdDivisor must be positive")
+	}
+	if config.LineThresholdDivisor <= 0 {
+		return fmt.Errorf("lineThresholdDivisor must be positive")
+	}
+	if config.MinAreaHeight < 0 || config.MinLineHeight < 0 || config.MinWordWidth < 0 {
+		return fmt.Errorf("minimum sizes must be non-negative")
+	}
+	if config.WordColumnThreshold < 0 {
+		return fmt.Errorf("wordColumnThreshold must be non-negative")
+	}
+	if config.WordGap <= 0 {
+		return fmt.Errorf("wordGap must be positive")
+	}
+	if config.MinCharWidth <= 0 || config.MinCharHeight <= 0 {
+		return fmt.Errorf("minCharWidth and minCharHeight must be positive")
+	}
+	if config.MinCharAspect < 0 || config.MaxCharAspect < config.MinCharAspect {
+		return fmt.Errorf("charAspect bounds must satisfy 0 <= min <= max")
+	}
+	if config.MinCharDensity < 0 || config.MinCharDensity > 1 {
+		return fmt.Errorf("minCharDensity must be between 0 and 1")
+	}
+	return nil
+}
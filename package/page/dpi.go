@@ -0,0 +1,97 @@
+package page
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	BaselineDPI         = 150
+	BaselineStrokeWidth = 2.0 // Typical body text stroke width in pixels at BaselineDPI
+)
+
+// ScaleForDPI returns a copy of the config with pixel sizes scaled from BaselineDPI to dpi
+func (config *DetectionConfig) ScaleForDPI(dpi int) *DetectionConfig {
+	return config.scale(float64(dpi) / BaselineDPI)
+}
+
+func (config *DetectionConfig) scale(factor float64) *DetectionConfig {
+	scalePixels := func(value, min int) int {
+		scaled := int(math.Round(float64(value) * factor))
+		if scaled < min {
+			return min
+		}
+		return scaled
+	}
+
+	return &DetectionConfig{
+		// Ratios are resolution independent
+		AreaThresholdDivisor: config.AreaThresholdDivisor,
+		LineThresholdDivisor: config.LineThresholdDivisor,
+		MinCharAspect:        config.MinCharAspect,
+		MaxCharAspect:        config.MaxCharAspect,
+		MinCharDensity:       config.MinCharDensity,
+
+		MinAreaHeight:       scalePixels(config.MinAreaHeight, 0),
+		MinLineHeight:       scalePixels(config.MinLineHeight, 0),
+		WordColumnThreshold: scalePixels(config.WordColumnThreshold, 0),
+		MinWordWidth:        scalePixels(config.MinWordWidth, 0),
+		WordGap:             scalePixels(config.WordGap, 1),
+		MinCharWidth:        scalePixels(config.MinCharWidth, 1),
+		MinCharHeight:       scalePixels(config.MinCharHeight, 1),
+	}
+}
+
+func (p *Page) AutoConfigureForDPI(dpi int) error {
+	if dpi <= 0 {
+		return fmt.Errorf("dpi must be positive")
+	}
+
+	factor := float64(dpi) / BaselineDPI
+
+	// Sanity check against the measured stroke width, trust it when the dpi is off by more than 2x
+	strokeWidth := p.EstimateStrokeWidth()
+	if strokeWidth > 0 {
+		strokeFactor := strokeWidth / BaselineStrokeWidth
+		factor = math.Max(strokeFactor/2, math.Min(strokeFactor*2, factor))
+	}
+
+	p.Config = DefaultDetectionConfig().scale(factor)
+	return nil
+}
+
+func (p *Page) EstimateStrokeWidth() float64 {
+	binary := p.binaryImage()
+
+	var runs []int
+	for y := 0; y < binary.Height; y++ {
+		run := 0
+		for x := 0; x <= binary.Width; x++ {
+			if x < binary.Width && binary.Get(x, y) {
+				run++
+			} else if run > 0 {
+				runs = append(runs, run)
+				run = 0
+			}
+		}
+	}
+	for x := 0; x < binary.Width; x++ {
+		run := 0
+		for y := 0; y <= binary.Height; y++ {
+			if y < binary.Height && binary.Get(x, y) {
+				run++
+			} else if run > 0 {
+				runs = append(runs, run)
+				run = 0
+			}
+		}
+	}
+
+	if len(runs) == 0 {
+		return 0
+	}
+
+	sort.Ints(runs)
+	return float64(runs[len(runs)/2])
+}
@@ -0,0 +1,76 @@
+package page
+
+import (
+	"image"
+	"os"
+)
+
+func ExamplePage_WriteJSON() {
+	p := NewPage(image.NewGray(image.Rect(0, 0, 40, 20)), nil)
+
+	char := &CharacterBounds{X: 4, Y: 3, Width: 8, Height: 12, Unicode: "0041", Text: "A", Confidence: 92.5}
+	word := &Word{X: 4, Y: 3, Width: 8, Height: 12, Text: "A", Chars: []*CharacterBounds{char}, Confidence: 92.5}
+	line := &TextLine{X: 4, Y: 3, Width: 8, Height: 12, Words: []*Word{word}, Text: "A", Chars: []*CharacterBounds{char}}
+	line.EstimateMetrics()
+	p.TextAreas = []*TextArea{{X: 0, Y: 3, Width: 40, Height: 12, Lines: []*TextLine{line}}}
+
+	_ = p.WriteJSON(os.Stdout)
+
+	// Output:
+	// {
+	//   "width": 40,
+	//   "height": 20,
+	//   "text_areas": [
+	//     {
+	//       "x": 0,
+	//       "y": 3,
+	//       "width": 40,
+	//       "height": 12,
+	//       "lines": [
+	//         {
+	//           "x": 4,
+	//           "y": 3,
+	//           "width": 8,
+	//           "height": 12,
+	//           "words": [
+	//             {
+	//               "x": 4,
+	//               "y": 3,
+	//               "width": 8,
+	//               "height": 12,
+	//               "text": "A",
+	//               "character_ids": [
+	//                 0
+	//               ],
+	//               "confidence": 92.5
+	//             }
+	//           ],
+	//           "text": "A",
+	//           "baseline": 15,
+	//           "x_height": 12,
+	//           "character_ids": [
+	//             0
+	//           ]
+	//         }
+	//       ]
+	//     }
+	//   ],
+	//   "lines": [],
+	//   "words": [],
+	//   "characters": [
+	//     {
+	//       "id": 0,
+	//       "x": 4,
+	//       "y": 3,
+	//       "width": 8,
+	//       "height": 12,
+	//       "unicode": "0041",
+	//       "text": "A",
+	//       "confidence": 92.5,
+	//       "suspect": false,
+	//       "relative_top": 1,
+	//       "relative_bottom": 0
+	//     }
+	//   ]
+	// }
+}
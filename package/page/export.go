@@ -0,0 +1,47 @@
+package page
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// SavePNG encodes img to path, creating the parent directories
+func SavePNG(img image.Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return file.Close()
+}
+
+// ExportCharacterCrops saves the binarized crop of every detected character to dir as
+// crop_<x>_<y>_<unicode>.png, unrecognized characters use "unknown" in place of the unicode
+func (p *Page) ExportCharacterCrops(dir string) error {
+	binary := p.binaryImage()
+	for _, char := range p.Chars {
+		unicode := char.Unicode
+		if unicode == "" {
+			unicode = "unknown"
+		}
+		name := fmt.Sprintf("crop_%05d_%05d_%s.png", char.X, char.Y, unicode)
+
+		crop := binary.Sub(char.X, char.Y, char.Width, char.Height).Gray()
+		if err := SavePNG(crop, filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("character at (%d, %d): %w", char.X, char.Y, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package page
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// MarshalJSON writes every character once under "characters" with an id, lines and words list their characters by
+// those ids in "character_ids" instead of repeating them
+func (p *Page) MarshalJSON() ([]byte, error) {
+	ids := map[*CharacterBounds]int{}
+	chars := []characterJSON{}
+	idsOf := func(list []*CharacterBounds) []int {
+		refs := make([]int, 0, len(list))
+		for _, char := range list {
+			id, ok := ids[char]
+			if !ok {
+				// Characters only reachable through a line or word still get listed once
+				id = len(chars)
+				ids[char] = id
+				chars = append(chars, characterJSON{ID: id, CharacterBounds: char})
+			}
+			refs = append(refs, id)
+		}
+		return refs
+	}
+	idsOf(p.Chars)
+
+	words := map[*Word]*wordJSON{}
+	wordOf := func(word *Word) *wordJSON {
+		if encoded, ok := words[word]; ok {
+			return encoded
+		}
+		encoded := &wordJSON{
+			X:          word.X,
+			Y:          word.Y,
+			Width:      word.Width,
+			Height:     word.Height,
+			Text:       word.Text,
+			Chars:      idsOf(word.Chars),
+			Confidence: word.Confidence,
+		}
+		words[word] = encoded
+		return encoded
+	}
+
+	lines := map[*TextLine]*lineJSON{}
+	lineOf := func(line *TextLine) *lineJSON {
+		if encoded, ok := lines[line]; ok {
+			return encoded
+		}
+		encoded := &lineJSON{
+			X:        line.X,
+			Y:        line.Y,
+			Width:    line.Width,
+			Height:   line.Height,
+			Words:    make([]*wordJSON, 0, len(line.Words)),
+			Text:     line.Text,
+			Baseline: line.Baseline,
+			XHeight:  line.XHeight,
+			Chars:    idsOf(line.Chars),
+		}
+		for _, word := range line.Words {
+			encoded.Words = append(encoded.Words, wordOf(word))
+		}
+		lines[line] = encoded
+		return encoded
+	}
+
+	encoded := pageJSON{
+		Width:     p.Width,
+		Height:    p.Height,
+		TextAreas: make([]*areaJSON, 0, len(p.TextAreas)),
+		Lines:     make([]*lineJSON, 0, len(p.Lines)),
+		Words:     make([]*wordJSON, 0, len(p.Words)),
+		Grid:      p.Grid,
+	}
+	for _, area := range p.TextAreas {
+		encodedArea := &areaJSON{
+			X:      area.X,
+			Y:      area.Y,
+			Width:  area.Width,
+			Height: area.Height,
+			Lines:  make([]*lineJSON, 0, len(area.Lines)),
+		}
+		for _, line := range area.Lines {
+			encodedArea.Lines = append(encodedArea.Lines, lineOf(line))
+		}
+		encoded.TextAreas = append(encoded.TextAreas, encodedArea)
+	}
+	for _, line := range p.Lines {
+		encoded.Lines = append(encoded.Lines, lineOf(line))
+	}
+	for _, word := range p.Words {
+		encoded.Words = append(encoded.Words, wordOf(word))
+	}
+	encoded.Chars = chars
+
+	return json.Marshal(encoded)
+}
+
+type pageJSON struct {
+	Width     int             `json:"width"`
+	Height    int             `json:"height"`
+	TextAreas []*areaJSON     `json:"text_areas"`
+	Lines     []*lineJSON     `json:"lines"`
+	Words     []*wordJSON     `json:"words"`
+	Chars     []characterJSON `json:"characters"`
+	Grid      *MonospaceGrid  `json:"grid,omitempty"`
+}
+
+type areaJSON struct {
+	X      int         `json:"x"`
+	Y      int         `json:"y"`
+	Width  int         `json:"width"`
+	Height int         `json:"height"`
+	Lines  []*lineJSON `json:"lines"`
+}
+
+type lineJSON struct {
+	X        int         `json:"x"`
+	Y        int         `json:"y"`
+	Width    int         `json:"width"`
+	Height   int         `json:"height"`
+	Words    []*wordJSON `json:"words"`
+	Text     string      `json:"text"`
+	Baseline int         `json:"baseline"`
+	XHeight  int         `json:"x_height"`
+	Chars    []int       `json:"character_ids"`
+}
+
+type wordJSON struct {
+	X          int     `json:"x"`
+	Y          int     `json:"y"`
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	Text       string  `json:"text"`
+	Chars      []int   `json:"character_ids"`
+	Confidence float64 `json:"confidence"`
+}
+
+type characterJSON struct {
+	ID int `json:"id"`
+	*CharacterBounds
+}
+
+func (p *Page) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// SaveJSON writes the page to path, "-" writes to stdout
+func (p *Page) SaveJSON(path string) error {
+	if path == "-" {
+		return p.WriteJSON(os.Stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := p.WriteJSON(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,34 @@
+package page
+
+import "sort"
+
+// EstimateMetrics derives baseline and x-height from the median character extents and positions each character against them
+func (l *TextLine) EstimateMetrics() {
+	if len(l.Chars) == 0 {
+		return
+	}
+
+	tops := make([]int, 0, len(l.Chars))
+	bottoms := make([]int, 0, len(l.Chars))
+	for _, char := range l.Chars {
+		tops = append(tops, char.Y)
+		bottoms = append(bottoms, char.Y+char.Height)
+	}
+
+	l.Baseline = medianInt(bottoms)
+	l.XHeight = l.Baseline - medianInt(tops)
+	if l.XHeight <= 0 {
+		return
+	}
+
+	for _, char := range l.Chars {
+		char.RelativeTop = float64(l.Baseline-char.Y) / float64(l.XHeight)
+		char.RelativeBottom = float64(l.Baseline-(char.Y+char.Height)) / float64(l.XHeight)
+	}
+}
+
+func medianInt(values []int) int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted[len(sorted)/2]
+}
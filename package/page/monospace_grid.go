@@ -0,0 +1,180 @@
+package page
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MonospaceGrid is the fixed character cell of a monospace or tabular page, column 0 starts at OriginX and row 0 is the topmost line
+type MonospaceGrid struct {
+	CellWidth  float64 `json:"cell_width"`
+	CellHeight float64 `json:"cell_height"`
+	OriginX    float64 `json:"origin_x"`
+	OriginY    float64 `json:"origin_y"`
+}
+
+// GridCell is the column and row a character snaps to on the page's monospace grid
+type GridCell struct {
+	Column int `json:"column"`
+	Row    int `json:"row"`
+}
+
+// DetectMonospaceGrid estimates a fixed cell size from the median spacing of neighboring characters and lines, then
+// snaps every character to a cell. Characters must already be detected. Once a grid is set GetPlainText lays the text
+// out by cell, so the gaps between columns come out as spaces instead of relying on the word gap heuristic
+func (p *Page) DetectMonospaceGrid() error {
+	var advances, widths []float64
+	for _, line := range p.Lines {
+		chars := append([]*CharacterBounds(nil), line.Chars...)
+		sort.Slice(chars, func(i, j int) bool {
+			return chars[i].centerX() < chars[j].centerX()
+		})
+		for i, char := range chars {
+			widths = append(widths, float64(char.Width))
+			if i > 0 {
+				advances = append(advances, char.centerX()-chars[i-1].centerX())
+			}
+		}
+	}
+	if len(widths) == 0 {
+		return fmt.Errorf("no characters detected to estimate a monospace grid")
+	}
+
+	// Parts of one glyph, like the dot over an i, share a cell and are not an advance
+	minAdvance := medianFloat(widths) / 2
+	var steps []float64
+	for _, advance := range advances {
+		if advance >= minAdvance {
+			steps = append(steps, advance)
+		}
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("not enough adjacent characters to estimate a monospace grid")
+	}
+
+	// Most neighbors are adjacent cells, so the median skips the wider advances over spaces
+	grid := &MonospaceGrid{CellWidth: medianFloat(steps)}
+	grid.CellHeight, grid.OriginY = estimateGridRows(p.Lines)
+	grid.OriginX = estimateGridOrigin(p.Chars, grid.CellWidth)
+
+	for _, line := range p.Lines {
+		row := int(math.Round((float64(line.Y) - grid.OriginY) / grid.CellHeight))
+		for _, char := range line.Chars {
+			char.Cell = &GridCell{
+				Column: int(math.Floor((char.centerX() - grid.OriginX) / grid.CellWidth)),
+				Row:    row,
+			}
+		}
+	}
+
+	p.Grid = grid
+	return nil
+}
+
+// estimateGridRows returns the median line pitch and the top of the first line, a single line uses its own height
+func estimateGridRows(lines []*TextLine) (float64, float64) {
+	if len(lines) == 0 {
+		return 1, 0
+	}
+
+	heights := make([]float64, 0, len(lines))
+	for _, line := range lines {
+		heights = append(heights, float64(line.Height))
+	}
+	height := medianFloat(heights)
+
+	var pitches []float64
+	for i := 1; i < len(lines); i++ {
+		if pitch := float64(lines[i].Y - lines[i-1].Y); pitch >= height/2 {
+			pitches = append(pitches, pitch)
+		}
+	}
+	if len(pitches) > 0 {
+		height = medianFloat(pitches)
+	}
+
+	return math.Max(height, 1), float64(lines[0].Y)
+}
+
+// estimateGridOrigin places the column boundaries so character centers fall mid-cell, using the median phase of
+// every center against the cell width measured from the leftmost one
+func estimateGridOrigin(chars []*CharacterBounds, cellWidth float64) float64 {
+	left := math.Inf(1)
+	for _, char := range chars {
+		left = math.Min(left, char.centerX())
+	}
+
+	phases := make([]float64, 0, len(chars))
+	for _, char := range chars {
+		phase := math.Mod(char.centerX()-left, cellWidth)
+		if phase >= cellWidth/2 {
+			phase -= cellWidth
+		}
+		phases = append(phases, phase)
+	}
+
+	origin := left + medianFloat(phases) - cellWidth/2
+	// The leftmost character may sit slightly left of the median phase, keep it in column 0
+	for origin > left {
+		origin -= cellWidth
+	}
+	return origin
+}
+
+// gridText lays the recognized characters out by cell, empty cells become spaces and empty rows blank lines. A cell
+// holding several components keeps the most confident text
+func (p *Page) gridText() string {
+	type cellText struct {
+		text       string
+		confidence float64
+	}
+	rows := map[int]map[int]cellText{}
+	lastRow := -1
+	for _, line := range p.Lines {
+		for _, char := range line.Chars {
+			if char.Cell == nil || char.Text == "" {
+				continue
+			}
+			cells := rows[char.Cell.Row]
+			if cells == nil {
+				cells = map[int]cellText{}
+				rows[char.Cell.Row] = cells
+			}
+			if current, ok := cells[char.Cell.Column]; !ok || char.Confidence > current.confidence {
+				cells[char.Cell.Column] = cellText{char.Text, char.Confidence}
+			}
+			lastRow = max(lastRow, char.Cell.Row)
+		}
+	}
+
+	var text strings.Builder
+	for row := 0; row <= lastRow; row++ {
+		if row > 0 {
+			text.WriteString("\n")
+		}
+		lastColumn := -1
+		for column := range rows[row] {
+			lastColumn = max(lastColumn, column)
+		}
+		for column := 0; column <= lastColumn; column++ {
+			if cell, ok := rows[row][column]; ok {
+				text.WriteString(cell.text)
+			} else {
+				text.WriteString(" ")
+			}
+		}
+	}
+	return text.String()
+}
+
+func (c *CharacterBounds) centerX() float64 {
+	return float64(c.X) + float64(c.Width)/2
+}
+
+func medianFloat(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted[len(sorted)/2]
+}
@@ -1,11 +1,12 @@
 package page
 
 import (
+	"context"
 	"image"
-	"image/color"
 	"sort"
 
 	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/package/recognize"
 )
 
 type Page struct {
@@ -16,6 +17,16 @@ type Page struct {
 	Lines     []*TextLine        `json:"lines"`
 	Words     []*Word            `json:"words"`
 	Chars     []*CharacterBounds `json:"characters"`
+	Grid      *MonospaceGrid     `json:"grid,omitempty"` // Set by DetectMonospaceGrid
+	Binary    *BinaryImage       `json:"-"`
+	Config    *DetectionConfig   `json:"-"`
+	Threshold uint8              `json:"-"` // Gray level below which a pixel is ink when binarizing
+
+	// Options used to extract glyph features and match them during recognition
+	ExtractOptions   recognize.ExtractOptions   `json:"-"`
+	RecognizeOptions recognize.RecognizeOptions `json:"-"`
+
+	index *charIndex // Built by QueryBox
 }
 
 type TextArea struct {
@@ -34,6 +45,7 @@ type TextLine struct {
 	Words    []*Word            `json:"words"`
 	Text     string             `json:"text"`
 	Baseline int                `json:"baseline"`
+	XHeight  int                `json:"x_height"`
 	Chars    []*CharacterBounds `json:"characters"`
 }
 
@@ -56,9 +68,23 @@ type CharacterBounds struct {
 	Unicode    string               `json:"unicode"`
 	Text       string               `json:"text"`
 	Confidence float64              `json:"confidence"`
+	Suspect    bool                 `json:"suspect"`
+	Cell       *GridCell            `json:"cell,omitempty"` // Set by DetectMonospaceGrid
+
+	// Vertical extent relative to the line, 0 is the baseline and 1 the x-height
+	RelativeTop    float64 `json:"relative_top"`
+	RelativeBottom float64 `json:"relative_bottom"`
+
+	// Binary the glyph is cropped from on first use and the crop origin within it
+	source           *BinaryImage
+	sourceX, sourceY int
 }
 
-func NewPage(img image.Image) *Page {
+func NewPage(img image.Image, config *DetectionConfig) *Page {
+	if config == nil {
+		config = DefaultDetectionConfig()
+	}
+
 	bounds := img.Bounds()
 	return &Page{
 		Width:     bounds.Dx(),
@@ -68,18 +94,20 @@ func NewPage(img image.Image) *Page {
 		Lines:     []*TextLine{},
 		Words:     []*Word{},
 		Chars:     []*CharacterBounds{},
+		Config:    config,
+		Threshold: character.DefaultForegroundThreshold,
 	}
 }
 
 func (p *Page) DetectTextAreas() error {
-	textAreas := findTextAreas(p.Image)
+	textAreas := findTextAreas(p.binaryImage(), p.Config)
 	p.TextAreas = textAreas
 	return nil
 }
 
 func (p *Page) DetectLines() error {
 	for _, area := range p.TextAreas {
-		lines := findLinesInArea(p.Image, area)
+		lines := findLinesInArea(p.binaryImage(), area, p.Config)
 		area.Lines = lines
 		p.Lines = append(p.Lines, lines...)
 	}
@@ -96,16 +124,47 @@ func (p *Page) DetectLines() error {
 
 func (p *Page) DetectWords() error {
 	for _, line := range p.Lines {
-		words := findWordsInLine(p.Image, line)
+		words := findWordsInLine(p.binaryImage(), line, p.Config)
 		line.Words = words
 		p.Words = append(p.Words, words...)
 	}
 	return nil
 }
 
+// DetectWordsStream runs DetectWords in the background and sends each line's words as soon as the line is split.
+// Lines must already be detected. The channel closes when every line is done or ctx is cancelled, the page's
+// Words and line Words match DetectWords once it has closed and must not be read before then
+func (p *Page) DetectWordsStream(ctx context.Context) <-chan *Word {
+	words := make(chan *Word)
+	binary := p.binaryImage()
+
+	go func() {
+		defer close(words)
+		for _, line := range p.Lines {
+			if ctx.Err() != nil {
+				return
+			}
+
+			found := findWordsInLine(binary, line, p.Config)
+			line.Words = found
+			p.Words = append(p.Words, found...)
+
+			for _, word := range found {
+				select {
+				case words <- word:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return words
+}
+
 func (p *Page) DetectCharacters() error {
 	for _, word := range p.Words {
-		chars := findCharactersInWord(p.Image, word)
+		chars := findCharactersInWord(p.binaryImage(), word, p.Config)
 		word.Chars = chars
 		p.Chars = append(p.Chars, chars...)
 	}
@@ -114,11 +173,24 @@ func (p *Page) DetectCharacters() error {
 		for _, word := range line.Words {
 			line.Chars = append(line.Chars, word.Chars...)
 		}
+		line.EstimateMetrics()
 	}
 
 	return nil
 }
 
+// RemoveBorders clears solid ink along the image edges before detection, see BinaryImage.RemoveBorders
+func (p *Page) RemoveBorders() {
+	p.binaryImage().RemoveBorders()
+}
+
+func (p *Page) binaryImage() *BinaryImage {
+	if p.Binary == nil {
+		p.Binary = NewBinaryImage(p.Image, p.Threshold)
+	}
+	return p.Binary
+}
+
 func (p *Page) GetText() string {
 	text := ""
 	for i, line := range p.Lines {
@@ -130,7 +202,12 @@ func (p *Page) GetText() string {
 	return text
 }
 
+// GetPlainText joins line words with single spaces, or lays characters out by cell once DetectMonospaceGrid has run
 func (p *Page) GetPlainText() string {
+	if p.Grid != nil {
+		return p.gridText()
+	}
+
 	text := ""
 	for i, line := range p.Lines {
 		if i > 0 {
@@ -148,36 +225,21 @@ func (p *Page) GetPlainText() string {
 	return text
 }
 
-func findTextAreas(img image.Image) []*TextArea {
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	// Convert to binary
-	binary := make([][]bool, height)
-	for y := 0; y < height; y++ {
-		binary[y] = make([]bool, width)
-		for x := 0; x < width; x++ {
-			c := color.GrayModel.Convert(img.At(x+bounds.Min.X, y+bounds.Min.Y)).(color.Gray)
-			binary[y][x] = c.Y < 128
-		}
-	}
+func findTextAreas(binary *BinaryImage, config *DetectionConfig) []*TextArea {
+	width := binary.Width
+	height := binary.Height
 
 	// Find horizontal projections
 	hProjection := make([]int, height)
 	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			if binary[y][x] {
-				hProjection[y]++
-			}
-		}
+		hProjection[y] = binary.RowCount(y)
 	}
 
 	// Find text blocks based on horizontal projection
 	var areas []*TextArea
 	inText := false
 	startY := 0
-	threshold := width / 50 // Minimum pixels per line to consider text
+	threshold := width / config.AreaThresholdDivisor // Minimum pixels per line to consider text
 
 	for y := 0; y < height; y++ {
 		if hProjection[y] > threshold && !inText {
@@ -185,7 +247,7 @@ func findTextAreas(img image.Image) []*TextArea {
 			startY = y
 		} else if hProjection[y] <= threshold && inText {
 			inText = false
-			if y-startY > 10 { // Minimum height for text area
+			if y-startY >= config.MinAreaHeight {
 				area := &TextArea{
 					X:      0,
 					Y:      startY,
@@ -199,7 +261,7 @@ func findTextAreas(img image.Image) []*TextArea {
 	}
 
 	// Handle case where text continues to end of image
-	if inText && height-startY > 10 {
+	if inText && height-startY >= config.MinAreaHeight {
 		area := &TextArea{
 			X:      0,
 			Y:      startY,
@@ -213,36 +275,21 @@ func findTextAreas(img image.Image) []*TextArea {
 	return areas
 }
 
-func findLinesInArea(img image.Image, area *TextArea) []*TextLine {
-	bounds := img.Bounds()
-
-	// Extract area image
-	binary := make([][]bool, area.Height)
-	for y := 0; y < area.Height; y++ {
-		binary[y] = make([]bool, area.Width)
-		for x := 0; x < area.Width; x++ {
-			imgY := y + area.Y + bounds.Min.Y
-			imgX := x + area.X + bounds.Min.X
-			c := color.GrayModel.Convert(img.At(imgX, imgY)).(color.Gray)
-			binary[y][x] = c.Y < 128
-		}
-	}
+func findLinesInArea(page *BinaryImage, area *TextArea, config *DetectionConfig) []*TextLine {
+	// Extract area view
+	binary := page.Sub(area.X, area.Y, area.Width, area.Height)
 
 	// Find horizontal projection for lines
 	hProjection := make([]int, area.Height)
 	for y := 0; y < area.Height; y++ {
-		for x := 0; x < area.Width; x++ {
-			if binary[y][x] {
-				hProjection[y]++
-			}
-		}
+		hProjection[y] = binary.RowCount(y)
 	}
 
 	// Find individual lines
 	var lines []*TextLine
 	inLine := false
 	startY := 0
-	threshold := area.Width / 100 // Minimum pixels per line
+	threshold := area.Width / config.LineThresholdDivisor // Minimum pixels per line
 
 	for y := 0; y < area.Height; y++ {
 		if hProjection[y] > threshold && !inLine {
@@ -250,7 +297,7 @@ func findLinesInArea(img image.Image, area *TextArea) []*TextLine {
 			startY = y
 		} else if hProjection[y] <= threshold && inLine {
 			inLine = false
-			if y-startY > 5 { // Minimum line height
+			if y-startY >= config.MinLineHeight {
 				// Find actual text bounds in this line
 				minX, maxX := findLineBounds(binary, startY, y)
 				if maxX > minX {
@@ -271,7 +318,7 @@ func findLinesInArea(img image.Image, area *TextArea) []*TextLine {
 	}
 
 	// Handle case where line continues to end of area
-	if inLine && area.Height-startY > 5 {
+	if inLine && area.Height-startY >= config.MinLineHeight {
 		minX, maxX := findLineBounds(binary, startY, area.Height)
 		if maxX > minX {
 			line := &TextLine{
@@ -291,13 +338,13 @@ func findLinesInArea(img image.Image, area *TextArea) []*TextLine {
 	return lines
 }
 
-func findLineBounds(binary [][]bool, startY, endY int) (int, int) {
-	minX := len(binary[0])
+func findLineBounds(binary *BinaryImage, startY, endY int) (int, int) {
+	minX := binary.Width
 	maxX := 0
 
-	for y := startY; y < endY && y < len(binary); y++ {
-		for x := 0; x < len(binary[y]); x++ {
-			if binary[y][x] {
+	for y := startY; y < endY && y < binary.Height; y++ {
+		for x := 0; x < binary.Width; x++ {
+			if binary.Get(x, y) {
 				if x < minX {
 					minX = x
 				}
@@ -311,92 +358,64 @@ func findLineBounds(binary [][]bool, startY, endY int) (int, int) {
 	return minX, maxX + 1
 }
 
-func findWordsInLine(img image.Image, line *TextLine) []*Word {
-	bounds := img.Bounds()
-
-	// Extract line image
-	binary := make([][]bool, line.Height)
-	for y := 0; y < line.Height; y++ {
-		binary[y] = make([]bool, line.Width)
-		for x := 0; x < line.Width; x++ {
-			imgY := y + line.Y + bounds.Min.Y
-			imgX := x + line.X + bounds.Min.X
-			c := color.GrayModel.Convert(img.At(imgX, imgY)).(color.Gray)
-			binary[y][x] = c.Y < 128
-		}
-	}
+func findWordsInLine(page *BinaryImage, line *TextLine, config *DetectionConfig) []*Word {
+	// Extract line view
+	binary := page.Sub(line.X, line.Y, line.Width, line.Height)
 
 	// Find vertical projection
 	vProjection := make([]int, line.Width)
 	for x := 0; x < line.Width; x++ {
-		for y := 0; y < line.Height; y++ {
-			if binary[y][x] {
-				vProjection[x]++
-			}
-		}
+		vProjection[x] = binary.ColumnCount(x)
 	}
 
 	// Find word boundaries
 	var words []*Word
 	inWord := false
 	startX := 0
-	threshold := 1 // Minimum pixels per column to be part of word
+	lastX := 0
+	threshold := config.WordColumnThreshold // Minimum pixels per column to be part of word
 
 	for x := 0; x < line.Width; x++ {
-		if vProjection[x] > threshold && !inWord {
-			inWord = true
-			startX = x
-		} else if vProjection[x] <= threshold && inWord {
+		if vProjection[x] > threshold {
+			if !inWord {
+				inWord = true
+				startX = x
+			}
+			lastX = x
+		} else if inWord && x-lastX >= config.WordGap {
 			inWord = false
-			if x-startX > 3 { // Minimum word width
-				word := &Word{
-					X:          line.X + startX,
-					Y:          line.Y,
-					Width:      x - startX,
-					Height:     line.Height,
-					Text:       "",
-					Chars:      []*CharacterBounds{},
-					Confidence: 0.0,
-				}
-				words = append(words, word)
+			if lastX+1-startX >= config.MinWordWidth {
+				words = append(words, newWord(line, startX, lastX+1))
 			}
 		}
 	}
 
 	// Handle case where word continues to end of line
-	if inWord && line.Width-startX > 3 {
-		word := &Word{
-			X:          line.X + startX,
-			Y:          line.Y,
-			Width:      line.Width - startX,
-			Height:     line.Height,
-			Text:       "",
-			Chars:      []*CharacterBounds{},
-			Confidence: 0.0,
-		}
-		words = append(words, word)
+	if inWord && lastX+1-startX >= config.MinWordWidth {
+		words = append(words, newWord(line, startX, lastX+1))
 	}
 
 	return words
 }
 
-func findCharactersInWord(img image.Image, word *Word) []*CharacterBounds {
-	bounds := img.Bounds()
-
-	// Extract word image
-	binary := make([][]bool, word.Height)
-	for y := 0; y < word.Height; y++ {
-		binary[y] = make([]bool, word.Width)
-		for x := 0; x < word.Width; x++ {
-			imgY := y + word.Y + bounds.Min.Y
-			imgX := x + word.X + bounds.Min.X
-			c := color.GrayModel.Convert(img.At(imgX, imgY)).(color.Gray)
-			binary[y][x] = c.Y < 128
-		}
+func newWord(line *TextLine, startX, endX int) *Word {
+	return &Word{
+		X:          line.X + startX,
+		Y:          line.Y,
+		Width:      endX - startX,
+		Height:     line.Height,
+		Text:       "",
+		Chars:      []*CharacterBounds{},
+		Confidence: 0.0,
 	}
+}
+
+func findCharactersInWord(page *BinaryImage, word *Word, config *DetectionConfig) []*CharacterBounds {
+	// Extract word view
+	binary := page.Sub(word.X, word.Y, word.Width, word.Height)
 
 	// Find character boundaries using connected components
-	chars := findConnectedComponents(binary, word)
+	chars := findConnectedComponents(binary, word, config)
 
 	// Sort characters left to right
 	sort.Slice(chars, func(i, j int) bool {
@@ -406,34 +425,29 @@ func findCharactersInWord(img image.Image, word *Word) []*CharacterBounds {
 	return chars
 }
 
-func findConnectedComponents(binary [][]bool, word *Word) []*CharacterBounds {
-	height := len(binary)
-	width := len(binary[0])
-	visited := make([][]bool, height)
-	for i := range visited {
-		visited[i] = make([]bool, width)
-	}
+func findConnectedComponents(binary *BinaryImage, word *Word, config *DetectionConfig) []*CharacterBounds {
+	visited := newBinaryImage(binary.Width, binary.Height)
 
 	var chars []*CharacterBounds
 
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			if binary[y][x] && !visited[y][x] {
+	for y := 0; y < binary.Height; y++ {
+		for x := 0; x < binary.Width; x++ {
+			if binary.Get(x, y) && !visited.Get(x, y) {
 				minX, minY, maxX, maxY := floodFill(binary, visited, x, y)
 
 				// Filter out noise (very small components)
-				if maxX-minX >= 2 && maxY-minY >= 3 {
-					charImg := extractCharacterImage(binary, minX, minY, maxX-minX+1, maxY-minY+1)
-
+				if maxX-minX+1 >= config.MinCharWidth && maxY-minY+1 >= config.MinCharHeight {
 					char := &CharacterBounds{
 						X:          word.X + minX,
 						Y:          word.Y + minY,
 						Width:      maxX - minX + 1,
 						Height:     maxY - minY + 1,
-						Character:  charImg,
 						Unicode:    "",
 						Text:       "",
 						Confidence: 0.0,
+						source:     binary,
+						sourceX:    minX,
+						sourceY:    minY,
 					}
 					chars = append(chars, char)
 				}
@@ -444,10 +458,46 @@ func findConnectedComponents(binary [][]bool, word *Word) []*CharacterBounds {
 	return chars
 }
 
-func floodFill(binary, visited [][]bool, startX, startY int) (int, int, int, int) {
-	height := len(binary)
-	width := len(binary[0])
+// GetCharacter returns the glyph image, cropping it from the page binary on the first call
+func (c *CharacterBounds) GetCharacter() *character.Character {
+	if c.Character == nil && c.source != nil {
+		c.Character = extractCharacterImage(c.source, c.sourceX, c.sourceY, c.Width, c.Height)
+		c.source = nil
+	}
+	return c.Character
+}
 
+// MeasureEdgeBoxes sets the sub-pixel ink extent of every detected character from the gray page image, see
+// character.SubpixelEdgeBox. Bounds have to refer to Image, so call it before rotating Binary. An image type without
+// SubImage is left alone
+func (p *Page) MeasureEdgeBoxes() {
+	cropper, ok := p.Image.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return
+	}
+
+	origin := p.Image.Bounds().Min
+	for _, char := range p.Chars {
+		glyph := char.GetCharacter()
+		if glyph == nil {
+			continue
+		}
+
+		// A pixel of margin lets the half coverage contour of an anti-aliased edge fall outside the binary box
+		crop := image.Rect(char.X-1, char.Y-1, char.X+char.Width+1, char.Y+char.Height+1).Add(origin).Intersect(p.Image.Bounds())
+		box := character.SubpixelEdgeBox(cropper.SubImage(crop))
+		if box == nil {
+			continue
+		}
+
+		dx, dy := float64(crop.Min.X-origin.X-char.X), float64(crop.Min.Y-origin.Y-char.Y)
+		glyph.EdgeBox = &character.EdgeBox{MinX: box.MinX + dx, MinY: box.MinY + dy, MaxX: box.MaxX + dx, MaxY: box.MaxY + dy}
+	}
+}
+
+func floodFill(binary, visited *BinaryImage, startX, startY int) (int, int, int, int) {
 	minX, minY := startX, startY
 	maxX, maxY := startX, startY
 
@@ -457,11 +507,11 @@ func floodFill(binary, visited [][]bool, startX, startY int) (int, int, int, int
 		x, y := stack[len(stack)-1][0], stack[len(stack)-1][1]
 		stack = stack[:len(stack)-1]
 
-		if x < 0 || x >= width || y < 0 || y >= height || visited[y][x] || !binary[y][x] {
+		if x < 0 || x >= binary.Width || y < 0 || y >= binary.Height || visited.Get(x, y) || !binary.Get(x, y) {
 			continue
 		}
 
-		visited[y][x] = true
+		visited.Set(x, y, true)
 
 		if x < minX {
 			minX = x
@@ -486,17 +536,12 @@ func floodFill(binary, visited [][]bool, startX, startY int) (int, int, int, int
 	return minX, minY, maxX, maxY
 }
 
-func extractCharacterImage(binary [][]bool, x, y, width, height int) *character.Character {
+func extractCharacterImage(binary *BinaryImage, x, y, width, height int) *character.Character {
 	char := character.NewCharacter(uint16(width), uint16(height), nil)
 
 	for py := 0; py < height; py++ {
 		for px := 0; px < width; px++ {
-			sourceY := y + py
-			sourceX := x + px
-
-			if sourceY >= 0 && sourceY < len(binary) &&
-				sourceX >= 0 && sourceX < len(binary[sourceY]) &&
-				binary[sourceY][sourceX] {
+			if binary.Get(x+px, y+py) {
 				char.Draw(uint16(px), uint16(py))
 			}
 		}
@@ -0,0 +1,648 @@
+package page
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/package/recognize"
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func detectAll(p *Page) {
+	_ = p.DetectTextAreas()
+	_ = p.DetectLines()
+	_ = p.DetectWords()
+	_ = p.DetectCharacters()
+}
+
+func TestDetectionConfigScaled(t *testing.T) {
+	lines := []string{"HELLO WORLD", "GLYPH CANVAS", "SCALE TEST 42"}
+
+	base := NewPage(test.RenderText(lines, 2), nil)
+	detectAll(base)
+
+	if len(base.Lines) == 0 || len(base.Chars) == 0 {
+		t.Fatalf("expected detections at base scale, got %d lines and %d characters", len(base.Lines), len(base.Chars))
+	}
+
+	defaults := DefaultDetectionConfig()
+	scaledConfig := &DetectionConfig{
+		AreaThresholdDivisor: defaults.AreaThresholdDivisor,
+		MinAreaHeight:        defaults.MinAreaHeight * 2,
+		LineThresholdDivisor: defaults.LineThresholdDivisor,
+		MinLineHeight:        defaults.MinLineHeight * 2,
+		WordColumnThreshold:  defaults.WordColumnThreshold * 2,
+		MinWordWidth:         defaults.MinWordWidth * 2,
+		WordGap:              defaults.WordGap * 2,
+		MinCharWidth:         defaults.MinCharWidth * 2,
+		MinCharHeight:        defaults.MinCharHeight * 2,
+	}
+	if err := scaledConfig.Validate(); err != nil {
+		t.Fatalf("scaled config invalid: %v", err)
+	}
+
+	scaled := NewPage(test.RenderText(lines, 4), scaledConfig)
+	detectAll(scaled)
+
+	if len(scaled.TextAreas) != len(base.TextAreas) {
+		t.Errorf("text areas = %d, want %d", len(scaled.TextAreas), len(base.TextAreas))
+	}
+	if len(scaled.Lines) != len(base.Lines) {
+		t.Errorf("lines = %d, want %d", len(scaled.Lines), len(base.Lines))
+	}
+	if len(scaled.Words) != len(base.Words) {
+		t.Errorf("words = %d, want %d", len(scaled.Words), len(base.Words))
+	}
+	if len(scaled.Chars) != len(base.Chars) {
+		t.Fatalf("characters = %d, want %d", len(scaled.Chars), len(base.Chars))
+	}
+
+	for i := range base.Chars {
+		if scaled.Chars[i].Width != base.Chars[i].Width*2 || scaled.Chars[i].Height != base.Chars[i].Height*2 {
+			t.Errorf("character %d size = %dx%d, want %dx%d", i, scaled.Chars[i].Width, scaled.Chars[i].Height, base.Chars[i].Width*2, base.Chars[i].Height*2)
+		}
+	}
+}
+
+func TestDetectionConfigValidate(t *testing.T) {
+	config := DefaultDetectionConfig()
+	if err := config.Validate(); err != nil {
+		t.Errorf("default config invalid: %v", err)
+	}
+
+	config.AreaThresholdDivisor = 0
+	if err := config.Validate(); err == nil {
+		t.Errorf("expected error for zero areaThresholdDivisor")
+	}
+}
+
+func TestScaleForDPILinear(t *testing.T) {
+	defaults := DefaultDetectionConfig()
+
+	for _, multiple := range []int{1, 2, 4} {
+		config := defaults.ScaleForDPI(BaselineDPI * multiple)
+
+		if config.MinAreaHeight != defaults.MinAreaHeight*multiple {
+			t.Errorf("dpi %d: minAreaHeight = %d, want %d", BaselineDPI*multiple, config.MinAreaHeight, defaults.MinAreaHeight*multiple)
+		}
+		if config.MinLineHeight != defaults.MinLineHeight*multiple {
+			t.Errorf("dpi %d: minLineHeight = %d, want %d", BaselineDPI*multiple, config.MinLineHeight, defaults.MinLineHeight*multiple)
+		}
+		if config.MinWordWidth != defaults.MinWordWidth*multiple {
+			t.Errorf("dpi %d: minWordWidth = %d, want %d", BaselineDPI*multiple, config.MinWordWidth, defaults.MinWordWidth*multiple)
+		}
+		if config.WordGap != defaults.WordGap*multiple {
+			t.Errorf("dpi %d: wordGap = %d, want %d", BaselineDPI*multiple, config.WordGap, defaults.WordGap*multiple)
+		}
+		if config.MinCharWidth != defaults.MinCharWidth*multiple || config.MinCharHeight != defaults.MinCharHeight*multiple {
+			t.Errorf("dpi %d: char size = %dx%d, want %dx%d", BaselineDPI*multiple, config.MinCharWidth, config.MinCharHeight, defaults.MinCharWidth*multiple, defaults.MinCharHeight*multiple)
+		}
+		if config.AreaThresholdDivisor != defaults.AreaThresholdDivisor || config.LineThresholdDivisor != defaults.LineThresholdDivisor {
+			t.Errorf("dpi %d: threshold divisors should not scale", BaselineDPI*multiple)
+		}
+	}
+}
+
+func TestAutoConfigureForDPI(t *testing.T) {
+	lines := []string{"HELLO WORLD", "GLYPH CANVAS"}
+
+	// Basic font strokes are 1px, so scale 2 matches the baseline stroke width
+	p := NewPage(test.RenderText(lines, 4), nil)
+	if err := p.AutoConfigureForDPI(300); err != nil {
+		t.Fatalf("AutoConfigureForDPI failed: %v", err)
+	}
+	if p.Config.MinCharHeight != DefaultDetectionConfig().MinCharHeight*2 {
+		t.Errorf("minCharHeight = %d, want %d", p.Config.MinCharHeight, DefaultDetectionConfig().MinCharHeight*2)
+	}
+
+	// A dpi far from what the strokes suggest is clamped
+	p = NewPage(test.RenderText(lines, 2), nil)
+	if err := p.AutoConfigureForDPI(1200); err != nil {
+		t.Fatalf("AutoConfigureForDPI failed: %v", err)
+	}
+	if p.Config.MinCharHeight != DefaultDetectionConfig().MinCharHeight*2 {
+		t.Errorf("clamped minCharHeight = %d, want %d", p.Config.MinCharHeight, DefaultDetectionConfig().MinCharHeight*2)
+	}
+
+	if err := p.AutoConfigureForDPI(0); err == nil {
+		t.Errorf("expected error for zero dpi")
+	}
+}
+
+func TestCharacterBoundsLooksValid(t *testing.T) {
+	flat := &CharacterBounds{Width: 12, Height: 1, Character: character.NewCharacter(12, 1, nil)}
+	for x := uint16(0); x < 12; x++ {
+		flat.Character.Draw(x, 0)
+	}
+	if flat.LooksValid() {
+		t.Errorf("expected 1px-tall character to be flagged invalid")
+	}
+
+	sparse := &CharacterBounds{Width: 20, Height: 20, Character: character.NewCharacter(20, 20, nil)}
+	sparse.Character.Draw(0, 0)
+	sparse.Character.Draw(19, 19)
+	if sparse.LooksValid() {
+		t.Errorf("expected nearly empty character to be flagged invalid")
+	}
+
+	p := NewPage(test.RenderText([]string{"VALID"}, 2), nil)
+	detectAll(p)
+	p.Chars = append(p.Chars, flat)
+
+	if suspects := p.MarkSuspectCharacters(); suspects != 1 {
+		t.Errorf("suspects = %d, want 1", suspects)
+	}
+	if !flat.Suspect {
+		t.Errorf("expected flat character to be marked suspect")
+	}
+	if len(p.ValidCharacters()) != len(p.Chars)-1 {
+		t.Errorf("valid characters = %d, want %d", len(p.ValidCharacters()), len(p.Chars)-1)
+	}
+}
+
+func TestCharacterBoundsGetCharacter(t *testing.T) {
+	p := NewPage(test.RenderText([]string{"LAZY GLYPHS"}, 2), nil)
+	detectAll(p)
+	if len(p.Chars) == 0 {
+		t.Fatalf("no characters detected")
+	}
+
+	binary := p.binaryImage()
+	for i, char := range p.Chars {
+		if char.Character != nil {
+			t.Fatalf("char %d built before GetCharacter", i)
+		}
+
+		eager := extractCharacterImage(binary, char.X, char.Y, char.Width, char.Height)
+		lazy := char.GetCharacter()
+		if lazy == nil {
+			t.Fatalf("char %d: GetCharacter returned nil", i)
+		}
+		if lazy.ContentHash() != eager.ContentHash() {
+			t.Errorf("char %d: lazy character differs from eager crop", i)
+		}
+		if char.GetCharacter() != lazy {
+			t.Errorf("char %d: GetCharacter rebuilt the character", i)
+		}
+	}
+}
+
+func TestPageQueryBox(t *testing.T) {
+	p := NewPage(test.RenderText([]string{"HELLO WORLD", "GLYPH CANVAS"}, 2), nil)
+	detectAll(p)
+	if len(p.Words) < 2 {
+		t.Fatalf("expected at least 2 words, got %d", len(p.Words))
+	}
+
+	for _, word := range p.Words {
+		found := p.QueryBox(image.Rect(word.X, word.Y, word.X+word.Width, word.Y+word.Height))
+		if !reflect.DeepEqual(found, word.Chars) {
+			t.Errorf("word at (%d, %d): query returned %d characters, want its %d", word.X, word.Y, len(found), len(word.Chars))
+		}
+	}
+
+	// Every query agrees with a linear scan
+	for _, r := range []image.Rectangle{
+		image.Rect(0, 0, p.Width, p.Height),
+		image.Rect(p.Width/3, 0, p.Width/2, p.Height),
+		image.Rect(-100, -100, 5, 5),
+	} {
+		var want []*CharacterBounds
+		for _, char := range p.Chars {
+			if image.Rect(char.X, char.Y, char.X+char.Width, char.Y+char.Height).Overlaps(r) {
+				want = append(want, char)
+			}
+		}
+		if found := p.QueryBox(r); !reflect.DeepEqual(found, want) {
+			t.Errorf("query %v returned %d characters, want %d", r, len(found), len(want))
+		}
+	}
+
+	extra := &CharacterBounds{X: p.Width + 10, Y: 0, Width: 5, Height: 5}
+	p.Chars = append(p.Chars, extra)
+	if found := p.QueryBox(image.Rect(p.Width, 0, p.Width+20, 10)); len(found) != 1 || found[0] != extra {
+		t.Errorf("expected the index to pick up an appended character, got %v", found)
+	}
+
+	// Reordering in place keeps the slice header, results must still follow the new page order
+	p.Chars[0], p.Chars[1] = p.Chars[1], p.Chars[0]
+	if found := p.QueryBox(image.Rect(0, 0, p.Width, p.Height)); len(found) < 2 || found[0] != p.Chars[0] || found[1] != p.Chars[1] {
+		t.Errorf("expected the index to pick up reordered characters")
+	}
+
+	extra.X, extra.Y = 0, p.Height+10
+	if found := p.QueryBox(image.Rect(0, p.Height, 20, p.Height+20)); len(found) != 1 || found[0] != extra {
+		t.Errorf("expected the index to pick up a moved character, got %v", found)
+	}
+}
+
+func TestPageExportCharacterCrops(t *testing.T) {
+	p := NewPage(test.RenderText([]string{"AB"}, 2), nil)
+	p.Chars = []*CharacterBounds{
+		{X: 20, Y: 24, Width: 12, Height: 18, Unicode: "0041"},
+		{X: 34, Y: 24, Width: 10, Height: 16},
+	}
+
+	dir := filepath.Join(t.TempDir(), "crops")
+	if err := p.ExportCharacterCrops(dir); err != nil {
+		t.Fatalf("ExportCharacterCrops failed: %v", err)
+	}
+
+	for name, size := range map[string]image.Point{
+		"crop_00020_00024_0041.png":    {12, 18},
+		"crop_00034_00024_unknown.png": {10, 16},
+	} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected crop %s: %v", name, err)
+		}
+		config, err := png.DecodeConfig(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("decoding %s: %v", name, err)
+		}
+		if config.Width != size.X || config.Height != size.Y {
+			t.Errorf("%s is %dx%d, want %dx%d", name, config.Width, config.Height, size.X, size.Y)
+		}
+	}
+}
+
+func TestPageRemoveBorders(t *testing.T) {
+	lines := []string{"BORDERED SCAN", "KEEP THIS TEXT"}
+	framed := test.RenderText(lines, 2)
+	bounds := framed.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			if x < 5 || y < 5 || x >= bounds.Dx()-5 || y >= bounds.Dy()-5 {
+				framed.Pix[y*framed.Stride+x] = 0
+			}
+		}
+	}
+
+	p := NewPage(framed, nil)
+	p.RemoveBorders()
+
+	reference := NewBinaryImage(test.RenderText(lines, 2), 128)
+	binary := p.binaryImage()
+	for y := 0; y < binary.Height; y++ {
+		for x := 0; x < binary.Width; x++ {
+			if binary.Get(x, y) != reference.Get(x, y) {
+				t.Fatalf("pixel (%d, %d) = %v after removing the border, want %v", x, y, binary.Get(x, y), reference.Get(x, y))
+			}
+		}
+	}
+
+	clean := NewPage(test.RenderText(lines, 2), nil)
+	detectAll(p)
+	detectAll(clean)
+	if len(p.Lines) != len(clean.Lines) || len(p.Chars) != len(clean.Chars) {
+		t.Errorf("detected %d lines and %d characters, want %d and %d", len(p.Lines), len(p.Chars), len(clean.Lines), len(clean.Chars))
+	}
+
+	if cleared := reference.RemoveBorders(); cleared != 0 {
+		t.Errorf("cleared %d pixels from a page without a border", cleared)
+	}
+}
+
+func TestPageMeasureEdgeBoxes(t *testing.T) {
+	// A 10x20 ink block whose right edge is a quarter covered column the binary threshold leaves out
+	img := image.NewGray(image.Rect(0, 0, 40, 30))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for y := 5; y < 25; y++ {
+		for x := 10; x < 20; x++ {
+			img.Pix[y*img.Stride+x] = 0
+		}
+		img.Pix[y*img.Stride+20] = 191
+	}
+
+	p := NewPage(img, nil)
+	p.Chars = []*CharacterBounds{{X: 10, Y: 5, Width: 10, Height: 20, Character: character.NewCharacter(10, 20, nil)}}
+	p.MeasureEdgeBoxes()
+
+	box := p.Chars[0].Character.EdgeBox
+	if box == nil {
+		t.Fatal("expected an edge box")
+	}
+	want := character.EdgeBox{MinX: 0, MinY: 0, MaxX: 10.25, MaxY: 20}
+	if math.Abs(box.MinX-want.MinX) > 0.01 || math.Abs(box.MinY-want.MinY) > 0.01 || math.Abs(box.MaxX-want.MaxX) > 0.01 || math.Abs(box.MaxY-want.MaxY) > 0.01 {
+		t.Errorf("edge box = %+v, want %+v in glyph coordinates", *box, want)
+	}
+}
+
+func TestTextLineEstimateMetrics(t *testing.T) {
+	line := &TextLine{}
+	for i := 0; i < 4; i++ {
+		line.Chars = append(line.Chars, &CharacterBounds{X: i * 12, Y: 20, Width: 10, Height: 10})
+	}
+	superscript := &CharacterBounds{X: 48, Y: 16, Width: 4, Height: 4}
+	descender := &CharacterBounds{X: 56, Y: 20, Width: 10, Height: 14}
+	line.Chars = append(line.Chars, superscript, descender)
+
+	line.EstimateMetrics()
+
+	if line.Baseline != 30 || line.XHeight != 10 {
+		t.Fatalf("baseline = %d, x-height = %d, want 30 and 10", line.Baseline, line.XHeight)
+	}
+
+	baseline := line.Chars[0]
+	if baseline.RelativeTop != 1 || baseline.RelativeBottom != 0 {
+		t.Errorf("baseline glyph offsets = %v..%v, want 0..1", baseline.RelativeBottom, baseline.RelativeTop)
+	}
+	if superscript.RelativeBottom < 0.9 || superscript.RelativeTop <= 1 {
+		t.Errorf("superscript offsets = %v..%v, want raised above x-height", superscript.RelativeBottom, superscript.RelativeTop)
+	}
+	if descender.RelativeBottom >= 0 {
+		t.Errorf("descender bottom = %v, want below baseline", descender.RelativeBottom)
+	}
+}
+
+func buildTestDatabase(tb testing.TB, letters string) *recognize.FeatureDatabase {
+	database := &recognize.FeatureDatabase{Characters: map[string]*recognize.CharacterFeature{}}
+	for _, letter := range letters {
+		features, err := recognize.ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{string(letter)}, 2)))
+		if err != nil {
+			tb.Fatalf("ExtractFeatures(%q) failed: %v", letter, err)
+		}
+		features.Unicode = fmt.Sprintf("%04X", letter)
+		database.Characters[features.Unicode] = features
+	}
+	return database
+}
+
+func TestProcessImage(t *testing.T) {
+	database := buildTestDatabase(t, "HIO")
+
+	stages := map[string]bool{}
+	p, err := ProcessImage(test.RenderText([]string{"HI HO"}, 2), database, ProcessOptions{
+		Denoise: true,
+		Progress: func(stage string, done, total int) {
+			stages[stage] = true
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessImage failed: %v", err)
+	}
+
+	for _, stage := range []string{"binarize", "areas", "lines", "words", "characters", "recognize"} {
+		if !stages[stage] {
+			t.Errorf("no progress reported for stage %q", stage)
+		}
+	}
+	if len(p.Lines) != 1 || len(p.Chars) == 0 {
+		t.Fatalf("got %d lines and %d characters, want 1 line with characters", len(p.Lines), len(p.Chars))
+	}
+	for _, char := range p.Chars {
+		if char.Unicode == "" || char.Text == "" {
+			t.Errorf("character at %d,%d was not recognized", char.X, char.Y)
+		}
+	}
+	if p.Lines[0].Text == "" {
+		t.Errorf("line text was not assembled")
+	}
+
+	subpixel, err := ProcessImage(test.RenderText([]string{"HI HO"}, 2), database, ProcessOptions{EdgeBox: true})
+	if err != nil {
+		t.Fatalf("ProcessImage with EdgeBox failed: %v", err)
+	}
+	for _, char := range subpixel.Chars {
+		if char.GetCharacter().EdgeBox == nil {
+			t.Errorf("character at %d,%d has no edge box", char.X, char.Y)
+		}
+	}
+
+	if _, err := ProcessImage(test.RenderText([]string{"HI"}, 1), &recognize.FeatureDatabase{}, ProcessOptions{}); err == nil {
+		t.Errorf("expected error for empty database")
+	}
+}
+
+func TestRecognizeAllParallel(t *testing.T) {
+	database := buildTestDatabase(t, "HIOX")
+	p := NewPage(test.RenderText([]string{"HOX IX OH", "XI HO IOX"}, 2), nil)
+	detectAll(p)
+	if len(p.Chars) == 0 {
+		t.Fatal("expected detected characters")
+	}
+
+	var mutex sync.Mutex
+	last := -1
+	p.RecognizeAll(database, 4, func(stage string, done, total int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if done < last || total != len(p.Chars) {
+			t.Errorf("progress went from %d to %d of %d", last, done, total)
+		}
+		last = done
+	})
+
+	if last != len(p.Chars) {
+		t.Errorf("final progress = %d, want %d", last, len(p.Chars))
+	}
+	for _, char := range p.Chars {
+		if char.Unicode == "" {
+			t.Errorf("character at %d,%d was not recognized", char.X, char.Y)
+		}
+	}
+}
+
+func BenchmarkRecognizeAll(b *testing.B) {
+	database := buildTestDatabase(b, "HIOX")
+
+	// A few hundred characters across several lines
+	var lines []string
+	for i := 0; i < 12; i++ {
+		lines = append(lines, strings.Repeat("HOX IX ", 5))
+	}
+	img := test.RenderText(lines, 2)
+
+	for _, workers := range []int{1, 0} {
+		name := "serial"
+		if workers == 0 {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				p := NewPage(img, nil)
+				detectAll(p)
+				b.StartTimer()
+				p.RecognizeAll(database, workers, nil)
+			}
+		})
+	}
+}
+
+func TestDetectWordsStream(t *testing.T) {
+	lines := []string{"HELLO WORLD", "STREAM THE WORDS", "ONE BY ONE"}
+
+	batch := NewPage(test.RenderText(lines, 2), nil)
+	_ = batch.DetectTextAreas()
+	_ = batch.DetectLines()
+	_ = batch.DetectWords()
+
+	stream := NewPage(test.RenderText(lines, 2), nil)
+	_ = stream.DetectTextAreas()
+	_ = stream.DetectLines()
+
+	var received []*Word
+	for word := range stream.DetectWordsStream(context.Background()) {
+		received = append(received, word)
+	}
+
+	if len(batch.Words) == 0 {
+		t.Fatalf("batch detection found no words")
+	}
+	if !reflect.DeepEqual(received, batch.Words) {
+		t.Errorf("streamed %d words, batch found %d, or they differ", len(received), len(batch.Words))
+	}
+	if !reflect.DeepEqual(stream.Words, batch.Words) {
+		t.Errorf("page words after streaming differ from DetectWords")
+	}
+	for i := range stream.Lines {
+		if len(stream.Lines[i].Words) != len(batch.Lines[i].Words) {
+			t.Errorf("line %d has %d words, batch %d", i, len(stream.Lines[i].Words), len(batch.Lines[i].Words))
+		}
+	}
+
+	// Cancelling stops the producer and closes the channel
+	cancelled := NewPage(test.RenderText(lines, 2), nil)
+	_ = cancelled.DetectTextAreas()
+	_ = cancelled.DetectLines()
+	ctx, cancel := context.WithCancel(context.Background())
+	words := cancelled.DetectWordsStream(ctx)
+	<-words
+	cancel()
+	count := 1
+	for range words {
+		count++
+	}
+	if count >= len(batch.Words) {
+		t.Errorf("received all %d words despite cancelling after the first", count)
+	}
+}
+
+func TestPageWriteJSON(t *testing.T) {
+	p := NewPage(test.RenderText([]string{"HI HO", "OH"}, 2), nil)
+	detectAll(p)
+	if len(p.Chars) == 0 {
+		t.Fatalf("no characters detected")
+	}
+
+	var buffer strings.Builder
+	if err := p.WriteJSON(&buffer); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var decoded struct {
+		Lines []struct {
+			Words []struct {
+				CharacterIDs []int `json:"character_ids"`
+			} `json:"words"`
+			CharacterIDs []int `json:"character_ids"`
+		} `json:"lines"`
+		Characters []struct {
+			ID int `json:"id"`
+			X  int `json:"x"`
+			Y  int `json:"y"`
+		} `json:"characters"`
+	}
+	if err := json.Unmarshal([]byte(buffer.String()), &decoded); err != nil {
+		t.Fatalf("failed to decode page JSON: %v", err)
+	}
+
+	if len(decoded.Characters) != len(p.Chars) {
+		t.Fatalf("got %d characters in JSON, want %d", len(decoded.Characters), len(p.Chars))
+	}
+	for i, char := range decoded.Characters {
+		if char.ID != i || char.X != p.Chars[i].X || char.Y != p.Chars[i].Y {
+			t.Errorf("character %d encoded as id %d at %d,%d, want %d,%d", i, char.ID, char.X, char.Y, p.Chars[i].X, p.Chars[i].Y)
+		}
+	}
+
+	// Each character's fields appear exactly once, everywhere else refers to it by id
+	if count := strings.Count(buffer.String(), `"relative_top"`); count != len(p.Chars) {
+		t.Errorf("character fields appear %d times, want %d", count, len(p.Chars))
+	}
+
+	referenced := 0
+	for i, line := range decoded.Lines {
+		if len(line.CharacterIDs) != len(p.Lines[i].Chars) {
+			t.Errorf("line %d lists %d character ids, want %d", i, len(line.CharacterIDs), len(p.Lines[i].Chars))
+		}
+		for j, id := range line.CharacterIDs {
+			if id < 0 || id >= len(p.Chars) || p.Chars[id] != p.Lines[i].Chars[j] {
+				t.Errorf("line %d character %d refers to id %d, not the same character", i, j, id)
+			}
+		}
+		for _, word := range line.Words {
+			referenced += len(word.CharacterIDs)
+		}
+	}
+	if referenced != len(p.Chars) {
+		t.Errorf("line words reference %d characters, want %d", referenced, len(p.Chars))
+	}
+}
+
+func TestDetectMonospaceGrid(t *testing.T) {
+	database := buildTestDatabase(t, "HIELT")
+
+	p, err := ProcessImage(test.RenderText([]string{"HI  EL", "  TH"}, 2), database, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("ProcessImage failed: %v", err)
+	}
+	if got := p.GetPlainText(); strings.Contains(got, "  ") {
+		t.Fatalf("word based text %q already keeps the column gap", got)
+	}
+
+	if err := p.DetectMonospaceGrid(); err != nil {
+		t.Fatalf("DetectMonospaceGrid failed: %v", err)
+	}
+	// basicfont.Face7x13 advances 7 pixels per glyph, rendered at scale 2
+	if math.Abs(p.Grid.CellWidth-14) > 1 {
+		t.Errorf("cell width is %.2f, want 14", p.Grid.CellWidth)
+	}
+	for _, char := range p.Chars {
+		if char.Cell == nil {
+			t.Errorf("character at %d,%d was not snapped to the grid", char.X, char.Y)
+		}
+	}
+
+	if got, want := p.GetPlainText(), "HI  EL\n  TH"; got != want {
+		t.Errorf("grid text is %q, want %q", got, want)
+	}
+
+	if err := NewPage(image.NewGray(image.Rect(0, 0, 10, 10)), nil).DetectMonospaceGrid(); err == nil {
+		t.Errorf("expected error for a page without characters")
+	}
+}
+
+func TestWordCharSpacings(t *testing.T) {
+	word := &Word{Chars: []*CharacterBounds{
+		{X: 20, Width: 6},
+		{X: 10, Width: 7},
+	}}
+	if got := word.CharSpacings(); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("CharSpacings() = %v, want [3]", got)
+	}
+
+	word.Chars = append(word.Chars, &CharacterBounds{X: 24, Width: 5})
+	if got := word.CharSpacings(); !reflect.DeepEqual(got, []int{3, -2}) {
+		t.Errorf("CharSpacings() with an overlapping box = %v, want [3 -2]", got)
+	}
+
+	if got := (&Word{Chars: []*CharacterBounds{{X: 4, Width: 5}}}).CharSpacings(); got != nil {
+		t.Errorf("CharSpacings() of a single character = %v, want nil", got)
+	}
+}
@@ -0,0 +1,151 @@
+package page
+
+import "math"
+
+// Denoise clears ink pixels without any 8-connected ink neighbour and returns how many were removed
+func (b *BinaryImage) Denoise() int {
+	var isolated [][2]int
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			if !b.Get(x, y) {
+				continue
+			}
+
+			alone := true
+			for dy := -1; dy <= 1 && alone; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && b.Get(x+dx, y+dy) {
+						alone = false
+						break
+					}
+				}
+			}
+			if alone {
+				isolated = append(isolated, [2]int{x, y})
+			}
+		}
+	}
+
+	for _, p := range isolated {
+		b.Set(p[0], p[1], false)
+	}
+
+	return len(isolated)
+}
+
+// borderInkFraction is the share of ink above which an edge row or column is taken as border rather than text
+const borderInkFraction = 0.5
+
+// RemoveBorders clears rows and columns working inward from each edge while they are mostly ink, such as the black
+// frame or shadow of a scanner, and returns how many pixels were cleared
+func (b *BinaryImage) RemoveBorders() int {
+	cleared := 0
+	top, bottom := 0, b.Height-1
+	left, right := 0, b.Width-1
+
+	clearRow := func(y int) {
+		cleared += b.RowCount(y)
+		for x := 0; x < b.Width; x++ {
+			b.Set(x, y, false)
+		}
+	}
+	clearColumn := func(x int) {
+		cleared += b.ColumnCount(x)
+		for y := 0; y < b.Height; y++ {
+			b.Set(x, y, false)
+		}
+	}
+
+	// Clearing one side lowers the ink of the rows or columns crossing it, so repeat until every side settles
+	for changed := true; changed && top <= bottom && left <= right; {
+		changed = false
+		for ; top <= bottom && float64(b.RowCount(top)) > borderInkFraction*float64(right-left+1); top++ {
+			clearRow(top)
+			changed = true
+		}
+		for ; bottom >= top && float64(b.RowCount(bottom)) > borderInkFraction*float64(right-left+1); bottom-- {
+			clearRow(bottom)
+			changed = true
+		}
+		for ; left <= right && float64(b.ColumnCount(left)) > borderInkFraction*float64(bottom-top+1); left++ {
+			clearColumn(left)
+			changed = true
+		}
+		for ; right >= left && float64(b.ColumnCount(right)) > borderInkFraction*float64(bottom-top+1); right-- {
+			clearColumn(right)
+			changed = true
+		}
+	}
+
+	return cleared
+}
+
+// EstimateSkew returns the angle in degrees within ±maxAngle that gives the sharpest horizontal projection profile
+func (b *BinaryImage) EstimateSkew(maxAngle, step float64) float64 {
+	if step <= 0 {
+		return 0
+	}
+
+	var points [][2]float64
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			if b.Get(x, y) {
+				points = append(points, [2]float64{float64(x), float64(y)})
+			}
+		}
+	}
+	if len(points) == 0 {
+		return 0
+	}
+
+	diagonal := int(math.Hypot(float64(b.Width), float64(b.Height))) + 1
+	profile := make([]int, 2*diagonal+1)
+
+	bestAngle := 0.0
+	bestScore := -1.0
+	for angle := -maxAngle; angle <= maxAngle+step/2; angle += step {
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for i := range profile {
+			profile[i] = 0
+		}
+		for _, p := range points {
+			row := int(math.Round(p[1]*cos-p[0]*sin)) + diagonal
+			profile[row]++
+		}
+
+		// Text lines aligned with the rows concentrate ink, so the sum of squares peaks
+		score := 0.0
+		for _, count := range profile {
+			score += float64(count) * float64(count)
+		}
+		if score > bestScore || (score == bestScore && math.Abs(angle) < math.Abs(bestAngle)) {
+			bestScore = score
+			bestAngle = angle
+		}
+	}
+
+	return bestAngle
+}
+
+// Rotate returns a new image of the same size rotated by angle degrees around its centre
+func (b *BinaryImage) Rotate(angle float64) *BinaryImage {
+	rotated := newBinaryImage(b.Width, b.Height)
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	cx := float64(b.Width) / 2
+	cy := float64(b.Height) / 2
+
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			// Inverse mapping keeps the output free of holes
+			dx := float64(x) - cx
+			dy := float64(y) - cy
+			sx := int(math.Round(dx*cos + dy*sin + cx))
+			sy := int(math.Round(-dx*sin + dy*cos + cy))
+			if b.Get(sx, sy) {
+				rotated.Set(x, y, true)
+			}
+		}
+	}
+
+	return rotated
+}
@@ -0,0 +1,186 @@
+package page
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// Filter transforms a page image before detection
+type Filter func(img image.Image) image.Image
+
+// Preprocessor runs a chain of filters over every scan it is given
+type Preprocessor struct {
+	Filters []Filter
+}
+
+func NewPreprocessor(filters ...Filter) *Preprocessor {
+	return &Preprocessor{Filters: filters}
+}
+
+// Apply runs the filters in order, a nil preprocessor returns img unchanged
+func (pre *Preprocessor) Apply(img image.Image) image.Image {
+	if pre == nil {
+		return img
+	}
+	for _, filter := range pre.Filters {
+		img = filter(img)
+	}
+	return img
+}
+
+// GrayscaleFilter converts the image to 8-bit gray with its origin at (0, 0)
+func GrayscaleFilter() Filter {
+	return func(img image.Image) image.Image {
+		return toGray(img)
+	}
+}
+
+// ThresholdFilter turns pixels darker than level black and every other pixel white
+func ThresholdFilter(level uint8) Filter {
+	return func(img image.Image) image.Image {
+		gray := toGray(img)
+		out := image.NewGray(gray.Rect)
+		width := gray.Rect.Dx()
+		for y := 0; y < gray.Rect.Dy(); y++ {
+			for x, v := range gray.Pix[y*gray.Stride : y*gray.Stride+width] {
+				if v >= level {
+					out.Pix[y*out.Stride+x] = 255
+				}
+			}
+		}
+		return out
+	}
+}
+
+// OtsuFilter thresholds at the level that best separates the gray histogram into ink and background
+func OtsuFilter() Filter {
+	return func(img image.Image) image.Image {
+		gray := toGray(img)
+		return ThresholdFilter(otsuLevel(gray))(gray)
+	}
+}
+
+// AdaptiveThresholdFilter marks a pixel as ink when it is more than offset darker than the mean of the window×window
+// block around it, which copes with uneven lighting a single level cannot
+func AdaptiveThresholdFilter(window int, offset uint8) Filter {
+	return func(img image.Image) image.Image {
+		gray := toGray(img)
+		width, height := gray.Rect.Dx(), gray.Rect.Dy()
+		radius := max(window/2, 1)
+
+		// Summed area table with a zero row and column in front
+		sums := make([]int, (width+1)*(height+1))
+		for y := 0; y < height; y++ {
+			row := 0
+			for x := 0; x < width; x++ {
+				row += int(gray.Pix[y*gray.Stride+x])
+				sums[(y+1)*(width+1)+x+1] = sums[y*(width+1)+x+1] + row
+			}
+		}
+
+		out := image.NewGray(gray.Rect)
+		for y := 0; y < height; y++ {
+			y0, y1 := max(0, y-radius), min(height, y+radius+1)
+			for x := 0; x < width; x++ {
+				x0, x1 := max(0, x-radius), min(width, x+radius+1)
+				sum := sums[y1*(width+1)+x1] - sums[y0*(width+1)+x1] - sums[y1*(width+1)+x0] + sums[y0*(width+1)+x0]
+				mean := sum / ((x1 - x0) * (y1 - y0))
+				if int(gray.Pix[y*gray.Stride+x])+int(offset) >= mean {
+					out.Pix[y*out.Stride+x] = 255
+				}
+			}
+		}
+		return out
+	}
+}
+
+// DenoiseFilter binarizes the image at threshold and clears isolated ink pixels, see BinaryImage.Denoise. A threshold
+// of 0 uses character.DefaultForegroundThreshold
+func DenoiseFilter(threshold uint8) Filter {
+	return func(img image.Image) image.Image {
+		binary := NewBinaryImage(img, filterThreshold(threshold))
+		binary.Denoise()
+		return binary.Gray()
+	}
+}
+
+// BorderFilter binarizes the image at threshold and clears black borders along the edges, see BinaryImage.RemoveBorders.
+// A threshold of 0 uses character.DefaultForegroundThreshold
+func BorderFilter(threshold uint8) Filter {
+	return func(img image.Image) image.Image {
+		binary := NewBinaryImage(img, filterThreshold(threshold))
+		binary.RemoveBorders()
+		return binary.Gray()
+	}
+}
+
+// DeskewFilter binarizes the image at threshold and straightens it by the skew found within ±maxAngle degrees. A
+// threshold of 0 uses character.DefaultForegroundThreshold
+func DeskewFilter(maxAngle float64, threshold uint8) Filter {
+	return func(img image.Image) image.Image {
+		binary := NewBinaryImage(img, filterThreshold(threshold))
+		if angle := binary.EstimateSkew(maxAngle, 0.25); angle != 0 {
+			binary = binary.Rotate(-angle)
+		}
+		return binary.Gray()
+	}
+}
+
+func filterThreshold(threshold uint8) uint8 {
+	if threshold == 0 {
+		return character.DefaultForegroundThreshold
+	}
+	return threshold
+}
+
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	if gray, ok := img.(*image.Gray); ok && bounds.Min == (image.Point{}) {
+		return gray
+	}
+
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Rect, img, bounds.Min, draw.Src)
+	return gray
+}
+
+// otsuLevel returns the gray level maximizing the between class variance, pixels below it are ink
+func otsuLevel(gray *image.Gray) uint8 {
+	var histogram [256]int
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	for y := 0; y < height; y++ {
+		for _, v := range gray.Pix[y*gray.Stride : y*gray.Stride+width] {
+			histogram[v]++
+		}
+	}
+
+	total := width * height
+	sumAll := 0.0
+	for level, count := range histogram {
+		sumAll += float64(level * count)
+	}
+
+	best, bestVariance := character.DefaultForegroundThreshold, -1.0
+	weightDark, sumDark := 0, 0.0
+	for level := 0; level < 255; level++ {
+		weightDark += histogram[level]
+		sumDark += float64(level * histogram[level])
+		weightLight := total - weightDark
+		if weightDark == 0 || weightLight == 0 {
+			continue
+		}
+
+		meanDark := sumDark / float64(weightDark)
+		meanLight := (sumAll - sumDark) / float64(weightLight)
+		variance := float64(weightDark) * float64(weightLight) * (meanDark - meanLight) * (meanDark - meanLight)
+		if variance > bestVariance {
+			bestVariance = variance
+			// Levels up to and including this one are dark
+			best = level + 1
+		}
+	}
+
+	return uint8(min(best, 255))
+}
@@ -0,0 +1,205 @@
+package page
+
+import (
+	"fmt"
+	"image"
+	"runtime"
+	"sync"
+
+	"github.com/bsthun/glyphcanvas/package/recognize"
+)
+
+type ProcessOptions struct {
+	Preprocessor *Preprocessor    // Filters run on the image before binarizing, the page then holds the filtered image
+	Config       *DetectionConfig // Nil uses the defaults, or AutoConfigureForDPI when DPI is set
+	DPI          int              // Scan resolution, 0 when unknown
+	Threshold    uint8            // Gray level below which a pixel is ink, 0 uses character.DefaultForegroundThreshold
+	Denoise      bool             // Clear isolated ink pixels before detection
+	Borders      bool             // Clear black borders and scanner shadows along the edges before detection
+	Deskew       bool             // Straighten the binary image before detection, bounds then refer to the deskewed image
+	MaxSkew      float64          // Largest skew in degrees searched by Deskew, 0 means 5
+	Align        bool             // Straighten each slightly tilted glyph before recognition, see recognize.ExtractOptions
+	ContourOnly  bool             // Describe each glyph by its outline, use with a database extracted in contour mode
+	EdgeBox      bool             // Measure each glyph's aspect ratio on the sub-pixel ink edges, skipped when Deskew rotates the page
+	Rotations    []float64        // Also match each glyph turned by these angles in degrees, see recognize.RecognizeOptions
+	Workers      int              // Recognition goroutines, 0 uses GOMAXPROCS
+	Progress     func(stage string, done, total int)
+}
+
+// ProcessImage binarizes img, runs every detection stage and recognizes the characters against database
+func ProcessImage(img image.Image, database *recognize.FeatureDatabase, opts ProcessOptions) (*Page, error) {
+	if img == nil {
+		return nil, fmt.Errorf("image is nil")
+	}
+	if database == nil || len(database.Characters) == 0 {
+		return nil, recognize.ErrEmptyDatabase
+	}
+
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(string, int, int) {}
+	}
+
+	p := NewPage(opts.Preprocessor.Apply(img), opts.Config)
+	if opts.Threshold > 0 {
+		p.Threshold = opts.Threshold
+	}
+	p.ExtractOptions.AlignPrincipalAxis = opts.Align
+	p.ExtractOptions.ContourOnly = opts.ContourOnly
+	p.RecognizeOptions.RotationAngles = opts.Rotations
+	if opts.Config == nil && opts.DPI > 0 {
+		if err := p.AutoConfigureForDPI(opts.DPI); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.Config.Validate(); err != nil {
+		return nil, err
+	}
+
+	progress("binarize", 0, 1)
+	binary := p.binaryImage()
+	if opts.Borders {
+		binary.RemoveBorders()
+	}
+	if opts.Denoise {
+		binary.Denoise()
+	}
+	deskewed := false
+	if opts.Deskew {
+		maxSkew := opts.MaxSkew
+		if maxSkew <= 0 {
+			maxSkew = 5
+		}
+		if angle := binary.EstimateSkew(maxSkew, 0.25); angle != 0 {
+			p.Binary = binary.Rotate(-angle)
+			deskewed = true
+		}
+	}
+	progress("binarize", 1, 1)
+
+	stages := []struct {
+		name   string
+		detect func() error
+	}{
+		{"areas", p.DetectTextAreas},
+		{"lines", p.DetectLines},
+		{"words", p.DetectWords},
+		{"characters", p.DetectCharacters},
+	}
+	for _, stage := range stages {
+		progress(stage.name, 0, 1)
+		if err := stage.detect(); err != nil {
+			return nil, err
+		}
+		progress(stage.name, 1, 1)
+	}
+
+	p.MarkSuspectCharacters()
+	if opts.EdgeBox && !deskewed {
+		p.MeasureEdgeBoxes()
+	}
+	p.RecognizeAll(database, opts.Workers, progress)
+	p.AssembleText()
+
+	return p, nil
+}
+
+// RecognizeAll fills in the best database match for every detected character using a pool of workers, 0 uses GOMAXPROCS
+func (p *Page) RecognizeAll(database *recognize.FeatureDatabase, workers int, progress func(stage string, done, total int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	total := len(p.Chars)
+	if progress != nil {
+		progress("recognize", 0, total)
+	}
+
+	// Each worker writes only to its own CharacterBounds and the database is only read
+	indices := make(chan int)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				recognizeCharacter(p.Chars[i], database, p.ExtractOptions, p.RecognizeOptions)
+
+				if progress != nil {
+					mutex.Lock()
+					done++
+					if done%50 == 0 && done < total {
+						progress("recognize", done, total)
+					}
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range p.Chars {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	if progress != nil {
+		progress("recognize", total, total)
+	}
+}
+
+func recognizeCharacter(char *CharacterBounds, database *recognize.FeatureDatabase, extractOpts recognize.ExtractOptions, opts recognize.RecognizeOptions) {
+	glyph := char.GetCharacter()
+	if glyph == nil {
+		return
+	}
+
+	candidates, err := recognize.RecognizeWithOptions(glyph, database, extractOpts, opts)
+	if err != nil {
+		return
+	}
+
+	if len(candidates) > 0 {
+		best := candidates[0]
+		char.Unicode = best.Unicode
+		char.Text = recognize.UnicodeToString(best.Unicode)
+		char.Confidence = best.Confidence
+		if char.Suspect {
+			char.Confidence *= 0.5
+		}
+	}
+}
+
+// AssembleText builds word and line text and word confidence from the recognized characters
+func (p *Page) AssembleText() {
+	for _, word := range p.Words {
+		wordText := ""
+		totalConfidence := 0.0
+		validChars := 0
+
+		for _, char := range word.Chars {
+			if char.Text != "" {
+				wordText += char.Text
+				totalConfidence += char.Confidence
+				validChars++
+			}
+		}
+
+		word.Text = wordText
+		if validChars > 0 {
+			word.Confidence = totalConfidence / float64(validChars)
+		}
+	}
+
+	for _, line := range p.Lines {
+		lineText := ""
+		for i, word := range line.Words {
+			if i > 0 && word.Text != "" {
+				lineText += " "
+			}
+			lineText += word.Text
+		}
+		line.Text = lineText
+	}
+}
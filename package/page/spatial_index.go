@@ -0,0 +1,112 @@
+package page
+
+import (
+	"image"
+	"sort"
+)
+
+// charIndex buckets character boxes into square grid cells so a box query only visits nearby characters
+type charIndex struct {
+	cellSize int
+	cells    map[image.Point][]int // Indices into the indexed characters of every box overlapping the cell
+	chars    []*CharacterBounds    // Copy of the indexed slice, so reordering the page's slice is noticed
+	rects    []image.Rectangle     // Box of each indexed character when it was indexed
+	bounds   image.Rectangle       // Union of every box, queries are clipped to it
+}
+
+func newCharIndex(chars []*CharacterBounds) *charIndex {
+	// Cells about twice a typical glyph keep most boxes within one or two cells
+	cellSize := 16
+	if len(chars) > 0 {
+		sum := 0
+		for _, char := range chars {
+			sum += max(char.Width, char.Height)
+		}
+		cellSize = max(cellSize, 2*sum/len(chars))
+	}
+
+	index := &charIndex{
+		cellSize: cellSize,
+		cells:    map[image.Point][]int{},
+		chars:    append([]*CharacterBounds(nil), chars...),
+		rects:    make([]image.Rectangle, len(chars)),
+	}
+	for i, char := range chars {
+		index.rects[i] = char.rect()
+		index.bounds = index.bounds.Union(index.rects[i])
+		index.visit(index.rects[i], func(cell image.Point) {
+			index.cells[cell] = append(index.cells[cell], i)
+		})
+	}
+
+	return index
+}
+
+func (index *charIndex) visit(r image.Rectangle, fn func(cell image.Point)) {
+	if r.Empty() {
+		return
+	}
+	for y := floorDiv(r.Min.Y, index.cellSize); y <= floorDiv(r.Max.Y-1, index.cellSize); y++ {
+		for x := floorDiv(r.Min.X, index.cellSize); x <= floorDiv(r.Max.X-1, index.cellSize); x++ {
+			fn(image.Point{X: x, Y: y})
+		}
+	}
+}
+
+// current reports whether the index was built from the same characters in the same order and places, comparing
+// contents is linear but still far cheaper than a rebuild
+func (index *charIndex) current(chars []*CharacterBounds) bool {
+	if len(index.chars) != len(chars) {
+		return false
+	}
+	for i, char := range chars {
+		if index.chars[i] != char || index.rects[i] != char.rect() {
+			return false
+		}
+	}
+	return true
+}
+
+func (index *charIndex) query(r image.Rectangle) []*CharacterBounds {
+	var found []int
+	seen := map[int]bool{}
+	index.visit(r.Intersect(index.bounds), func(cell image.Point) {
+		for _, i := range index.cells[cell] {
+			if !seen[i] && index.rects[i].Overlaps(r) {
+				seen[i] = true
+				found = append(found, i)
+			}
+		}
+	})
+
+	if len(found) == 0 {
+		return nil
+	}
+
+	sort.Ints(found)
+	result := make([]*CharacterBounds, len(found))
+	for i, at := range found {
+		result[i] = index.chars[at]
+	}
+	return result
+}
+
+// QueryBox returns the detected characters whose bounds overlap r, in page order.
+// The index is rebuilt on the first query after p.Chars is changed, reordered or a character is moved.
+func (p *Page) QueryBox(r image.Rectangle) []*CharacterBounds {
+	if p.index == nil || !p.index.current(p.Chars) {
+		p.index = newCharIndex(p.Chars)
+	}
+	return p.index.query(r.Canon())
+}
+
+func (c *CharacterBounds) rect() image.Rectangle {
+	return image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height)
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
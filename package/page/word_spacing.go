@@ -0,0 +1,22 @@
+package page
+
+import "sort"
+
+// CharSpacings returns the horizontal gap in pixels between each pair of neighboring characters, ordered left to
+// right. Overlapping boxes give a negative gap, a word with fewer than two characters has none
+func (w *Word) CharSpacings() []int {
+	if len(w.Chars) < 2 {
+		return nil
+	}
+
+	chars := append([]*CharacterBounds(nil), w.Chars...)
+	sort.SliceStable(chars, func(i, j int) bool {
+		return chars[i].X < chars[j].X
+	})
+
+	spacings := make([]int, 0, len(chars)-1)
+	for i := 1; i < len(chars); i++ {
+		spacings = append(spacings, chars[i].X-(chars[i-1].X+chars[i-1].Width))
+	}
+	return spacings
+}
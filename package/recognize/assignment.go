@@ -0,0 +1,71 @@
+package recognize
+
+import "math"
+
+// hungarianAssignment solves the rectangular assignment problem with Kuhn-Munkres, cost has at most as many rows as
+// columns and every row gets a distinct column minimizing the total cost. It returns the column of each row
+func hungarianAssignment(cost [][]float64) []int {
+	rows := len(cost)
+	if rows == 0 {
+		return nil
+	}
+	columns := len(cost[0])
+
+	// Potentials and the row matched to each column, both 1-based with column 0 as the augmenting path root
+	u := make([]float64, rows+1)
+	v := make([]float64, columns+1)
+	match := make([]int, columns+1)
+	way := make([]int, columns+1)
+
+	for row := 1; row <= rows; row++ {
+		match[0] = row
+		column := 0
+		minReduced := make([]float64, columns+1)
+		for j := range minReduced {
+			minReduced[j] = math.Inf(1)
+		}
+		used := make([]bool, columns+1)
+
+		for match[column] != 0 {
+			used[column] = true
+			current := match[column]
+			delta, next := math.Inf(1), 0
+			for j := 1; j <= columns; j++ {
+				if used[j] {
+					continue
+				}
+				if reduced := cost[current-1][j-1] - u[current] - v[j]; reduced < minReduced[j] {
+					minReduced[j] = reduced
+					way[j] = column
+				}
+				if minReduced[j] < delta {
+					delta, next = minReduced[j], j
+				}
+			}
+			for j := 0; j <= columns; j++ {
+				if used[j] {
+					u[match[j]] += delta
+					v[j] -= delta
+				} else {
+					minReduced[j] -= delta
+				}
+			}
+			column = next
+		}
+
+		// Flip the augmenting path back to the root
+		for column != 0 {
+			previous := way[column]
+			match[column] = match[previous]
+			column = previous
+		}
+	}
+
+	assignment := make([]int, rows)
+	for j := 1; j <= columns; j++ {
+		if match[j] > 0 {
+			assignment[match[j]-1] = j - 1
+		}
+	}
+	return assignment
+}
@@ -0,0 +1,115 @@
+package recognize
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+type FeatureCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	mutex    sync.Mutex
+	hits     int
+	misses   int
+}
+
+type featureCacheEntry struct {
+	key      string
+	features *CharacterFeature
+}
+
+func NewFeatureCache(capacity int) *FeatureCache {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+
+	return &FeatureCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *FeatureCache) Get(key string) (*CharacterFeature, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(element)
+	return copyFeatures(element.Value.(*featureCacheEntry).features), true
+}
+
+func (c *FeatureCache) Put(key string, features *CharacterFeature) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*featureCacheEntry).features = copyFeatures(features)
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&featureCacheEntry{key: key, features: copyFeatures(features)})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*featureCacheEntry).key)
+	}
+}
+
+func (c *FeatureCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+func (c *FeatureCache) Stats() (hits int, misses int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.hits, c.misses
+}
+
+func ExtractFeaturesCached(char *character.Character, cache *FeatureCache) (*CharacterFeature, error) {
+	if cache == nil {
+		return ExtractFeatures(char)
+	}
+	if char == nil {
+		return nil, character.ErrEmptyCharacter
+	}
+
+	// The same pixels analysed under another configuration or with another sub-pixel extent give other features
+	key := char.ContentHash() + ":" + char.Config.Hash()
+	if box := char.EdgeBox; box != nil {
+		key += fmt.Sprintf(":%g,%g,%g,%g", box.MinX, box.MinY, box.MaxX, box.MaxY)
+	}
+	if features, ok := cache.Get(key); ok {
+		return features, nil
+	}
+
+	features, err := ExtractFeatures(char)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Put(key, features)
+	return features, nil
+}
+
+func copyFeatures(features *CharacterFeature) *CharacterFeature {
+	copied := *features
+	copied.Directions = append([]float64(nil), features.Directions...)
+	copied.RegionFeatures = append([]RegionFeatureSet(nil), features.RegionFeatures...)
+	copied.Components = append([]ComponentFeature(nil), features.Components...)
+	return &copied
+}
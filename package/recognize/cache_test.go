@@ -0,0 +1,107 @@
+package recognize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func TestExtractFeaturesCached(t *testing.T) {
+	cache := NewFeatureCache(8)
+
+	first, err := ExtractFeaturesCached(test.CharacterFromImage(test.RenderText([]string{"B"}, 3)), cache)
+	if err != nil {
+		t.Fatalf("ExtractFeaturesCached failed: %v", err)
+	}
+
+	second, err := ExtractFeaturesCached(test.CharacterFromImage(test.RenderText([]string{"B"}, 3)), cache)
+	if err != nil {
+		t.Fatalf("ExtractFeaturesCached failed: %v", err)
+	}
+
+	hits, misses := cache.Stats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("hits = %d, misses = %d, want 1 and 1", hits, misses)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cached features differ from extracted features")
+	}
+
+	// Mutating a returned result must not affect the cached entry
+	second.Unicode = "0042"
+	directions := append([]float64(nil), second.Directions...)
+	for i := range second.Directions {
+		second.Directions[i] = -1
+	}
+	third, _ := ExtractFeaturesCached(test.CharacterFromImage(test.RenderText([]string{"B"}, 3)), cache)
+	if third.Unicode != "" {
+		t.Errorf("cached entry was mutated through a returned result")
+	}
+	if len(directions) == 0 || !reflect.DeepEqual(third.Directions, directions) {
+		t.Errorf("cached directions = %v, want %v", third.Directions, directions)
+	}
+}
+
+func TestExtractFeaturesCachedConfig(t *testing.T) {
+	cache := NewFeatureCache(8)
+
+	if _, err := ExtractFeaturesCached(test.CharacterFromImage(test.RenderText([]string{"B"}, 3)), cache); err != nil {
+		t.Fatalf("ExtractFeaturesCached failed: %v", err)
+	}
+
+	char := test.CharacterFromImage(test.RenderText([]string{"B"}, 3))
+	char.Config.CurvatureThreshold = 0.8
+	if _, err := ExtractFeaturesCached(char, cache); err != nil {
+		t.Fatalf("ExtractFeaturesCached failed: %v", err)
+	}
+
+	if hits, misses := cache.Stats(); hits != 0 || misses != 2 {
+		t.Errorf("hits = %d, misses = %d, want a miss for the changed config", hits, misses)
+	}
+
+	char = test.CharacterFromImage(test.RenderText([]string{"B"}, 3))
+	char.EdgeBox = &character.EdgeBox{MinX: 1.5, MinY: 2.25, MaxX: 20.5, MaxY: 30.75}
+	if _, err := ExtractFeaturesCached(char, cache); err != nil {
+		t.Fatalf("ExtractFeaturesCached failed: %v", err)
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 3 {
+		t.Errorf("hits = %d, misses = %d, want a miss for the sub-pixel extent", hits, misses)
+	}
+	if cache.Len() != 3 {
+		t.Errorf("len = %d, want 3", cache.Len())
+	}
+}
+
+func TestExtractFeaturesCachedNil(t *testing.T) {
+	cache := NewFeatureCache(8)
+
+	_, err := ExtractFeaturesCached(nil, cache)
+	if !errors.Is(err, character.ErrEmptyCharacter) {
+		t.Errorf("err = %v, want ErrEmptyCharacter", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("len = %d, want nothing cached for a nil character", cache.Len())
+	}
+}
+
+func TestFeatureCacheEviction(t *testing.T) {
+	cache := NewFeatureCache(2)
+	cache.Put("a", &CharacterFeature{Unicode: "a"})
+	cache.Put("b", &CharacterFeature{Unicode: "b"})
+	cache.Get("a")
+	cache.Put("c", &CharacterFeature{Unicode: "c"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("expected recently used entry to be kept")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("len = %d, want 2", cache.Len())
+	}
+}
@@ -0,0 +1,94 @@
+package recognize
+
+import (
+	"sort"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// DefaultClusterMinPoints is the DBSCAN minPts ClusterCharacters uses, a glyph and two neighbors make a core so a
+// single stray glyph between two shapes cannot chain them into one cluster
+const DefaultClusterMinPoints = 3
+
+type ClusterOptions struct {
+	Eps       float64 // Largest feature distance at which two glyphs are neighbors
+	MinPoints int     // Glyphs within Eps, itself included, that make a glyph a core, 0 uses DefaultClusterMinPoints
+}
+
+// ClusterCharacters groups chars whose feature distance is within eps with DBSCAN, see ClusterCharactersWithOptions
+func ClusterCharacters(chars []*character.Character, eps float64) [][]int {
+	return ClusterCharactersWithOptions(chars, ClusterOptions{Eps: eps})
+}
+
+// ClusterCharactersWithOptions runs DBSCAN over the feature distance. Clusters grow through core glyphs, a glyph that
+// is neither a core nor next to one forms a cluster of its own so it can still be labeled, empty glyphs are left out.
+// Each cluster lists indices into chars in ascending order, clusters are ordered by their first index.
+func ClusterCharactersWithOptions(chars []*character.Character, opts ClusterOptions) [][]int {
+	minPoints := opts.MinPoints
+	if minPoints <= 0 {
+		minPoints = DefaultClusterMinPoints
+	}
+
+	features := make([]*CharacterFeature, len(chars))
+	for i, char := range chars {
+		if char == nil || char.IsEmpty() {
+			continue
+		}
+		if extracted, err := ExtractFeatures(char); err == nil {
+			features[i] = extracted
+		}
+	}
+
+	neighbors := make([][]int, len(chars))
+	for i := range features {
+		if features[i] == nil {
+			continue
+		}
+		for j := i + 1; j < len(features); j++ {
+			if features[j] != nil && computeFeatureDistance(features[i], features[j]) <= opts.Eps {
+				neighbors[i] = append(neighbors[i], j)
+				neighbors[j] = append(neighbors[j], i)
+			}
+		}
+	}
+
+	var clusters [][]int
+	assigned := make([]bool, len(chars))
+	for i := range chars {
+		if assigned[i] || features[i] == nil || len(neighbors[i])+1 < minPoints {
+			continue
+		}
+
+		assigned[i] = true
+		members := []int{i}
+		for queue := []int{i}; len(queue) > 0; queue = queue[1:] {
+			for _, j := range neighbors[queue[0]] {
+				if assigned[j] {
+					continue
+				}
+				assigned[j] = true
+				members = append(members, j)
+				// Border glyphs join the cluster but do not extend it
+				if len(neighbors[j])+1 >= minPoints {
+					queue = append(queue, j)
+				}
+			}
+		}
+
+		sort.Ints(members)
+		clusters = append(clusters, members)
+	}
+
+	// Glyphs no core reached are noise, each is a cluster of its own
+	for i := range chars {
+		if !assigned[i] && features[i] != nil {
+			clusters = append(clusters, []int{i})
+		}
+	}
+
+	sort.Slice(clusters, func(a, b int) bool {
+		return clusters[a][0] < clusters[b][0]
+	})
+
+	return clusters
+}
@@ -0,0 +1,37 @@
+package recognize
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func TestClusterCharacters(t *testing.T) {
+	var chars []*character.Character
+	for _, scale := range []int{3, 4, 5} {
+		chars = append(chars,
+			test.CharacterFromImage(test.RenderText([]string{"A"}, scale)),
+			test.CharacterFromImage(test.RenderText([]string{"O"}, scale)),
+		)
+	}
+	chars = append(chars, character.NewCharacter(8, 8, nil))
+
+	clusters := ClusterCharacters(chars, 0.1)
+	want := [][]int{{0, 2, 4}, {1, 3, 5}}
+	if !reflect.DeepEqual(clusters, want) {
+		t.Errorf("clusters = %v, want %v", clusters, want)
+	}
+
+	singles := ClusterCharacters(chars[:2], 0)
+	if !reflect.DeepEqual(singles, [][]int{{0}, {1}}) {
+		t.Errorf("clusters with eps 0 = %v, want each glyph alone", singles)
+	}
+
+	// Three glyphs per shape are not enough for a core of four, so every glyph is noise
+	noise := ClusterCharactersWithOptions(chars, ClusterOptions{Eps: 0.1, MinPoints: 4})
+	if !reflect.DeepEqual(noise, [][]int{{0}, {1}, {2}, {3}, {4}, {5}}) {
+		t.Errorf("clusters with 4 min points = %v, want each glyph alone", noise)
+	}
+}
@@ -0,0 +1,302 @@
+package recognize
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const gridEncodingHex = "hex"
+
+type SaveOptions struct {
+	PackGridSignature bool // Store grid signatures as "<bits>:<hex>" instead of one character per bit
+}
+
+func SaveDatabase(database *FeatureDatabase, path string) error {
+	return SaveDatabaseWithOptions(database, path, SaveOptions{})
+}
+
+// SaveDatabaseWithOptions writes database as YAML, gzip compressed when path ends in .gz
+func SaveDatabaseWithOptions(database *FeatureDatabase, path string, opts SaveOptions) error {
+	versioned := *database
+	versioned.Version = DatabaseVersion
+	versioned.GridEncoding = ""
+
+	if opts.PackGridSignature {
+		versioned.GridEncoding = gridEncodingHex
+		versioned.Characters = make(map[string]*CharacterFeature, len(database.Characters))
+		for unicode, features := range database.Characters {
+			packed := *features
+			packed.GridSignature = packGridSignature(features.GridSignature)
+			versioned.Characters[unicode] = &packed
+		}
+	}
+
+	data, err := yaml.Marshal(&versioned)
+	if err != nil {
+		return fmt.Errorf("failed to encode database: %w", err)
+	}
+
+	return writeDatabaseFile(path, data)
+}
+
+// LoadDatabase reads a database file, files without a version predate versioning and load as version 0.
+// Paths ending in .gob (optionally followed by .gz) are read with LoadDatabaseGob
+func LoadDatabase(path string) (*FeatureDatabase, error) {
+	if strings.HasSuffix(strings.TrimSuffix(path, ".gz"), ".gob") {
+		return LoadDatabaseGob(path)
+	}
+
+	data, err := readDatabaseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var database FeatureDatabase
+	err = yaml.Unmarshal(data, &database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode database %s: %w", path, err)
+	}
+
+	if err := checkDatabase(path, &database); err != nil {
+		return nil, err
+	}
+	return &database, nil
+}
+
+// SaveDatabaseGob writes database with encoding/gob, much faster to load than YAML but not human editable
+func SaveDatabaseGob(database *FeatureDatabase, path string) error {
+	versioned := *database
+	versioned.Version = DatabaseVersion
+	versioned.GridEncoding = ""
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(&versioned); err != nil {
+		return fmt.Errorf("failed to encode database: %w", err)
+	}
+
+	return writeDatabaseFile(path, buffer.Bytes())
+}
+
+func LoadDatabaseGob(path string) (*FeatureDatabase, error) {
+	data, err := readDatabaseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var database FeatureDatabase
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&database); err != nil {
+		return nil, fmt.Errorf("failed to decode database %s: %w", path, err)
+	}
+
+	if err := checkDatabase(path, &database); err != nil {
+		return nil, err
+	}
+	return &database, nil
+}
+
+// checkDatabase rejects newer versions and unpacks grid signatures stored in a packed encoding
+func checkDatabase(path string, database *FeatureDatabase) error {
+	if database.Version > DatabaseVersion {
+		return fmt.Errorf("%s has version %d, newest supported is %d: %w", path, database.Version, DatabaseVersion, ErrDatabaseVersion)
+	}
+	if database.Version < MinDatabaseVersion {
+		return fmt.Errorf("%s has version %d, features changed in version %d, extract it again: %w", path, database.Version, MinDatabaseVersion, ErrDatabaseVersion)
+	}
+
+	if err := canonicalizeKeys(path, database); err != nil {
+		return err
+	}
+
+	switch database.GridEncoding {
+	case "":
+	case gridEncodingHex:
+		for unicode, features := range database.Characters {
+			signature, err := unpackGridSignature(features.GridSignature)
+			if err != nil {
+				return fmt.Errorf("%s: grid signature of %s: %w", path, unicode, err)
+			}
+			features.GridSignature = signature
+		}
+		database.GridEncoding = ""
+	default:
+		return fmt.Errorf("%s has grid encoding %q: %w", path, database.GridEncoding, ErrDatabaseVersion)
+	}
+
+	return nil
+}
+
+// canonicalizeKeys rewrites the keys of a hand edited or older database the way UnicodeKey writes them, keys that do not
+// parse are left for Validate to report
+func canonicalizeKeys(path string, database *FeatureDatabase) error {
+	for key, features := range database.Characters {
+		canonical, err := CanonicalUnicodeKey(key)
+		if err != nil || canonical == key {
+			continue
+		}
+		if _, exists := database.Characters[canonical]; exists {
+			return fmt.Errorf("%s: keys %s and %s name the same characters: %w", path, key, canonical, ErrInvalidFeature)
+		}
+		delete(database.Characters, key)
+		database.Characters[canonical] = features
+		if features != nil && features.Unicode == key {
+			features.Unicode = canonical
+		}
+	}
+	return nil
+}
+
+// readDatabaseFile reads path, decompressing it when the name ends in .gz
+func readDatabaseFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database %s: %w", path, err)
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress database %s: %w", path, err)
+		}
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress database %s: %w", path, err)
+		}
+	}
+
+	return data, nil
+}
+
+// writeDatabaseFile writes data to path, compressing it when the name ends in .gz
+func writeDatabaseFile(path string, data []byte) error {
+	if strings.HasSuffix(path, ".gz") {
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		if _, err := writer.Write(data); err != nil {
+			return fmt.Errorf("failed to compress database: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("failed to compress database: %w", err)
+		}
+		data = buffer.Bytes()
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write database %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every entry with a Unicode key ParseUnicodeKey rejects, a grid signature whose length differs from the rest of
+// the database, or a NaN or infinite Hu moment. Each error wraps ErrInvalidFeature
+func (db *FeatureDatabase) Validate() []error {
+	keys := make([]string, 0, len(db.Characters))
+	lengths := make(map[int]int)
+	for key, features := range db.Characters {
+		keys = append(keys, key)
+		if features != nil {
+			lengths[len(features.GridSignature)]++
+		}
+	}
+	sort.Strings(keys)
+
+	// The most common signature length is taken as the one the database was extracted with
+	gridLength := 0
+	for length, count := range lengths {
+		if count > lengths[gridLength] || (count == lengths[gridLength] && length > gridLength) {
+			gridLength = length
+		}
+	}
+
+	var problems []error
+	for _, key := range keys {
+		features := db.Characters[key]
+		if features == nil {
+			problems = append(problems, fmt.Errorf("%s: missing features: %w", key, ErrInvalidFeature))
+			continue
+		}
+
+		if canonical, err := CanonicalUnicodeKey(key); err != nil {
+			problems = append(problems, fmt.Errorf("%s: key is not a hex codepoint sequence: %w", key, ErrInvalidFeature))
+		} else if canonical != key {
+			problems = append(problems, fmt.Errorf("%s: key is not canonical, want %s: %w", key, canonical, ErrInvalidFeature))
+		}
+		if features.Unicode != "" && features.Unicode != key {
+			problems = append(problems, fmt.Errorf("%s: unicode field is %q: %w", key, features.Unicode, ErrInvalidFeature))
+		}
+
+		if len(features.GridSignature) == 0 {
+			problems = append(problems, fmt.Errorf("%s: empty grid signature: %w", key, ErrInvalidFeature))
+		} else if len(features.GridSignature) != gridLength {
+			problems = append(problems, fmt.Errorf("%s: grid signature has %d bits, want %d: %w", key, len(features.GridSignature), gridLength, ErrInvalidFeature))
+		} else if strings.Trim(features.GridSignature, "01") != "" {
+			problems = append(problems, fmt.Errorf("%s: grid signature is not a bit string: %w", key, ErrInvalidFeature))
+		}
+
+		for i, moment := range features.HuMoments {
+			if math.IsNaN(moment) || math.IsInf(moment, 0) {
+				problems = append(problems, fmt.Errorf("%s: hu moment %d is %v: %w", key, i+1, moment, ErrInvalidFeature))
+			}
+		}
+	}
+
+	return problems
+}
+
+// packGridSignature packs a '0'/'1' bit string four bits per hex digit, prefixed with the bit count
+func packGridSignature(bits string) string {
+	if bits == "" {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(strconv.Itoa(len(bits)))
+	builder.WriteByte(':')
+	for i := 0; i < len(bits); i += 4 {
+		nibble := 0
+		for j := i; j < i+4; j++ {
+			nibble <<= 1
+			if j < len(bits) && bits[j] == '1' {
+				nibble |= 1
+			}
+		}
+		builder.WriteString(strconv.FormatInt(int64(nibble), 16))
+	}
+	return builder.String()
+}
+
+func unpackGridSignature(packed string) (string, error) {
+	if packed == "" {
+		return "", nil
+	}
+
+	count, digits, found := strings.Cut(packed, ":")
+	if !found {
+		return "", fmt.Errorf("missing bit count in %q", packed)
+	}
+	length, err := strconv.Atoi(count)
+	if err != nil || length < 0 || (length+3)/4 != len(digits) {
+		return "", fmt.Errorf("invalid packed signature %q", packed)
+	}
+
+	bits := make([]byte, 0, len(digits)*4)
+	for _, digit := range digits {
+		nibble, err := strconv.ParseUint(string(digit), 16, 4)
+		if err != nil {
+			return "", fmt.Errorf("invalid packed signature %q", packed)
+		}
+		for shift := 3; shift >= 0; shift-- {
+			bits = append(bits, '0'+byte(nibble>>shift&1))
+		}
+	}
+	return string(bits[:length]), nil
+}
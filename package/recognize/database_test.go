@@ -0,0 +1,294 @@
+package recognize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func TestDatabaseDropsChainCodeHash(t *testing.T) {
+	dir := t.TempDir()
+
+	// A leftover field in an otherwise current database must not stop it loading
+	legacy := filepath.Join(dir, "legacy.yml")
+	err := os.WriteFile(legacy, []byte(fmt.Sprintf(`version: %d
+characters:
+  "0041":
+    unicode: "0041"
+    region_features:
+      - arc_type: line
+        linearity: 0.9
+        chain_code_hash: 1a2b3c4d
+`, DatabaseVersion)), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	database, err := LoadDatabase(legacy)
+	if err != nil {
+		t.Fatalf("LoadDatabase failed on legacy file: %v", err)
+	}
+	features := database.Characters["0041"]
+	if features == nil || len(features.RegionFeatures) != 1 || features.RegionFeatures[0].Linearity != 0.9 {
+		t.Fatalf("legacy database loaded incorrectly: %+v", features)
+	}
+
+	saved := filepath.Join(dir, "saved.yml")
+	if err := SaveDatabase(database, saved); err != nil {
+		t.Fatalf("SaveDatabase failed: %v", err)
+	}
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "chain_code_hash") {
+		t.Errorf("saved database still contains chain_code_hash")
+	}
+}
+
+func TestLoadDatabaseVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	saved := filepath.Join(dir, "saved.yml")
+	if err := SaveDatabase(&FeatureDatabase{Characters: map[string]*CharacterFeature{}}, saved); err != nil {
+		t.Fatalf("SaveDatabase failed: %v", err)
+	}
+	database, err := LoadDatabase(saved)
+	if err != nil {
+		t.Fatalf("LoadDatabase failed: %v", err)
+	}
+	if database.Version != DatabaseVersion {
+		t.Errorf("saved database has version %d, want %d", database.Version, DatabaseVersion)
+	}
+
+	future := filepath.Join(dir, "future.yml")
+	if err := os.WriteFile(future, []byte(fmt.Sprintf("version: %d\ncharacters: {}\n", DatabaseVersion+1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDatabase(future); !errors.Is(err, ErrDatabaseVersion) {
+		t.Errorf("expected ErrDatabaseVersion, got %v", err)
+	}
+
+	for _, version := range []int{0, MinDatabaseVersion - 1} {
+		stale := filepath.Join(dir, fmt.Sprintf("stale%d.yml", version))
+		if err := os.WriteFile(stale, []byte(fmt.Sprintf("version: %d\ncharacters: {}\n", version)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadDatabase(stale); !errors.Is(err, ErrDatabaseVersion) {
+			t.Errorf("version %d: expected ErrDatabaseVersion, got %v", version, err)
+		}
+	}
+
+	if _, err := LoadDatabase(filepath.Join(dir, "missing.yml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a wrapped os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestDatabaseGzipRoundTrip(t *testing.T) {
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{}}
+	for _, letter := range []string{"A", "B", "O"} {
+		features, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{letter}, 3)))
+		if err != nil {
+			t.Fatalf("ExtractFeatures failed: %v", err)
+		}
+		features.Unicode = fmt.Sprintf("%04X", letter[0])
+		database.Characters[features.Unicode] = features
+	}
+	original := database.Characters["0041"].GridSignature
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "char.yml")
+	compressed := filepath.Join(dir, "char.yml.gz")
+	if err := SaveDatabase(database, plain); err != nil {
+		t.Fatalf("SaveDatabase failed: %v", err)
+	}
+	if err := SaveDatabaseWithOptions(database, compressed, SaveOptions{PackGridSignature: true}); err != nil {
+		t.Fatalf("SaveDatabaseWithOptions failed: %v", err)
+	}
+	if database.Characters["0041"].GridSignature != original {
+		t.Fatalf("saving with packed signatures modified the database")
+	}
+
+	plainInfo, err := os.Stat(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressedData, err := os.ReadFile(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressedData) < 2 || compressedData[0] != 0x1f || compressedData[1] != 0x8b {
+		t.Fatalf("expected a gzip file")
+	}
+	if int64(len(compressedData)) >= plainInfo.Size()/2 {
+		t.Errorf("compressed database is %d bytes, plain is %d", len(compressedData), plainInfo.Size())
+	}
+
+	loaded, err := LoadDatabase(compressed)
+	if err != nil {
+		t.Fatalf("LoadDatabase failed: %v", err)
+	}
+	if loaded.GridEncoding != "" {
+		t.Errorf("loaded database still reports grid encoding %q", loaded.GridEncoding)
+	}
+	loaded.Version = 0
+	if !reflect.DeepEqual(loaded, database) {
+		t.Errorf("database changed after a packed gzip round trip")
+	}
+}
+
+func TestGridSignaturePacking(t *testing.T) {
+	for _, bits := range []string{"", "1", "0110", "10110", strings.Repeat("1001", 16)} {
+		packed := packGridSignature(bits)
+		unpacked, err := unpackGridSignature(packed)
+		if err != nil || unpacked != bits {
+			t.Errorf("%q packed to %q and back to %q, %v", bits, packed, unpacked, err)
+		}
+	}
+
+	for _, packed := range []string{"ff", "8:f", "4:g", "x:f"} {
+		if _, err := unpackGridSignature(packed); err == nil {
+			t.Errorf("expected an error unpacking %q", packed)
+		}
+	}
+}
+
+func TestDatabaseGobRoundTrip(t *testing.T) {
+	database := buildLargeDatabase(t, 20)
+
+	dir := t.TempDir()
+	for _, name := range []string{"char.gob", "char.gob.gz"} {
+		path := filepath.Join(dir, name)
+		if err := SaveDatabaseGob(database, path); err != nil {
+			t.Fatalf("SaveDatabaseGob(%s) failed: %v", name, err)
+		}
+
+		// LoadDatabase picks the gob decoder from the extension
+		loaded, err := LoadDatabase(path)
+		if err != nil {
+			t.Fatalf("LoadDatabase(%s) failed: %v", name, err)
+		}
+		if loaded.Version != DatabaseVersion {
+			t.Errorf("%s loaded with version %d, want %d", name, loaded.Version, DatabaseVersion)
+		}
+		loaded.Version = 0
+		if !reflect.DeepEqual(loaded, database) {
+			t.Errorf("database changed after a %s round trip", name)
+		}
+	}
+}
+
+func TestDatabaseValidate(t *testing.T) {
+	database := buildLargeDatabase(t, 8)
+	if problems := database.Validate(); len(problems) != 0 {
+		t.Fatalf("expected a freshly extracted database to be valid, got %v", problems)
+	}
+
+	nan := *database.Characters["4E00"]
+	nan.HuMoments[2] = math.NaN()
+	database.Characters["4E00"] = &nan
+
+	problems := database.Validate()
+	if len(problems) != 1 || !errors.Is(problems[0], ErrInvalidFeature) || !strings.Contains(problems[0].Error(), "4E00") {
+		t.Fatalf("expected one invalid feature error for 4E00, got %v", problems)
+	}
+
+	truncated := *database.Characters["4E01"]
+	truncated.GridSignature = truncated.GridSignature[:10]
+	database.Characters["4E01"] = &truncated
+	badKey := *database.Characters["4E02"]
+	badKey.Unicode = "not-hex"
+	database.Characters["not-hex"] = &badKey
+
+	if problems := database.Validate(); len(problems) != 3 {
+		t.Errorf("expected NaN, truncated signature and key errors, got %v", problems)
+	}
+}
+
+func TestDatabaseCanonicalKeys(t *testing.T) {
+	database := buildLargeDatabase(t, 2)
+	short := *database.Characters["4E00"]
+	short.Unicode = "41"
+	database.Characters["41"] = &short
+	problems := database.Validate()
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "want 0041") {
+		t.Fatalf("expected the short key reported with its canonical form, got %v", problems)
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.yml")
+	if err := SaveDatabase(database, path); err != nil {
+		t.Fatalf("SaveDatabase failed: %v", err)
+	}
+	loaded, err := LoadDatabase(path)
+	if err != nil {
+		t.Fatalf("LoadDatabase failed: %v", err)
+	}
+	if features, ok := loaded.Characters["0041"]; !ok || features.Unicode != "0041" {
+		t.Errorf("expected the entry loaded under 0041, got keys %v", loaded.Characters)
+	}
+	if _, ok := loaded.Characters["41"]; ok {
+		t.Errorf("expected the short key replaced by its canonical form")
+	}
+
+	database.Characters["0041"] = &short
+	if err := SaveDatabase(database, path); err != nil {
+		t.Fatalf("SaveDatabase failed: %v", err)
+	}
+	if _, err := LoadDatabase(path); !errors.Is(err, ErrInvalidFeature) {
+		t.Errorf("expected two keys for one character to be rejected, got %v", err)
+	}
+}
+
+func BenchmarkLoadDatabase(b *testing.B) {
+	database := buildLargeDatabase(b, 500)
+
+	dir := b.TempDir()
+	yamlPath := filepath.Join(dir, "char.yml")
+	gobPath := filepath.Join(dir, "char.gob")
+	if err := SaveDatabase(database, yamlPath); err != nil {
+		b.Fatal(err)
+	}
+	if err := SaveDatabaseGob(database, gobPath); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, path := range []string{yamlPath, gobPath} {
+		b.Run(filepath.Ext(path)[1:], func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := LoadDatabase(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// buildLargeDatabase fills a database with count entries cycling through the features of a few rendered glyphs
+func buildLargeDatabase(tb testing.TB, count int) *FeatureDatabase {
+	tb.Helper()
+
+	var glyphs []*CharacterFeature
+	for _, letter := range []string{"A", "B", "O", "x"} {
+		features, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{letter}, 3)))
+		if err != nil {
+			tb.Fatalf("ExtractFeatures failed: %v", err)
+		}
+		glyphs = append(glyphs, features)
+	}
+
+	database := &FeatureDatabase{Characters: make(map[string]*CharacterFeature, count)}
+	for i := 0; i < count; i++ {
+		features := *glyphs[i%len(glyphs)]
+		features.Unicode = fmt.Sprintf("%04X", 0x4E00+i)
+		database.Characters[features.Unicode] = &features
+	}
+	return database
+}
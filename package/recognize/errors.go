@@ -0,0 +1,9 @@
+package recognize
+
+import "errors"
+
+var (
+	ErrEmptyDatabase   = errors.New("feature database is empty")
+	ErrDatabaseVersion = errors.New("unsupported feature database version")
+	ErrInvalidFeature  = errors.New("invalid feature entry")
+)
@@ -0,0 +1,103 @@
+package recognize
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+// ConfusedPair counts how often glyphs of True were recognized as Predicted
+type ConfusedPair struct {
+	True      string
+	Predicted string
+	Count     int
+}
+
+// BuildConfusionMatrix recognizes every glyph of testSet, keyed by its true unicode, and counts matrix[true][predicted].
+// A glyph that yields no candidate is counted under an empty predicted unicode.
+func BuildConfusionMatrix(db *FeatureDatabase, testSet map[string]*character.Character) map[string]map[string]int {
+	matrix := make(map[string]map[string]int, len(testSet))
+	for unicode, char := range testSet {
+		predicted := ""
+		if candidates, err := Recognize(char, db); err == nil && len(candidates) > 0 {
+			predicted = candidates[0].Unicode
+		}
+
+		if matrix[unicode] == nil {
+			matrix[unicode] = map[string]int{}
+		}
+		matrix[unicode][predicted]++
+	}
+
+	return matrix
+}
+
+// MostConfusedPairs returns up to limit misrecognized pairs, most frequent first, 0 returns all of them
+func MostConfusedPairs(matrix map[string]map[string]int, limit int) []ConfusedPair {
+	var pairs []ConfusedPair
+	for truth, row := range matrix {
+		for predicted, count := range row {
+			if predicted != truth && count > 0 {
+				pairs = append(pairs, ConfusedPair{True: truth, Predicted: predicted, Count: count})
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		if pairs[i].True != pairs[j].True {
+			return pairs[i].True < pairs[j].True
+		}
+		return pairs[i].Predicted < pairs[j].Predicted
+	})
+
+	if limit > 0 && len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+	return pairs
+}
+
+// WriteConfusionMatrix prints the overall accuracy followed by the limit most confused pairs
+func WriteConfusionMatrix(w io.Writer, matrix map[string]map[string]int, limit int) error {
+	total, correct := 0, 0
+	for truth, row := range matrix {
+		for predicted, count := range row {
+			total += count
+			if predicted == truth {
+				correct += count
+			}
+		}
+	}
+
+	accuracy := 0.0
+	if total > 0 {
+		accuracy = float64(correct) / float64(total) * 100
+	}
+	if _, err := fmt.Fprintf(w, "Accuracy: %d/%d (%.1f%%)\n", correct, total, accuracy); err != nil {
+		return err
+	}
+
+	pairs := MostConfusedPairs(matrix, limit)
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "Most confused:"); err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		predicted := "(none)"
+		if pair.Predicted != "" {
+			predicted = fmt.Sprintf("%q U+%s", UnicodeToString(pair.Predicted), pair.Predicted)
+		}
+		if _, err := fmt.Fprintf(w, "  %q U+%s -> %s: %d\n", UnicodeToString(pair.True), pair.True, predicted, pair.Count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
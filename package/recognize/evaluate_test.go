@@ -0,0 +1,60 @@
+package recognize
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func TestBuildConfusionMatrix(t *testing.T) {
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{}}
+	for unicode, text := range map[string]string{"0041": "A", "004F": "O", "0049": "I"} {
+		features, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{text}, 3)))
+		if err != nil {
+			t.Fatalf("ExtractFeatures(%q) failed: %v", text, err)
+		}
+		features.Unicode = unicode
+		database.Characters[unicode] = features
+	}
+
+	// The database has no zero, so a zero drawn like O can only be confused
+	testSet := map[string]*character.Character{
+		"0041": test.CharacterFromImage(test.RenderText([]string{"A"}, 4)),
+		"0049": test.CharacterFromImage(test.RenderText([]string{"I"}, 4)),
+		"0030": test.CharacterFromImage(test.RenderText([]string{"O"}, 4)),
+		"0058": character.NewCharacter(8, 8, nil),
+	}
+
+	matrix := BuildConfusionMatrix(database, testSet)
+	if matrix["0041"]["0041"] != 1 || matrix["0049"]["0049"] != 1 {
+		t.Errorf("expected A and I recognized correctly, got %v", matrix)
+	}
+	if matrix["0030"]["004F"] != 1 {
+		t.Errorf("expected zero confused with O, got %v", matrix["0030"])
+	}
+	if matrix["0058"][""] != 1 {
+		t.Errorf("expected the blank glyph counted without a prediction, got %v", matrix["0058"])
+	}
+
+	pairs := MostConfusedPairs(matrix, 0)
+	if len(pairs) != 2 || pairs[0] != (ConfusedPair{True: "0030", Predicted: "004F", Count: 1}) {
+		t.Errorf("confused pairs = %v, want zero as O first", pairs)
+	}
+	if limited := MostConfusedPairs(matrix, 1); len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d pairs", len(limited))
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteConfusionMatrix(&buffer, matrix, 5); err != nil {
+		t.Fatalf("WriteConfusionMatrix failed: %v", err)
+	}
+	output := buffer.String()
+	for _, want := range []string{"Accuracy: 2/4 (50.0%)", `"0" U+0030 -> "O" U+004F: 1`, `"X" U+0058 -> (none): 1`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
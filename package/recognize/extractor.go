@@ -1,7 +1,9 @@
 package recognize
 
 import (
-	"os"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/bsthun/glyphcanvas/package/character"
 	characterCalculate "github.com/bsthun/glyphcanvas/package/character/calculate"
@@ -10,34 +12,88 @@ import (
 	"github.com/bsthun/glyphcanvas/package/region"
 	regionCalculate "github.com/bsthun/glyphcanvas/package/region/calculate"
 	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
-	"gopkg.in/yaml.v3"
 )
 
+// directionBins is the number of skeleton orientation bins over [0, π), 22.5 degrees each
+const directionBins = 8
+
+// DefaultMaxRegions is how many regions of a glyph get region features when ExtractOptions.MaxRegions is 0
+const DefaultMaxRegions = 10
+
+// DefaultSimpleGlyphComplexity is the characterHelper.CharacterComplexity below which a glyph is a single plain stroke
+// such as '.' or '-' when ExtractOptions.SimpleGlyphComplexity is 0. Such a glyph skips the region breakdown, each of
+// its components counts as one region with no features. Real glyphs sit close on both sides of it, '|' scores about 7
+// and skips while 'i' scores about 10 and does not, so a database and the pages matched against it need the same value.
+const DefaultSimpleGlyphComplexity = 8.0
+
+type ExtractOptions struct {
+	NormalizeOrientation  bool    // Rotate each region so its principal axis is horizontal before computing region features
+	AlignPrincipalAxis    bool    // Straighten a slightly tilted glyph before any feature, can hurt naturally slanted scripts
+	MaxRegions            int     // Largest regions kept for region features, 0 uses DefaultMaxRegions
+	BridgeGap             int     // Join collinear dashes and dots at most this many canvas pixels apart, 0 leaves them apart
+	ContourOnly           bool    // Compute moments, center of mass and zoning on the boundary pixels so outline and filled glyphs compare alike
+	SimpleGlyphComplexity float64 // Complexity below which the region breakdown is skipped, 0 uses DefaultSimpleGlyphComplexity, negative never skips
+}
+
+// ExtractFeatures computes the recognition features of char. A nil character returns ErrEmptyCharacter, a blank one
+// returns the empty feature set straight away without running any analysis
 func ExtractFeatures(char *character.Character) (*CharacterFeature, error) {
+	return ExtractFeaturesWithOptions(char, ExtractOptions{})
+}
+
+func ExtractFeaturesWithOptions(char *character.Character, opts ExtractOptions) (*CharacterFeature, error) {
+	if char == nil {
+		return nil, character.ErrEmptyCharacter
+	}
+	if char.IsEmpty() {
+		return emptyFeatures(), nil
+	}
+
 	features := &CharacterFeature{}
 
+	// Database and page glyphs come at different sizes, compute every feature at the same resolution.
+	// This also keeps an accidental page sized input from stalling the quadratic analysis steps.
+	source := char
+	if opts.AlignPrincipalAxis {
+		char = characterCalculate.AlignPrincipalAxis(char)
+	}
+	char = characterCalculate.NormalizeToCanvas(char, characterCalculate.NormalizedCanvasSize)
+	if opts.BridgeGap > 0 {
+		characterHelper.CharacterBridgeDashes(char, opts.BridgeGap)
+	}
+
 	err := characterHelper.CharacterDetectAnchors(char)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to detect anchors: %w", err)
 	}
 
 	err = characterHelper.CharacterComputeMedialAxis(char)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to compute medial axis: %w", err)
 	}
 
+	// The bitmap features below do not depend on it, keep the error on the character instead of failing
 	err = characterHelper.CharacterComprehensiveAnalysis(char)
 	if err != nil {
-		// Ignore error as it may not be critical
+		source.RecordAnalysisError(fmt.Errorf("comprehensive analysis: %w", err))
+	}
+
+	// The ink distribution features of a thin outline would otherwise be dominated by how the interior is filled
+	shape := char
+	if opts.ContourOnly {
+		shape = characterCalculate.CharacterContour(char)
 	}
 
 	features.GridSignature = helper.ComputeGridSignature(char, 8)
 	features.DirectionHist = helper.ComputeDirectionHistogram(char)
-	features.ZoningFeatures = helper.ComputeZoningFeatures(char)
+	features.ZoningFeatures = helper.ComputeZoningFeatures(shape)
 	features.ChainCode = helper.ComputeChainCodeFromBitmap(char)
-	features.HuMoments = helper.ComputeHuMomentsFromChar(char)
+	features.HuMoments = helper.ComputeHuMomentsFromChar(shape)
 
-	if char.GetBoundingBoxHeight() > 0 {
+	if box := source.EdgeBox; box != nil && !opts.AlignPrincipalAxis && box.Height() > 0 {
+		// The sub-pixel extent of an anti-aliased scan, alignment rotates the glyph away from it
+		features.AspectRatio = box.Width() / box.Height()
+	} else if char.GetBoundingBoxHeight() > 0 {
 		features.AspectRatio = float64(char.GetBoundingBoxWidth()) / float64(char.GetBoundingBoxHeight())
 	} else {
 		features.AspectRatio = 1.0
@@ -47,125 +103,129 @@ func ExtractFeatures(char *character.Character) (*CharacterFeature, error) {
 	if totalArea > 0 {
 		features.Density = float64(char.GetPixelCount()) / totalArea
 	}
+	features.FillRatio = helper.ComputeFillRatioExcludingHoles(char)
 
-	cx, cy := helper.ComputeCenterOfMass(char)
+	cx, cy := helper.ComputeCenterOfMass(shape)
 	features.CenterOfMass = [2]float64{cx, cy}
 
+	features.Elongation = helper.ComputeElongation(shape)
+	features.Eccentricity = helper.ComputeEccentricity(shape)
+	features.SymmetryAxis, features.SymmetryScore = helper.ComputeSymmetryAxis(char)
+	features.Directions = characterHelper.CharacterDominantDirections(char, directionBins)
+
 	endpoints, junctions := helper.CountEndpointsAndJunctions(char)
 	features.EndPoints = endpoints
 	features.Junctions = junctions
+	features.StrokeCount = characterHelper.CharacterEstimateStrokeCount(char)
 
-	regions, _ := characterCalculate.CharacterBreakdownToRegions(char)
-	features.RegionCount = len(regions)
+	features.ComponentCount, features.Components = extractComponents(char)
 
-	features.RegionFeatures = extractRegionFeatures(char, regions)
+	// Region features describe how strokes join, a plain stroke has none worth the cost on punctuation heavy text
+	simpleGlyphComplexity := opts.SimpleGlyphComplexity
+	if simpleGlyphComplexity == 0 {
+		simpleGlyphComplexity = DefaultSimpleGlyphComplexity
+	}
+	if characterHelper.CharacterComplexity(char) < simpleGlyphComplexity {
+		features.RegionCount = features.ComponentCount
+	} else {
+		// A failed breakdown leaves no regions, keep why on the character instead of a silent count of 0
+		regions, err := characterCalculate.CharacterBreakdownToRegions(char)
+		if err != nil {
+			source.RecordAnalysisError(fmt.Errorf("region breakdown: %w", err))
+		}
+		features.RegionCount = len(regions)
+		features.RegionFeatures = extractRegionFeatures(char, regions, opts)
+	}
 
 	features.TopologyHash = helper.ComputeTopologyHash(features.EndPoints, features.Junctions, features.RegionCount, features.ChainCode, features.GridSignature)
 
 	return features, nil
 }
 
-func extractRegionFeatures(char *character.Character, regions []*region.Region) []RegionFeatureSet {
+// emptyFeatures describes a blank glyph: every measure zero, a square aspect and an all background grid signature
+func emptyFeatures() *CharacterFeature {
+	features := &CharacterFeature{
+		GridSignature: strings.Repeat("0", 8*8),
+		AspectRatio:   1.0,
+	}
+	features.TopologyHash = helper.ComputeTopologyHash(0, 0, 0, "", features.GridSignature)
+	return features
+}
+
+func extractRegionFeatures(char *character.Character, regions []*region.Region, opts ExtractOptions) []RegionFeatureSet {
 	var featureSets []RegionFeatureSet
 
-	for _, reg := range regions {
-		if reg == nil || len(reg.Draws) == 0 {
-			continue
-		}
+	// Specks below the minimum region size have no meaningful shape and would only match other specks
+	minArea := int(character.DefaultCharacterConfig().MinRegionSize)
+	if char.Config != nil {
+		minArea = int(char.Config.MinRegionSize)
+	}
 
-		features := RegionFeatureSet{}
-
-		arc := regionCalculate.RegionArc(reg)
-		if arc != nil {
-			features.ArcType = getArcTypeString(arc.Type)
-			moments := regionHelper.RegionComputeMoments(reg)
-			huMoments := regionHelper.RegionComputeHuInvariants(moments)
-			features.Circularity = regionHelper.RegionComputeCircularity(huMoments)
-			features.Linearity = regionHelper.RegionComputeLinearity(huMoments)
-
-			// Compute curve strength
-			edges := regionHelper.RegionExtractEdge(reg)
-			chainCode := regionHelper.RegionComputeChainCode(edges)
-			curvatures := regionHelper.RegionComputeCurvatures(chainCode)
-			features.CurveStrength = float64(regionHelper.RegionComputeCurveStrength(curvatures, edges))
-		}
+	limit := opts.MaxRegions
+	if limit <= 0 {
+		limit = DefaultMaxRegions
+	}
 
-		if arc == nil {
-			moments := regionHelper.RegionComputeMoments(reg)
-			huArray := regionHelper.RegionComputeHuInvariants(moments)
-			copy(features.HuMoments[:], huArray)
+	kept := LargestRegions(regions, minArea, limit)
+	analyzed := kept
+	if opts.NormalizeOrientation {
+		analyzed = make([]*region.Region, len(kept))
+		for i, reg := range kept {
+			analyzed[i] = regionHelper.RegionNormalizeOrientation(reg)
 		}
+	}
 
-		edges := regionHelper.RegionExtractEdge(reg)
-		chainCode := regionHelper.RegionComputeChainCode(edges)
-		features.ChainCodeHash = helper.HashChainCode(chainCode)
+	// Page recognition already runs one glyph per worker, stay on this goroutine
+	analyses := regionCalculate.RegionAnalyzeAll(analyzed, 1)
+
+	for i, reg := range kept {
+		features := regionCalculate.RegionFeaturesFromAnalysis(analyses[i])
 
 		if char.GetPixelCount() > 0 {
 			features.RelativeSize = float64(len(reg.Draws)) / float64(char.GetPixelCount())
 		}
 
-		if len(reg.Draws) > 0 {
-			var sumX, sumY uint32
-			for _, point := range reg.Draws {
-				sumX += uint32(point.X)
-				sumY += uint32(point.Y)
-			}
-			avgX := float64(sumX) / float64(len(reg.Draws))
-			avgY := float64(sumY) / float64(len(reg.Draws))
-
-			if char.SizeX > 0 && char.SizeY > 0 {
-				features.RelativePos[0] = avgX / float64(char.SizeX)
-				features.RelativePos[1] = avgY / float64(char.SizeY)
-			}
-		}
+		features.RelativePos = reg.RelativePosition(char.SizeX, char.SizeY)
 
 		featureSets = append(featureSets, features)
-
-		if len(featureSets) >= 10 {
-			break
-		}
 	}
 
 	return featureSets
 }
 
-func getArcTypeString(arcType region.ArcType) string {
-	switch arcType {
-	case region.ArcTypeCircle:
-		return "circle"
-	case region.ArcTypeStrengthLine:
-		return "strength_line"
-	case region.ArcTypeCurveLine:
-		return "curve_line"
-	case region.ArcTypeTriangle:
-		return "triangle"
-	case region.ArcTypeRectangle:
-		return "rectangle"
-	default:
-		return "unknown"
+func extractComponents(char *character.Character) (int, []ComponentFeature) {
+	count, layout := helper.ComputeComponents(char, MaxComponents)
+	components := make([]ComponentFeature, len(layout))
+	for i, component := range layout {
+		components[i] = ComponentFeature{RelativeSize: component[0], RelativePos: [2]float64{component[1], component[2]}}
 	}
+	return count, components
 }
 
-func SaveDatabase(database *FeatureDatabase, path string) error {
-	data, err := yaml.Marshal(database)
-	if err != nil {
-		return err
+// LargestRegions returns up to limit regions with at least minArea draws, largest first. Equal sizes are ordered by
+// their top then left edge so the selection does not depend on the order regions were found in.
+func LargestRegions(regions []*region.Region, minArea, limit int) []*region.Region {
+	var kept []*region.Region
+	for _, reg := range regions {
+		if reg != nil && len(reg.Draws) > 0 && len(reg.Draws) >= minArea {
+			kept = append(kept, reg)
+		}
 	}
 
-	return os.WriteFile(path, data, 0644)
-}
-
-func LoadDatabase(path string) (*FeatureDatabase, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if len(kept[i].Draws) != len(kept[j].Draws) {
+			return len(kept[i].Draws) > len(kept[j].Draws)
+		}
+		left1, top1, _, _ := kept[i].ContentBounds()
+		left2, top2, _, _ := kept[j].ContentBounds()
+		if top1 != top2 {
+			return top1 < top2
+		}
+		return left1 < left2
+	})
 
-	var database FeatureDatabase
-	err = yaml.Unmarshal(data, &database)
-	if err != nil {
-		return nil, err
+	if limit > 0 && len(kept) > limit {
+		kept = kept[:limit]
 	}
-
-	return &database, nil
+	return kept
 }
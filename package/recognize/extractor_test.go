@@ -0,0 +1,450 @@
+package recognize
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/package/region"
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func TestExtractFeaturesOversized(t *testing.T) {
+	char := test.CharacterFromImage(test.RenderText([]string{"O"}, 40))
+	if char.SizeX <= char.Config.MaxGlyphSize && char.SizeY <= char.Config.MaxGlyphSize {
+		t.Fatalf("test glyph %dx%d is not oversized", char.SizeX, char.SizeY)
+	}
+
+	// Normalizing onto the canvas is the guard, the analysis never sees the oversized glyph
+	start := time.Now()
+	features, err := ExtractFeatures(char)
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("ExtractFeatures took %v on an oversized glyph", elapsed)
+	}
+	if features.RegionCount == 0 || features.Density == 0 {
+		t.Errorf("expected features from the normalized glyph, got %d regions and density %v", features.RegionCount, features.Density)
+	}
+	for _, err := range char.AnalysisErrors {
+		if errors.Is(err, character.ErrGlyphTooLarge) {
+			t.Errorf("expected the normalized glyph to pass the size guard, got %v", err)
+		}
+	}
+}
+
+func TestExtractFeaturesNormalizesSize(t *testing.T) {
+	small, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 2)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+
+	distances := map[string]float64{}
+	for _, text := range []string{"A", "B", "H", "X"} {
+		large, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{text}, 6)))
+		if err != nil {
+			t.Fatalf("ExtractFeatures(%q) failed: %v", text, err)
+		}
+		distances[text] = computeFeatureDistance(small, large)
+	}
+
+	if distances["A"] > 0.05 {
+		t.Errorf("20px and 60px A are %v apart, want them to match closely", distances["A"])
+	}
+	for text, distance := range distances {
+		if text != "A" && distance <= distances["A"] {
+			t.Errorf("20px A is %v from 60px %s, no further than from 60px A at %v", distance, text, distances["A"])
+		}
+	}
+}
+
+func TestExtractFeaturesErrors(t *testing.T) {
+	if _, err := ExtractFeatures(nil); !errors.Is(err, character.ErrEmptyCharacter) {
+		t.Errorf("expected ErrEmptyCharacter for a nil character, got %v", err)
+	}
+	empty, err := ExtractFeatures(character.NewCharacter(8, 8, nil))
+	if err != nil {
+		t.Fatalf("expected the empty feature set for a blank character, got %v", err)
+	}
+	if empty.GridSignature != strings.Repeat("0", 64) || empty.AspectRatio != 1 || empty.TopologyHash == "" || empty.RegionCount != 0 || empty.Density != 0 {
+		t.Errorf("unexpected empty feature set %+v", empty)
+	}
+	if _, err := Recognize(character.NewCharacter(8, 8, nil), &FeatureDatabase{Characters: map[string]*CharacterFeature{"0041": empty}}); !errors.Is(err, character.ErrEmptyCharacter) {
+		t.Errorf("expected ErrEmptyCharacter when recognizing a blank character, got %v", err)
+	}
+
+	// A page sized blank canvas must not reach the analysis, it leaves the character untouched
+	blank := character.NewCharacter(4000, 4000, nil)
+	if features, err := ExtractFeaturesWithOptions(blank, ExtractOptions{AlignPrincipalAxis: true, BridgeGap: 2}); err != nil || !reflect.DeepEqual(features, empty) {
+		t.Errorf("expected the empty feature set for a large blank character, got %+v, %v", features, err)
+	}
+	if len(blank.AnchorPoints) != 0 || len(blank.MedialAxis) != 0 || len(blank.AnalysisErrors) != 0 {
+		t.Errorf("expected the blank character to be left unanalyzed")
+	}
+
+	char := test.CharacterFromImage(test.RenderText([]string{"A"}, 3))
+	if _, err := Recognize(char, &FeatureDatabase{}); !errors.Is(err, ErrEmptyDatabase) {
+		t.Errorf("expected ErrEmptyDatabase, got %v", err)
+	}
+
+	// A failed comprehensive analysis is recorded on the character instead of failing extraction
+	char.Config.ComputationTimeout = 1
+	large := test.CharacterFromImage(test.RenderText([]string{"O"}, 20))
+	large.Config = char.Config
+	if _, err := ExtractFeatures(large); err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	recorded := false
+	for _, err := range large.AnalysisErrors {
+		recorded = recorded || errors.Is(err, character.ErrAnalysisTimeout)
+	}
+	if !recorded {
+		t.Errorf("expected an ErrAnalysisTimeout on the character, got %v", large.AnalysisErrors)
+	}
+}
+
+func TestExtractRegionFeaturesNormalizeOrientation(t *testing.T) {
+	bar := func(angle float64) (*character.Character, []*region.Region) {
+		char := character.NewCharacter(80, 80, nil)
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for l := -25.0; l <= 25; l += 0.5 {
+			for w := -3.0; w <= 3; w += 0.5 {
+				x := uint16(math.Round(40 + l*cos - w*sin))
+				y := uint16(math.Round(40 + l*sin + w*cos))
+				if !char.IsDrew(x, y) {
+					char.Draw(x, y)
+				}
+			}
+		}
+		return char, []*region.Region{char.ToRegion()}
+	}
+
+	horizontalChar, horizontalRegions := bar(0)
+	tiltedChar, tiltedRegions := bar(30)
+
+	distance := func(opts ExtractOptions) (float64, float64) {
+		horizontal := extractRegionFeatures(horizontalChar, horizontalRegions, opts)
+		tilted := extractRegionFeatures(tiltedChar, tiltedRegions, opts)
+		if len(horizontal) != 1 || len(tilted) != 1 {
+			t.Fatalf("expected one region feature set each, got %d and %d", len(horizontal), len(tilted))
+		}
+
+		histogram := 0.0
+		for i := range horizontal[0].ChainCodeHist {
+			histogram += math.Abs(horizontal[0].ChainCodeHist[i] - tilted[0].ChainCodeHist[i])
+		}
+		return computeSingleRegionDistance(horizontal[0], tilted[0]), histogram
+	}
+
+	raw, rawHistogram := distance(ExtractOptions{})
+	normalized, normalizedHistogram := distance(ExtractOptions{NormalizeOrientation: true})
+	if normalized >= raw {
+		t.Errorf("normalized distance = %v, want below raw distance %v", normalized, raw)
+	}
+	if normalizedHistogram >= rawHistogram {
+		t.Errorf("normalized histogram distance = %v, want below raw %v", normalizedHistogram, rawHistogram)
+	}
+}
+
+// strokeGlyph draws 6px wide strokes given as (x1, y1, x2, y2) around the center of an 80x80 canvas, rotated by angle degrees
+func strokeGlyph(angle float64, strokes [][4]float64) *character.Character {
+	char := character.NewCharacter(80, 80, nil)
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	for _, stroke := range strokes {
+		dx, dy := stroke[2]-stroke[0], stroke[3]-stroke[1]
+		length := math.Hypot(dx, dy)
+		for l := 0.0; l <= length; l += 0.5 {
+			for w := -3.0; w <= 3; w += 0.5 {
+				px := stroke[0] + (dx*l-dy*w)/length
+				py := stroke[1] + (dy*l+dx*w)/length
+				x := uint16(math.Round(40 + px*cos - py*sin))
+				y := uint16(math.Round(40 + px*sin + py*cos))
+				if !char.IsDrew(x, y) {
+					char.Draw(x, y)
+				}
+			}
+		}
+	}
+	return char
+}
+
+func TestExtractFeaturesAlignPrincipalAxis(t *testing.T) {
+	glyphs := map[string][][4]float64{
+		"T": {{-22, -25, 22, -25}, {0, -25, 0, 25}},
+		"L": {{-15, -25, -15, 25}, {-15, 25, 20, 25}},
+		"J": {{-22, -25, 22, -25}, {10, -25, 10, 25}, {10, 25, -10, 25}},
+		"Y": {{-20, -25, 0, 0}, {20, -25, 0, 0}, {0, 0, 0, 25}},
+	}
+
+	recognizeTilted := func(opts ExtractOptions) RecognitionCandidate {
+		database := &FeatureDatabase{Characters: map[string]*CharacterFeature{}}
+		for name, strokes := range glyphs {
+			features, err := ExtractFeaturesWithOptions(strokeGlyph(0, strokes), opts)
+			if err != nil {
+				t.Fatalf("ExtractFeatures(%s) failed: %v", name, err)
+			}
+			database.Characters[name] = features
+		}
+
+		features, err := ExtractFeaturesWithOptions(strokeGlyph(10, glyphs["T"]), opts)
+		if err != nil {
+			t.Fatalf("ExtractFeatures failed: %v", err)
+		}
+		return RecognizeCharacter(features, database)[0]
+	}
+
+	raw := recognizeTilted(ExtractOptions{})
+	aligned := recognizeTilted(ExtractOptions{AlignPrincipalAxis: true})
+	if aligned.Unicode != "T" {
+		t.Fatalf("aligned top candidate = %s, want T", aligned.Unicode)
+	}
+	if aligned.Distance >= raw.Distance/2 {
+		t.Errorf("aligned distance to T = %v, want well below the unaligned %v", aligned.Distance, raw.Distance)
+	}
+}
+
+func TestExtractRegionFeaturesKeepsLargestRegions(t *testing.T) {
+	// 15 horizontal bars of 5 to 19 pixels, found smallest first
+	char := character.NewCharacter(40, 40, nil)
+	var regions []*region.Region
+	for i := 0; i < 15; i++ {
+		reg := region.NewRegion(40, 40)
+		for x := 0; x < 5+i; x++ {
+			reg.Draw(uint16(x), uint16(2*i))
+			char.Draw(uint16(x), uint16(2*i))
+		}
+		regions = append(regions, reg)
+	}
+
+	sizes := func(features []RegionFeatureSet) []int {
+		var result []int
+		for _, set := range features {
+			result = append(result, int(math.Round(set.RelativeSize*float64(char.GetPixelCount()))))
+		}
+		return result
+	}
+
+	want := []int{19, 18, 17, 16, 15, 14, 13, 12, 11, 10}
+	if got := sizes(extractRegionFeatures(char, regions, ExtractOptions{})); !slices.Equal(got, want) {
+		t.Errorf("kept region sizes = %v, want %v", got, want)
+	}
+	if got := sizes(extractRegionFeatures(char, regions, ExtractOptions{MaxRegions: 3})); !slices.Equal(got, want[:3]) {
+		t.Errorf("kept region sizes with a cap of 3 = %v, want %v", got, want[:3])
+	}
+
+	// The selection does not depend on the order the regions arrive in
+	slices.Reverse(regions)
+	if got := sizes(extractRegionFeatures(char, regions, ExtractOptions{})); !slices.Equal(got, want) {
+		t.Errorf("kept region sizes from reversed regions = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkExtractRegionFeatures(b *testing.B) {
+	char := character.NewCharacter(64, 64, nil)
+	for x := uint16(10); x <= 50; x++ {
+		for y := uint16(10); y <= 50; y++ {
+			if x <= 16 || y <= 16 || x >= 44 {
+				char.Draw(x, y)
+			}
+		}
+	}
+
+	buildRegions := func() []*region.Region {
+		return []*region.Region{char.ToRegion()}
+	}
+
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			regions := buildRegions()
+			b.StartTimer()
+			_ = extractRegionFeatures(char, regions, ExtractOptions{})
+		}
+	})
+
+	b.Run("warm", func(b *testing.B) {
+		b.ReportAllocs()
+		regions := buildRegions()
+		_ = extractRegionFeatures(char, regions, ExtractOptions{})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = extractRegionFeatures(char, regions, ExtractOptions{})
+		}
+	})
+}
+
+func TestExtractRegionFeaturesSkipsSpecks(t *testing.T) {
+	char := test.CharacterFromImage(test.RenderText([]string{"O"}, 3))
+	glyph := char.ToRegion()
+
+	speck := region.NewRegion(char.SizeX, char.SizeY)
+	speck.Draw(0, 0)
+	speck.Draw(1, 0)
+
+	if sets := extractRegionFeatures(char, []*region.Region{speck}, ExtractOptions{}); len(sets) != 0 {
+		t.Fatalf("expected a 2 pixel region to be skipped, got %+v", sets)
+	}
+
+	plain := extractRegionFeatures(char, []*region.Region{glyph}, ExtractOptions{})
+	specked := extractRegionFeatures(char, []*region.Region{glyph, speck}, ExtractOptions{})
+	if computeRegionFeaturesDistance(plain, specked) != 0 {
+		t.Errorf("a speck changed the region distance of an otherwise identical glyph")
+	}
+	if distance := computeRegionFeaturesDistance(plain, extractRegionFeatures(char, []*region.Region{speck}, ExtractOptions{})); distance != 1 {
+		t.Errorf("speck only glyph region distance = %v, want the maximum 1", distance)
+	}
+
+	// Missing Hu moments on one side must not be compared as valid zeros
+	valid := RegionFeatureSet{ArcType: "curve_line", HuMoments: [7]float64{0.2, 0.01, 0.001}}
+	other := valid
+	other.HuMoments = [7]float64{0.9, 0.5, 0.3}
+	missing := RegionFeatureSet{ArcType: "curve_line"}
+	if computeSingleRegionDistance(missing, valid) != computeSingleRegionDistance(missing, other) {
+		t.Errorf("Hu moments were compared against a region without them")
+	}
+	if computeSingleRegionDistance(valid, other) <= computeSingleRegionDistance(valid, valid) {
+		t.Errorf("expected differing Hu moments to add distance between regions that have them")
+	}
+}
+
+func BenchmarkExtractFeaturesEmpty(b *testing.B) {
+	blank := character.NewCharacter(1000, 1000, nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ExtractFeatures(blank); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestExtractFeaturesContourOnly(t *testing.T) {
+	// A one pixel outline is what an outline font renders, the filled square has the same contour
+	square := func(outline bool) *character.Character {
+		char := character.NewCharacter(60, 60, character.DefaultCharacterConfig())
+		for x := uint16(10); x < 50; x++ {
+			for y := uint16(10); y < 50; y++ {
+				if !outline || x == 10 || x == 49 || y == 10 || y == 49 {
+					char.Draw(x, y)
+				}
+			}
+		}
+		return char
+	}
+
+	// shapeDistance sums the distance terms ContourOnly changes
+	shapeDistance := func(opts ExtractOptions) float64 {
+		filled, err := ExtractFeaturesWithOptions(square(false), opts)
+		if err != nil {
+			t.Fatalf("ExtractFeatures(filled) failed: %v", err)
+		}
+		outline, err := ExtractFeaturesWithOptions(square(true), opts)
+		if err != nil {
+			t.Fatalf("ExtractFeatures(outline) failed: %v", err)
+		}
+
+		terms := ExplainDistance(filled, outline)
+		return terms["zoning"] + terms["hu_moments"] + terms["center_of_mass"] + terms["elongation"] + terms["eccentricity"]
+	}
+
+	contour := shapeDistance(ExtractOptions{ContourOnly: true})
+	fill := shapeDistance(ExtractOptions{})
+	if contour > 0.06 {
+		t.Errorf("filled and outline squares are %v apart in contour mode, want them to match closely", contour)
+	}
+	if contour >= fill/3 {
+		t.Errorf("contour mode distance %v, want well below the fill distance %v", contour, fill)
+	}
+}
+
+func TestExtractFeaturesComponents(t *testing.T) {
+	// A synthetic 'i', a square dot above a stem, and an 'l' that is the stem alone
+	glyph := func(dot bool) *character.Character {
+		char := character.NewCharacter(40, 60, character.DefaultCharacterConfig())
+		for x := uint16(16); x < 24; x++ {
+			for y := uint16(20); y < 55; y++ {
+				char.Draw(x, y)
+			}
+			if dot {
+				for y := uint16(5); y < 13; y++ {
+					char.Draw(x, y)
+				}
+			}
+		}
+		return char
+	}
+
+	i, err := ExtractFeatures(glyph(true))
+	if err != nil {
+		t.Fatalf("ExtractFeatures(i) failed: %v", err)
+	}
+	if i.ComponentCount != 2 || len(i.Components) != 2 {
+		t.Fatalf("Expected the i to report 2 components, got %d with %d described", i.ComponentCount, len(i.Components))
+	}
+	stem, dot := i.Components[0], i.Components[1]
+	if stem.RelativeSize <= dot.RelativeSize || math.Abs(stem.RelativeSize+dot.RelativeSize-1) > 1e-9 {
+		t.Errorf("Expected the stem to hold most of the ink and the sizes to sum to 1, got %v and %v", stem.RelativeSize, dot.RelativeSize)
+	}
+	if dot.RelativePos[1] >= stem.RelativePos[1] || math.Abs(dot.RelativePos[0]-stem.RelativePos[0]) > 0.05 {
+		t.Errorf("Expected the dot centered above the stem, got dot %v and stem %v", dot.RelativePos, stem.RelativePos)
+	}
+
+	l, err := ExtractFeatures(glyph(false))
+	if err != nil {
+		t.Fatalf("ExtractFeatures(l) failed: %v", err)
+	}
+	if l.ComponentCount != 1 {
+		t.Errorf("Expected the l to report 1 component, got %d", l.ComponentCount)
+	}
+	if distance := ExplainDistance(i, l)["components"]; distance <= 0 {
+		t.Errorf("Expected the component term to separate i from l, got %v", distance)
+	}
+}
+
+func TestExtractFeaturesSkipsRegionsForSimpleGlyphs(t *testing.T) {
+	dash, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"-"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures(-) failed: %v", err)
+	}
+	if len(dash.RegionFeatures) != 0 {
+		t.Errorf("Expected the dash to skip region analysis, got %d region feature sets", len(dash.RegionFeatures))
+	}
+	if dash.RegionCount != 1 {
+		t.Errorf("Expected the dash to count as 1 region, got %d", dash.RegionCount)
+	}
+
+	a, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures(A) failed: %v", err)
+	}
+	if len(a.RegionFeatures) == 0 {
+		t.Errorf("Expected the A to be broken down into regions, got none")
+	}
+
+	// '|' and 'i' score just below and just above the default cutoff
+	extract := func(text string, opts ExtractOptions) *CharacterFeature {
+		features, err := ExtractFeaturesWithOptions(test.CharacterFromImage(test.RenderText([]string{text}, 2)), opts)
+		if err != nil {
+			t.Fatalf("ExtractFeatures(%s) failed: %v", text, err)
+		}
+		return features
+	}
+	if bar := extract("|", ExtractOptions{}); len(bar.RegionFeatures) != 0 {
+		t.Errorf("Expected the bar to skip region analysis, got %d region feature sets", len(bar.RegionFeatures))
+	}
+	if i := extract("i", ExtractOptions{}); len(i.RegionFeatures) == 0 {
+		t.Errorf("Expected the i to be broken down into regions, got none")
+	}
+	if i := extract("i", ExtractOptions{SimpleGlyphComplexity: 12}); len(i.RegionFeatures) != 0 {
+		t.Errorf("Expected a higher cutoff to skip region analysis of the i, got %d region feature sets", len(i.RegionFeatures))
+	}
+	if bar := extract("|", ExtractOptions{SimpleGlyphComplexity: -1}); len(bar.RegionFeatures) == 0 {
+		t.Errorf("Expected a negative cutoff to break the bar down into regions, got none")
+	}
+}
@@ -2,8 +2,11 @@ package helper
 
 import (
 	"fmt"
+	"math"
+	"sort"
 
 	"github.com/bsthun/glyphcanvas/package/character"
+	characterHelper "github.com/bsthun/glyphcanvas/package/character/helper"
 	regionHelper "github.com/bsthun/glyphcanvas/package/region/helper"
 )
 
@@ -85,14 +88,21 @@ func ComputeDirectionHistogram(char *character.Character) [8]float64 {
 	return hist
 }
 
+// ComputeZoningFeatures returns the share of ink in each cell of a 4x4 grid over the tight bounding box, row by row
 func ComputeZoningFeatures(char *character.Character) [16]float64 {
 	var features [16]float64
-	zoneWidth := float64(char.SizeX) / 4.0
-	zoneHeight := float64(char.SizeY) / 4.0
+	if char.IsEmpty() {
+		return features
+	}
+
+	// Zones split the tight bounding box, so padding around the glyph does not move the zone boundaries
+	minX, minY := float64(char.BoundingBox["minX"]), float64(char.BoundingBox["minY"])
+	zoneWidth := float64(char.GetBoundingBoxWidth()) / 4.0
+	zoneHeight := float64(char.GetBoundingBoxHeight()) / 4.0
 
 	for _, point := range char.Draws {
-		zoneX := int(float64(point.X) / zoneWidth)
-		zoneY := int(float64(point.Y) / zoneHeight)
+		zoneX := int((float64(point.X) - minX) / zoneWidth)
+		zoneY := int((float64(point.Y) - minY) / zoneHeight)
 
 		if zoneX >= 4 {
 			zoneX = 3
@@ -123,8 +133,23 @@ func ComputeChainCodeFromBitmap(char *character.Character) string {
 		return ""
 	}
 
+	// Start at the topmost, then leftmost, pixel so the code does not depend on draw order
+	startX, startY := uint16(0), uint16(0)
+	found := false
+	for _, point := range char.Draws {
+		if !char.IsDrew(point.X, point.Y) {
+			continue
+		}
+		if !found || point.Y < startY || (point.Y == startY && point.X < startX) {
+			startX, startY = point.X, point.Y
+			found = true
+		}
+	}
+	if !found {
+		return ""
+	}
+
 	visited := make(map[string]bool)
-	startX, startY := char.Draws[0].X, char.Draws[0].Y
 	currentX, currentY := startX, startY
 
 	chainCode := ""
@@ -170,71 +195,217 @@ func ComputeChainCodeFromBitmap(char *character.Character) string {
 }
 
 func ComputeHuMomentsFromChar(char *character.Character) [7]float64 {
-	moments := make(map[string]float64)
+	moments := regionHelper.RegionComputeMoments(char.ToRegion())
+	huArray := regionHelper.RegionComputeHuInvariants(moments)
+	var result [7]float64
+	for i, hu := range huArray {
+		// A degenerate glyph must not carry NaN or Inf into the database or the distance
+		if !math.IsNaN(hu) && !math.IsInf(hu, 0) {
+			result[i] = hu
+		}
+	}
+	return result
+}
 
-	m00, m10, m01 := 0.0, 0.0, 0.0
-	m20, m02, m11 := 0.0, 0.0, 0.0
-	m30, m03, m21, m12 := 0.0, 0.0, 0.0, 0.0
+// ComputeCenterOfMass returns the ink centroid relative to the tight bounding box, (0.5, 0.5) for a symmetric glyph
+// whatever canvas it sits on
+func ComputeCenterOfMass(char *character.Character) (float64, float64) {
+	width, height := char.GetBoundingBoxWidth(), char.GetBoundingBoxHeight()
+	if width == 0 || height == 0 {
+		return 0, 0
+	}
 
+	var sumX, sumY uint64
+	count := 0
 	for _, point := range char.Draws {
-		x := float64(point.X)
-		y := float64(point.Y)
-
-		m00++
-		m10 += x
-		m01 += y
-		m11 += x * y
-		m20 += x * x
-		m02 += y * y
-		m30 += x * x * x
-		m03 += y * y * y
-		m21 += x * x * y
-		m12 += x * y * y
-	}
-
-	moments["m00"] = m00
-	moments["m10"] = m10
-	moments["m01"] = m01
-
-	if m00 > 0 {
-		xc := m10 / m00
-		yc := m01 / m00
-
-		moments["mu20"] = m20 - xc*m10
-		moments["mu02"] = m02 - yc*m01
-		moments["mu11"] = m11 - xc*m01
-		moments["mu30"] = m30 - 3*xc*m20 + 2*xc*xc*m10
-		moments["mu03"] = m03 - 3*yc*m02 + 2*yc*yc*m01
-		moments["mu21"] = m21 - 2*xc*m11 - yc*m20 + 2*xc*xc*m01
-		moments["mu12"] = m12 - 2*yc*m11 - xc*m02 + 2*yc*yc*m10
+		if !char.IsDrew(point.X, point.Y) {
+			continue
+		}
+		sumX += uint64(point.X)
+		sumY += uint64(point.Y)
+		count++
+	}
+	if count == 0 {
+		return 0, 0
 	}
 
-	huArray := regionHelper.RegionComputeHuInvariants(moments)
-	var result [7]float64
-	copy(result[:], huArray)
-	return result
+	// Measured from the outer edge of the box, so pixel centers sit half a pixel in
+	cx := float64(sumX)/float64(count) - float64(char.BoundingBox["minX"]) + 0.5
+	cy := float64(sumY)/float64(count) - float64(char.BoundingBox["minY"]) + 0.5
+
+	return cx / float64(width), cy / float64(height)
 }
 
-func ComputeCenterOfMass(char *character.Character) (float64, float64) {
+// ComputeFillRatioExcludingHoles returns the ink share of the bounding box with enclosed holes taken out of the area,
+// so an 'O' measures how much of its ring is inked rather than counting its counter as unfilled
+func ComputeFillRatioExcludingHoles(char *character.Character) float64 {
+	area := int(char.GetBoundingBoxWidth()) * int(char.GetBoundingBoxHeight())
+	if area == 0 {
+		return 0
+	}
+
+	for _, hole := range regionHelper.RegionExtractHoles(char.ToRegion()) {
+		area -= len(hole.Draws)
+	}
+	if area <= 0 {
+		return 0
+	}
+
+	return math.Min(1, float64(char.GetPixelCount())/float64(area))
+}
+
+// ComputeElongation returns 1 - minor/major principal axis length, 0 for round and near 1 for a stroke
+func ComputeElongation(char *character.Character) float64 {
+	lambda1, lambda2 := computePrincipalAxes(char)
+	if lambda1 <= 0 {
+		return 0
+	}
+
+	return 1 - math.Sqrt(lambda2/lambda1)
+}
+
+// ComputeEccentricity returns the eccentricity of the moment-equivalent ellipse, 0 for a circle and 1 for a line
+func ComputeEccentricity(char *character.Character) float64 {
+	lambda1, lambda2 := computePrincipalAxes(char)
+	if lambda1 <= 0 {
+		return 0
+	}
+
+	return math.Sqrt(1 - lambda2/lambda1)
+}
+
+func computePrincipalAxes(char *character.Character) (float64, float64) {
 	if len(char.Draws) == 0 {
 		return 0, 0
 	}
 
-	var sumX, sumY uint32
+	var sumX, sumY float64
 	for _, point := range char.Draws {
-		sumX += uint32(point.X)
-		sumY += uint32(point.Y)
+		sumX += float64(point.X)
+		sumY += float64(point.Y)
 	}
+	cx := sumX / float64(len(char.Draws))
+	cy := sumY / float64(len(char.Draws))
 
-	cx := float64(sumX) / float64(len(char.Draws))
-	cy := float64(sumY) / float64(len(char.Draws))
+	var mu20, mu02, mu11 float64
+	for _, point := range char.Draws {
+		dx := float64(point.X) - cx
+		dy := float64(point.Y) - cy
+		mu20 += dx * dx
+		mu02 += dy * dy
+		mu11 += dx * dy
+	}
+
+	return regionHelper.RegionComputePrincipalAxes(map[string]float64{"mu20": mu20, "mu02": mu02, "mu11": mu11})
+}
 
-	if char.SizeX > 0 && char.SizeY > 0 {
-		cx /= float64(char.SizeX)
-		cy /= float64(char.SizeY)
+// ComputeComponents returns the number of 8-connected ink components and, for up to limit of the largest, their share
+// of the ink and their centroid relative to the bounding box as {size, x, y}. Equal sizes are ordered top to bottom,
+// then left to right
+func ComputeComponents(char *character.Character, limit int) (int, [][3]float64) {
+	width, height := float64(char.GetBoundingBoxWidth()), float64(char.GetBoundingBoxHeight())
+	if char.IsEmpty() || width == 0 || height == 0 {
+		return 0, nil
 	}
 
-	return cx, cy
+	type component struct {
+		size       int
+		sumX, sumY float64
+		top, left  uint16 // Topmost, then leftmost, pixel
+	}
+	var components []component
+	total := 0
+	for _, pixels := range characterHelper.CharacterConnectedComponents(char) {
+		current := component{size: len(pixels), top: pixels[0].Y, left: pixels[0].X}
+		for _, point := range pixels {
+			current.sumX += float64(point.X)
+			current.sumY += float64(point.Y)
+			if point.Y < current.top || (point.Y == current.top && point.X < current.left) {
+				current.top, current.left = point.Y, point.X
+			}
+		}
+		total += current.size
+		components = append(components, current)
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].size != components[j].size {
+			return components[i].size > components[j].size
+		}
+		if components[i].top != components[j].top {
+			return components[i].top < components[j].top
+		}
+		return components[i].left < components[j].left
+	})
+
+	kept := components[:min(max(limit, 0), len(components))]
+	layout := make([][3]float64, 0, len(kept))
+	for _, current := range kept {
+		// Measured from the outer edge of the box like ComputeCenterOfMass
+		cx := current.sumX/float64(current.size) - float64(char.BoundingBox["minX"]) + 0.5
+		cy := current.sumY/float64(current.size) - float64(char.BoundingBox["minY"]) + 0.5
+		layout = append(layout, [3]float64{float64(current.size) / float64(total), cx / width, cy / height})
+	}
+
+	return len(components), layout
+}
+
+// ComputeSymmetryAxis searches the mirror axes through the ink centroid for the one under which the glyph overlaps
+// itself the most. The angle is in radians in [0, π), 0 for a horizontal axis and π/2 for a vertical one like that of
+// an 'A'. The score is the share of ink pixels whose mirror image is inked too, 1 for a perfectly symmetric glyph. A
+// glyph like 'F' scores low and its angle carries no meaning
+func ComputeSymmetryAxis(char *character.Character) (float64, float64) {
+	ink := make([]bool, int(char.SizeX)*int(char.SizeY))
+	var points [][2]float64
+	var sumX, sumY float64
+	for _, point := range char.Draws {
+		index := int(point.Y)*int(char.SizeX) + int(point.X)
+		if ink[index] || !char.IsDrew(point.X, point.Y) {
+			continue
+		}
+		ink[index] = true
+		// Pixel centers, so a glyph spanning columns 0 to 9 mirrors about 5 rather than 4.5
+		center := [2]float64{float64(point.X) + 0.5, float64(point.Y) + 0.5}
+		points = append(points, center)
+		sumX += center[0]
+		sumY += center[1]
+	}
+	if len(points) == 0 {
+		return 0, 0
+	}
+	cx, cy := sumX/float64(len(points)), sumY/float64(len(points))
+
+	overlap := func(angle float64) float64 {
+		cos2, sin2 := math.Cos(2*angle), math.Sin(2*angle)
+		matched := 0
+		for _, point := range points {
+			dx, dy := point[0]-cx, point[1]-cy
+			x := math.Floor(cx + cos2*dx + sin2*dy)
+			y := math.Floor(cy + sin2*dx - cos2*dy)
+			if x >= 0 && y >= 0 && x < float64(char.SizeX) && y < float64(char.SizeY) && ink[int(y)*int(char.SizeX)+int(x)] {
+				matched++
+			}
+		}
+		return float64(matched) / float64(len(points))
+	}
+
+	// A coarse sweep in 2 degree steps, then a finer one around the best axis
+	bestAngle, bestScore := 0.0, -1.0
+	for step := 0; step < 90; step++ {
+		angle := float64(step) * math.Pi / 90
+		if score := overlap(angle); score > bestScore {
+			bestAngle, bestScore = angle, score
+		}
+	}
+	coarse := bestAngle
+	for step := -4; step <= 4; step++ {
+		angle := math.Mod(coarse+float64(step)*math.Pi/720+math.Pi, math.Pi)
+		if score := overlap(angle); score > bestScore {
+			bestAngle, bestScore = angle, score
+		}
+	}
+
+	return bestAngle, bestScore
 }
 
 func CountEndpointsAndJunctions(char *character.Character) (int, int) {
@@ -272,27 +443,6 @@ func CountEndpointsAndJunctions(char *character.Character) (int, int) {
 	return endpoints, junctions
 }
 
-func HashChainCode(chainCode []int) string {
-	if len(chainCode) == 0 {
-		return ""
-	}
-
-	str := ""
-	for _, code := range chainCode {
-		str += fmt.Sprintf("%d", code)
-		if len(str) > 20 {
-			break
-		}
-	}
-
-	hash := 0
-	for i, c := range str {
-		hash = hash*31 + int(c) + i
-	}
-
-	return fmt.Sprintf("%08x", hash)
-}
-
 func ComputeTopologyHash(endpoints, junctions, regionCount int, chainCode, gridSignature string) string {
 	data := fmt.Sprintf("e%d_j%d_r%d_%s_%s",
 		endpoints,
@@ -0,0 +1,271 @@
+package helper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+func createLineCharacter() *character.Character {
+	char := character.NewCharacter(40, 40, nil)
+	for y := uint16(5); y < 35; y++ {
+		char.Draw(19, y)
+		char.Draw(20, y)
+	}
+	return char
+}
+
+func createDiskCharacter() *character.Character {
+	char := character.NewCharacter(40, 40, nil)
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 40; y++ {
+			dx, dy := x-20, y-20
+			if dx*dx+dy*dy <= 15*15 {
+				char.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+	return char
+}
+
+func TestComputeElongationAndEccentricity(t *testing.T) {
+	tests := []struct {
+		name            string
+		char            *character.Character
+		minElongation   float64
+		maxElongation   float64
+		minEccentricity float64
+		maxEccentricity float64
+	}{
+		{
+			name:            "Vertical stroke",
+			char:            createLineCharacter(),
+			minElongation:   0.9,
+			maxElongation:   1.0,
+			minEccentricity: 0.99,
+			maxEccentricity: 1.0,
+		},
+		{
+			name:            "Filled disk",
+			char:            createDiskCharacter(),
+			minElongation:   0.0,
+			maxElongation:   0.05,
+			minEccentricity: 0.0,
+			maxEccentricity: 0.2,
+		},
+		{
+			name:            "Empty character",
+			char:            character.NewCharacter(10, 10, nil),
+			minElongation:   0.0,
+			maxElongation:   0.0,
+			minEccentricity: 0.0,
+			maxEccentricity: 0.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elongation := ComputeElongation(tt.char)
+			if elongation < tt.minElongation || elongation > tt.maxElongation {
+				t.Errorf("ComputeElongation() = %v, want in [%v, %v]", elongation, tt.minElongation, tt.maxElongation)
+			}
+
+			eccentricity := ComputeEccentricity(tt.char)
+			if eccentricity < tt.minEccentricity || eccentricity > tt.maxEccentricity {
+				t.Errorf("ComputeEccentricity() = %v, want in [%v, %v]", eccentricity, tt.minEccentricity, tt.maxEccentricity)
+			}
+		})
+	}
+}
+
+func TestComputeChainCodeFromBitmapDrawOrder(t *testing.T) {
+	// An L drawn stroke by stroke and the same L drawn bottom row first
+	forward := character.NewCharacter(20, 20, nil)
+	for y := uint16(3); y < 15; y++ {
+		forward.Draw(4, y)
+	}
+	for x := uint16(5); x < 12; x++ {
+		forward.Draw(x, 14)
+	}
+
+	backward := character.NewCharacter(20, 20, nil)
+	for x := uint16(11); x >= 4; x-- {
+		backward.Draw(x, 14)
+	}
+	for y := uint16(13); y >= 3; y-- {
+		backward.Draw(4, y)
+	}
+
+	code := ComputeChainCodeFromBitmap(forward)
+	if code == "" {
+		t.Fatalf("expected a chain code for the L")
+	}
+	if other := ComputeChainCodeFromBitmap(backward); other != code {
+		t.Errorf("chain codes differ with draw order: %q and %q", code, other)
+	}
+	if code[0] != '2' {
+		t.Errorf("chain code %q should start down the stem from the topmost pixel", code)
+	}
+}
+
+func TestComputeCenterOfMass(t *testing.T) {
+	tests := []struct {
+		name  string
+		sizeX uint16
+		sizeY uint16
+		cx    int
+		cy    int
+	}{
+		{name: "Centered on a small canvas", sizeX: 40, sizeY: 40, cx: 20, cy: 20},
+		{name: "Off center on a wide canvas", sizeX: 120, sizeY: 64, cx: 90, cy: 20},
+		{name: "Tight crop", sizeX: 31, sizeY: 31, cx: 15, cy: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char := character.NewCharacter(tt.sizeX, tt.sizeY, nil)
+			for x := tt.cx - 15; x <= tt.cx+15; x++ {
+				for y := tt.cy - 15; y <= tt.cy+15; y++ {
+					dx, dy := x-tt.cx, y-tt.cy
+					if dx*dx+dy*dy <= 15*15 {
+						char.Draw(uint16(x), uint16(y))
+					}
+				}
+			}
+
+			cx, cy := ComputeCenterOfMass(char)
+			if math.Abs(cx-0.5) > 1e-9 || math.Abs(cy-0.5) > 1e-9 {
+				t.Errorf("center of mass = (%v, %v), want (0.5, 0.5)", cx, cy)
+			}
+		})
+	}
+
+	if cx, cy := ComputeCenterOfMass(character.NewCharacter(10, 10, nil)); cx != 0 || cy != 0 {
+		t.Errorf("expected (0, 0) for an empty character, got (%v, %v)", cx, cy)
+	}
+}
+
+func TestComputeFillRatioExcludingHoles(t *testing.T) {
+	ring := character.NewCharacter(40, 40, nil)
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 40; y++ {
+			dx, dy := x-20, y-20
+			if distance := dx*dx + dy*dy; distance <= 15*15 && distance > 10*10 {
+				ring.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		char    *character.Character
+		holes   bool
+		minimum float64
+	}{
+		{name: "Ring", char: ring, holes: true, minimum: 0.55},
+		{name: "Disk", char: createDiskCharacter(), holes: false, minimum: 0.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			density := float64(tt.char.GetPixelCount()) / float64(int(tt.char.GetBoundingBoxWidth())*int(tt.char.GetBoundingBoxHeight()))
+			ratio := ComputeFillRatioExcludingHoles(tt.char)
+
+			if ratio < tt.minimum || ratio > 1 {
+				t.Errorf("fill ratio = %v, want in [%v, 1]", ratio, tt.minimum)
+			}
+			if differs := ratio-density > 0.1; differs != tt.holes {
+				t.Errorf("fill ratio %v against density %v, want them to differ %v", ratio, density, tt.holes)
+			}
+		})
+	}
+}
+
+func TestComputeTopologyHashShortSignature(t *testing.T) {
+	for _, signature := range []string{"", "0101", "0000000000000000"} {
+		if hash := ComputeTopologyHash(0, 0, 0, "", signature); hash == "" {
+			t.Errorf("empty hash for grid signature %q", signature)
+		}
+	}
+	if ComputeTopologyHash(0, 0, 0, "", "") == ComputeTopologyHash(0, 0, 0, "", "0101") {
+		t.Errorf("empty and short grid signatures hash the same")
+	}
+}
+
+func TestComputeSymmetryAxis(t *testing.T) {
+	// An equilateral triangle with its apex up, each row centered on column 30
+	triangle := character.NewCharacter(60, 60, nil)
+	for y := 8; y < 52; y++ {
+		half := int(float64(y-8) * math.Tan(math.Pi/6))
+		for x := 30 - half; x <= 30+half; x++ {
+			triangle.Draw(uint16(x), uint16(y))
+		}
+	}
+
+	angle, score := ComputeSymmetryAxis(triangle)
+	if math.Abs(angle-math.Pi/2) > math.Pi/90 {
+		t.Errorf("Expected a vertical symmetry axis for the triangle, got %.1f degrees", angle*180/math.Pi)
+	}
+	if score < 0.95 {
+		t.Errorf("Expected the triangle to mirror onto itself, got score %v", score)
+	}
+
+	// An 'F' has no mirror axis
+	f := character.NewCharacter(40, 40, nil)
+	for y := uint16(5); y < 35; y++ {
+		for x := uint16(8); x < 13; x++ {
+			f.Draw(x, y)
+		}
+	}
+	for x := uint16(13); x < 32; x++ {
+		for y := uint16(5); y < 10; y++ {
+			f.Draw(x, y)
+		}
+		if x < 26 {
+			for y := uint16(17); y < 22; y++ {
+				f.Draw(x, y)
+			}
+		}
+	}
+	if _, fScore := ComputeSymmetryAxis(f); fScore >= score-0.2 {
+		t.Errorf("Expected the F to score well below the triangle's %v, got %v", score, fScore)
+	}
+
+	if _, empty := ComputeSymmetryAxis(character.NewCharacter(10, 10, nil)); empty != 0 {
+		t.Errorf("Expected an empty character to score 0, got %v", empty)
+	}
+}
+
+func TestComputeZoningFeaturesPadding(t *testing.T) {
+	// An L filling a 16x16 box, once as a tight crop and once padded off center on a generated style canvas
+	drawL := func(sizeX, sizeY uint16, offsetX, offsetY int) *character.Character {
+		char := character.NewCharacter(sizeX, sizeY, nil)
+		for i := 0; i < 16; i++ {
+			for w := 0; w < 3; w++ {
+				char.Draw(uint16(offsetX+w), uint16(offsetY+i))
+				if w < 2 || i >= 3 {
+					char.Draw(uint16(offsetX+i), uint16(offsetY+15-w))
+				}
+			}
+		}
+		return char
+	}
+
+	tight := ComputeZoningFeatures(drawL(16, 16, 0, 0))
+	padded := ComputeZoningFeatures(drawL(64, 64, 30, 12))
+	for i := range tight {
+		if math.Abs(tight[i]-padded[i]) > 1e-9 {
+			t.Fatalf("zone %d = %v padded, want %v as for the tight crop", i, padded[i], tight[i])
+		}
+	}
+
+	// The stem fills the left column of zones and the foot the bottom row
+	if tight[0] == 0 || tight[15] == 0 || tight[3] != 0 {
+		t.Errorf("zoning = %v, want ink top left and bottom right but none top right", tight)
+	}
+
+	if zoning := ComputeZoningFeatures(character.NewCharacter(10, 10, nil)); zoning != [16]float64{} {
+		t.Errorf("expected no zoning for an empty character, got %v", zoning)
+	}
+}
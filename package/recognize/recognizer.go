@@ -1,42 +1,259 @@
 package recognize
 
 import (
+	"fmt"
 	"math"
 	"sort"
+	"strings"
 
+	"github.com/bsthun/glyphcanvas/package/character"
+	characterCalculate "github.com/bsthun/glyphcanvas/package/character/calculate"
 	"github.com/bsthun/glyphcanvas/package/recognize/helper"
 )
 
+func Recognize(char *character.Character, database *FeatureDatabase) ([]RecognitionCandidate, error) {
+	return RecognizeWithOptions(char, database, ExtractOptions{}, RecognizeOptions{})
+}
+
+// RecognizeWithOptions extracts the features of char with extractOpts and ranks the database against them. Every
+// angle of opts.RotationAngles matches the glyph once more turned by that angle, each entry keeps its smallest distance
+// so a hand tilted scan still finds its upright exemplar.
+func RecognizeWithOptions(char *character.Character, database *FeatureDatabase, extractOpts ExtractOptions, opts RecognizeOptions) ([]RecognitionCandidate, error) {
+	if char == nil {
+		return nil, fmt.Errorf("character is nil: %w", character.ErrEmptyCharacter)
+	}
+	// Every entry would score against the empty feature set, a blank crop has nothing to recognize
+	if char.IsEmpty() {
+		return nil, fmt.Errorf("character is blank: %w", character.ErrEmptyCharacter)
+	}
+	if database == nil || len(database.Characters) == 0 {
+		return nil, ErrEmptyDatabase
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	features, err := ExtractFeaturesWithOptions(char, extractOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract features: %w", err)
+	}
+	candidates := RecognizeCharacterWithOptions(features, database, opts)
+
+	for _, angle := range opts.RotationAngles {
+		if angle == 0 {
+			continue
+		}
+		rotated, err := ExtractFeaturesWithOptions(characterCalculate.CharacterRotate(char, angle), extractOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract features rotated by %g degrees: %w", angle, err)
+		}
+		candidates = mergeCandidates(candidates, RecognizeCharacterWithOptions(rotated, database, opts))
+	}
+
+	return candidates, nil
+}
+
+// CharacterSimilarity extracts features for both characters and returns 1 - distance between them, floored at 0
+func CharacterSimilarity(a, b *character.Character) (float64, error) {
+	if a == nil || b == nil {
+		return 0, fmt.Errorf("character is nil: %w", character.ErrEmptyCharacter)
+	}
+
+	featuresA, err := ExtractFeatures(a)
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract features: %w", err)
+	}
+	featuresB, err := ExtractFeatures(b)
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract features: %w", err)
+	}
+
+	return math.Max(0, 1-computeFeatureDistance(featuresA, featuresB)), nil
+}
+
+type RecognizeOptions struct {
+	ExactMatch      bool      // Return database entries with identical topology hash and grid signature as definitive matches, skipping the full distance
+	AspectTolerance float64   // Skip database entries whose aspect ratio is more than this factor wider or taller than the glyph, 0 compares every entry
+	RotationAngles  []float64 // Also match the glyph turned by each angle in degrees, see RecognizeWithOptions. Empty matches it as is only
+	MinEvidence     float64   // Weight of feature terms both glyphs must carry for full confidence, less caps it in proportion. 0 uses DefaultMinEvidence, negative never caps
+}
+
+// Validate reports an option that would make recognition silently return nothing
+func (opts RecognizeOptions) Validate() error {
+	// A ratio of the wider to the narrower aspect is at least 1, a smaller tolerance would reject every entry
+	if opts.AspectTolerance != 0 && !(opts.AspectTolerance >= 1) {
+		return fmt.Errorf("aspectTolerance must be 0 or at least 1, got %g", opts.AspectTolerance)
+	}
+	return nil
+}
+
+// DefaultMinEvidence is the RecognizeOptions.MinEvidence used when it is 0, a little under half the weight of all terms.
+// Glyphs whose features are mostly blank agree on every blank term and would otherwise look like near certain matches.
+const DefaultMinEvidence = 0.5
+
 func RecognizeCharacter(features *CharacterFeature, database *FeatureDatabase) []RecognitionCandidate {
+	return RecognizeCharacterWithOptions(features, database, RecognizeOptions{})
+}
+
+func RecognizeCharacterWithOptions(features *CharacterFeature, database *FeatureDatabase, opts RecognizeOptions) []RecognitionCandidate {
+	if opts.ExactMatch {
+		if candidates := findExactMatches(features, database); len(candidates) > 0 {
+			return candidates
+		}
+	}
+
+	minEvidence := opts.MinEvidence
+	if minEvidence == 0 {
+		minEvidence = DefaultMinEvidence
+	}
+
 	var candidates []RecognitionCandidate
 
 	for unicode, dbFeatures := range database.Characters {
-		distance := computeFeatureDistance(features, dbFeatures)
+		if opts.AspectTolerance > 0 && !aspectCompatible(features.AspectRatio, dbFeatures.AspectRatio, opts.AspectTolerance) {
+			continue
+		}
+
+		distance, evidence := computeFeatureDistanceEvidence(features, dbFeatures)
 		candidates = append(candidates, RecognitionCandidate{
-			Unicode:  unicode,
-			Distance: distance,
+			Unicode:    unicode,
+			Confidence: candidateConfidence(distance, evidence, minEvidence),
+			Distance:   distance,
 		})
 	}
 
+	sortCandidates(candidates)
+
+	return candidates
+}
+
+// candidateConfidence turns a distance into a percentage, capped at the share of minEvidence the evidence reaches
+func candidateConfidence(distance, evidence, minEvidence float64) float64 {
+	confidence := math.Max(0, (1.0-distance)*100)
+	if minEvidence > 0 && evidence < minEvidence {
+		confidence = math.Min(confidence, 100*evidence/minEvidence)
+	}
+	return confidence
+}
+
+// sortCandidates orders candidates by distance
+func sortCandidates(candidates []RecognitionCandidate) {
+	// Equal distances fall back on the key so ties do not depend on map iteration order
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].Distance < candidates[j].Distance
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		return candidates[i].Unicode < candidates[j].Unicode
 	})
+}
 
-	// Add confidence scores
-	for i := range candidates {
-		candidates[i].Confidence = (1.0 - candidates[i].Distance) * 100
-		if candidates[i].Confidence < 0 {
-			candidates[i].Confidence = 0
+// mergeCandidates combines two rankings of the same database, an entry in both keeps its smaller distance
+func mergeCandidates(a, b []RecognitionCandidate) []RecognitionCandidate {
+	index := make(map[string]int, len(a))
+	merged := append([]RecognitionCandidate(nil), a...)
+	for i, candidate := range merged {
+		index[candidate.Unicode] = i
+	}
+	for _, candidate := range b {
+		if i, ok := index[candidate.Unicode]; !ok {
+			index[candidate.Unicode] = len(merged)
+			merged = append(merged, candidate)
+		} else if candidate.Distance < merged[i].Distance {
+			merged[i] = candidate
 		}
 	}
 
+	sortCandidates(merged)
+	return merged
+}
+
+// aspectCompatible reports whether the wider of the two aspect ratios is at most tolerance times the other, a ratio
+// that is not positive and finite carries no shape and never excludes an entry
+func aspectCompatible(a, b, tolerance float64) bool {
+	if !(a > 0) || !(b > 0) || !isFinite(a) || !isFinite(b) {
+		return true
+	}
+	return math.Max(a, b)/math.Min(a, b) <= tolerance
+}
+
+func findExactMatches(features *CharacterFeature, database *FeatureDatabase) []RecognitionCandidate {
+	if features.TopologyHash == "" || features.GridSignature == "" {
+		return nil
+	}
+
+	var candidates []RecognitionCandidate
+	for unicode, dbFeatures := range database.Characters {
+		if dbFeatures.TopologyHash == features.TopologyHash && dbFeatures.GridSignature == features.GridSignature {
+			candidates = append(candidates, RecognitionCandidate{
+				Unicode:    unicode,
+				Confidence: 100,
+			})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Unicode < candidates[j].Unicode
+	})
+
 	return candidates
 }
 
+type distanceTerm struct {
+	name     string
+	value    float64
+	weight   float64
+	evidence bool // Both glyphs carry the feature, two blank features agree without telling the glyphs apart
+}
+
 func computeFeatureDistance(f1, f2 *CharacterFeature) float64 {
-	distance := 0.0
+	distance, _ := computeFeatureDistanceEvidence(f1, f2)
+	return distance
+}
+
+// computeFeatureDistanceEvidence returns the weighted distance between f1 and f2 together with the summed weight of the
+// terms backed by a feature on both sides
+func computeFeatureDistanceEvidence(f1, f2 *CharacterFeature) (float64, float64) {
+	terms, weight := computeFeatureTerms(f1, f2)
+	if weight <= 0 {
+		return 1.0, 0
+	}
+
+	distance, evidence := 0.0, 0.0
+	for _, term := range terms {
+		distance += term.value
+		if term.evidence {
+			evidence += term.weight
+		}
+	}
+	return distance / weight, evidence
+}
+
+// ExplainDistance returns each weighted feature term's share of the distance between f1 and f2, the values sum to the total distance
+func ExplainDistance(f1, f2 *CharacterFeature) map[string]float64 {
+	terms, weight := computeFeatureTerms(f1, f2)
+	contributions := make(map[string]float64, len(terms))
+	for _, term := range terms {
+		if weight > 0 {
+			contributions[term.name] = term.value / weight
+		}
+	}
+	return contributions
+}
+
+func computeFeatureTerms(f1, f2 *CharacterFeature) ([]distanceTerm, float64) {
+	var terms []distanceTerm
 	weight := 0.0
 
+	// addTerm weighs a normalized difference, a NaN or infinite one counts as completely different instead of poisoning the sum
+	addTerm := func(name string, difference, termWeight float64, evidence bool) {
+		if !isFinite(difference) {
+			difference = 1
+		}
+		terms = append(terms, distanceTerm{name, difference * termWeight, termWeight, evidence})
+		weight += termWeight
+	}
+	moments := hasHuMoments(f1.HuMoments) && hasHuMoments(f2.HuMoments)
+
 	// Grid signature distance (Hamming distance normalized)
 	if len(f1.GridSignature) == len(f2.GridSignature) {
 		hamming := 0.0
@@ -45,8 +262,7 @@ func computeFeatureDistance(f1, f2 *CharacterFeature) float64 {
 				hamming++
 			}
 		}
-		distance += (hamming / float64(len(f1.GridSignature))) * 0.15
-		weight += 0.15
+		addTerm("grid_signature", hamming/float64(len(f1.GridSignature)), 0.15, strings.Contains(f1.GridSignature, "1") && strings.Contains(f2.GridSignature, "1"))
 	}
 
 	// Direction histogram distance (Euclidean)
@@ -55,8 +271,7 @@ func computeFeatureDistance(f1, f2 *CharacterFeature) float64 {
 		diff := f1.DirectionHist[i] - f2.DirectionHist[i]
 		dirDistance += diff * diff
 	}
-	distance += math.Sqrt(dirDistance) * 0.12
-	weight += 0.12
+	addTerm("direction_histogram", math.Sqrt(dirDistance), 0.12, hasValues(f1.DirectionHist[:]) && hasValues(f2.DirectionHist[:]))
 
 	// Zoning features distance
 	zoneDistance := 0.0
@@ -64,38 +279,64 @@ func computeFeatureDistance(f1, f2 *CharacterFeature) float64 {
 		diff := f1.ZoningFeatures[i] - f2.ZoningFeatures[i]
 		zoneDistance += diff * diff
 	}
-	distance += math.Sqrt(zoneDistance) * 0.10
-	weight += 0.10
+	addTerm("zoning", math.Sqrt(zoneDistance), 0.10, hasValues(f1.ZoningFeatures[:]) && hasValues(f2.ZoningFeatures[:]))
 
 	// Hu moments distance
 	huDistance := 0.0
 	for i := 0; i < 7; i++ {
+		if !isFinite(f1.HuMoments[i]) || !isFinite(f2.HuMoments[i]) {
+			huDistance += huMaxLogDiff * huMaxLogDiff
+			continue
+		}
 		if math.Abs(f1.HuMoments[i]) > 1e-15 && math.Abs(f2.HuMoments[i]) > 1e-15 {
 			logDiff := math.Log10(math.Abs(f1.HuMoments[i])) - math.Log10(math.Abs(f2.HuMoments[i]))
 			huDistance += logDiff * logDiff
 		}
 	}
-	distance += math.Sqrt(huDistance) * 0.15
-	weight += 0.15
+	addTerm("hu_moments", math.Sqrt(huDistance), 0.15, moments)
 
 	// Aspect ratio distance
 	aspectDiff := math.Abs(f1.AspectRatio - f2.AspectRatio)
-	distance += aspectDiff * 0.08
-	weight += 0.08
+	addTerm("aspect_ratio", aspectDiff, 0.08, f1.AspectRatio > 0 && f2.AspectRatio > 0)
 
 	// Density distance
 	densityDiff := math.Abs(f1.Density - f2.Density)
-	distance += densityDiff * 0.08
-	weight += 0.08
+	addTerm("density", densityDiff, 0.08, f1.Density > 0 && f2.Density > 0)
 
 	// Center of mass distance
 	comDistance := math.Sqrt(math.Pow(f1.CenterOfMass[0]-f2.CenterOfMass[0], 2) +
 		math.Pow(f1.CenterOfMass[1]-f2.CenterOfMass[1], 2))
-	distance += comDistance * 0.05
-	weight += 0.05
+	addTerm("center_of_mass", comDistance, 0.05, hasValues(f1.CenterOfMass[:]) && hasValues(f2.CenterOfMass[:]))
+
+	// Elongation and eccentricity separate strokes like 'I' from round glyphs like 'O'
+	addTerm("elongation", math.Abs(f1.Elongation-f2.Elongation), 0.06, moments)
+	addTerm("eccentricity", math.Abs(f1.Eccentricity-f2.Eccentricity), 0.04, moments)
+
+	// The mirror axis only means something when both glyphs are symmetric, skipped for databases extracted before it existed
+	if f1.SymmetryScore > 0 && f2.SymmetryScore > 0 {
+		axisDifference := math.Mod(math.Abs(f1.SymmetryAxis-f2.SymmetryAxis), math.Pi)
+		axisDifference = math.Min(axisDifference, math.Pi-axisDifference) / (math.Pi / 2)
+		symmetryDistance := math.Abs(f1.SymmetryScore-f2.SymmetryScore) + math.Min(f1.SymmetryScore, f2.SymmetryScore)*axisDifference
+		addTerm("symmetry", symmetryDistance/2, 0.05, true)
+	}
+
+	// Skeleton stroke orientations, skipped for databases extracted before the feature existed
+	if len(f1.Directions) > 0 && len(f1.Directions) == len(f2.Directions) {
+		directionDistance := 0.0
+		for i := range f1.Directions {
+			directionDistance += math.Abs(f1.Directions[i] - f2.Directions[i])
+		}
+		addTerm("dominant_directions", directionDistance/2, 0.06, hasValues(f1.Directions) && hasValues(f2.Directions))
+	}
 
-	// Topology distance (endpoints, junctions, regions)
-	topologyDistance := 0.0
+	// Connected components separate 'i' and 'j' from 'l', skipped for databases extracted before the feature existed
+	if f1.ComponentCount > 0 && f2.ComponentCount > 0 {
+		addTerm("components", computeComponentsDistance(f1, f2), 0.08, true)
+	}
+
+	// Topology distance (endpoints, junctions, regions, strokes), the mean of the compared counts so that comparing
+	// stroke counts does not make the term weigh more than for a database without them
+	topologyDistance, topologyParts := 0.0, 3.0
 	if f1.EndPoints+f2.EndPoints > 0 {
 		topologyDistance += math.Abs(float64(f1.EndPoints-f2.EndPoints)) / float64(f1.EndPoints+f2.EndPoints+1)
 	}
@@ -105,63 +346,163 @@ func computeFeatureDistance(f1, f2 *CharacterFeature) float64 {
 	if f1.RegionCount+f2.RegionCount > 0 {
 		topologyDistance += math.Abs(float64(f1.RegionCount-f2.RegionCount)) / float64(f1.RegionCount+f2.RegionCount+1)
 	}
-	distance += topologyDistance * 0.12
-	weight += 0.12
+	// Databases extracted before stroke counts have none to compare
+	if f1.StrokeCount > 0 && f2.StrokeCount > 0 {
+		topologyDistance += math.Abs(float64(f1.StrokeCount-f2.StrokeCount)) / float64(f1.StrokeCount+f2.StrokeCount+1)
+		topologyParts++
+	}
+	addTerm("topology", topologyDistance/topologyParts, 0.12, f1.EndPoints+f1.Junctions+f1.RegionCount > 0 && f2.EndPoints+f2.Junctions+f2.RegionCount > 0)
 
 	// Region features distance
 	regionDistance := computeRegionFeaturesDistance(f1.RegionFeatures, f2.RegionFeatures)
-	distance += regionDistance * 0.10
-	weight += 0.10
+	addTerm("regions", regionDistance, 0.10, len(f1.RegionFeatures) > 0 && len(f2.RegionFeatures) > 0)
 
 	// Chain code similarity (Levenshtein distance normalized)
 	if len(f1.ChainCode) > 0 && len(f2.ChainCode) > 0 {
 		chainDistance := float64(helper.LevenshteinDistance(f1.ChainCode, f2.ChainCode)) /
 			float64(math.Max(float64(len(f1.ChainCode)), float64(len(f2.ChainCode))))
-		distance += chainDistance * 0.05
-		weight += 0.05
+		addTerm("chain_code", chainDistance, 0.05, true)
 	}
 
-	if weight > 0 {
-		return distance / weight
+	return terms, weight
+}
+
+const (
+	huMaxLogDiff      = 15.0 // Penalty for a Hu moment that is NaN or infinite, the whole range the 1e-15 cutoff allows
+	regionMaxDistance = 1.0  // Replaces a region comparison that is NaN or infinite
+)
+
+func hasHuMoments(hu [7]float64) bool {
+	for _, moment := range hu {
+		if moment != 0 {
+			return true
+		}
 	}
-	return 1.0
+	return false
 }
 
-func computeRegionFeaturesDistance(r1, r2 []RegionFeatureSet) float64 {
-	if len(r1) == 0 && len(r2) == 0 {
-		return 0.0
+func hasValues(values []float64) bool {
+	for _, value := range values {
+		if value != 0 {
+			return true
+		}
 	}
-	if len(r1) == 0 || len(r2) == 0 {
-		return 1.0
+	return false
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// computeComponentsDistance averages the difference in component count with the size and position differences of the
+// components both glyphs have, compared largest to largest
+func computeComponentsDistance(f1, f2 *CharacterFeature) float64 {
+	countDistance := math.Abs(float64(f1.ComponentCount-f2.ComponentCount)) / float64(f1.ComponentCount+f2.ComponentCount)
+
+	pairs := max(len(f1.Components), len(f2.Components))
+	if pairs == 0 {
+		return countDistance
+	}
+	layoutDistance := float64(pairs - min(len(f1.Components), len(f2.Components)))
+	for i := 0; i < min(len(f1.Components), len(f2.Components)); i++ {
+		c1, c2 := f1.Components[i], f2.Components[i]
+		position := math.Hypot(c1.RelativePos[0]-c2.RelativePos[0], c1.RelativePos[1]-c2.RelativePos[1])
+		layoutDistance += math.Min(1, (math.Abs(c1.RelativeSize-c2.RelativeSize)+position)/2)
+	}
+
+	return (countDistance + layoutDistance/float64(pairs)) / 2
+}
+
+func computeRegionFeaturesDistance(r1, r2 []RegionFeatureSet) float64 {
+	_, distance := MatchRegions(r1, r2)
+	return distance
+}
+
+// MatchRegions pairs the query regions a with the reference regions b one to one and returns every pair along with
+// the region distance used in recognition. Each region of both sides appears in exactly one match
+func MatchRegions(a, b []RegionFeatureSet) ([]RegionMatch, float64) {
+	if len(a) == 0 && len(b) == 0 {
+		return nil, 0.0
 	}
 
-	// Use Hungarian algorithm approximation: match each region in r1 to closest in r2
+	var matches []RegionMatch
+	matched := make([]bool, len(b))
 	totalDistance := 0.0
-	count := math.Min(float64(len(r1)), float64(len(r2)))
-
-	for i := 0; i < int(count); i++ {
-		minDist := math.Inf(1)
-		for j := 0; j < len(r2); j++ {
-			dist := computeSingleRegionDistance(r1[i], r2[j])
-			if dist < minDist {
-				minDist = dist
-			}
+	for i, j := range matchRegionFeatures(a, b) {
+		if j < 0 {
+			matches = append(matches, RegionMatch{Query: i, Reference: -1})
+			continue
+		}
+		// Each region pairs with a distinct region on the other side, relative position is part of the pair distance
+		distance := computeSingleRegionDistance(a[i], b[j])
+		matches = append(matches, RegionMatch{Query: i, Reference: j, Distance: distance})
+		matched[j] = true
+		totalDistance += distance
+	}
+	for j := range b {
+		if !matched[j] {
+			matches = append(matches, RegionMatch{Query: -1, Reference: j})
 		}
-		totalDistance += minDist
 	}
 
+	if len(a) == 0 || len(b) == 0 {
+		return matches, 1.0
+	}
+	count := float64(min(len(a), len(b)))
+
 	// Penalty for different region counts
-	countPenalty := math.Abs(float64(len(r1)-len(r2))) / float64(len(r1)+len(r2))
+	countPenalty := math.Abs(float64(len(a)-len(b))) / float64(len(a)+len(b))
 
-	return (totalDistance/count + countPenalty) / 2.0
+	return matches, (totalDistance/count + countPenalty) / 2.0
+}
+
+// matchRegionFeatures pairs regions one to one with the least total distance, returning the r2 index matched to each
+// r1 region or -1 for the regions left over when r1 has more
+func matchRegionFeatures(r1, r2 []RegionFeatureSet) []int {
+	transposed := len(r1) > len(r2)
+	rows, columns := r1, r2
+	if transposed {
+		rows, columns = r2, r1
+	}
+
+	cost := make([][]float64, len(rows))
+	for i := range rows {
+		cost[i] = make([]float64, len(columns))
+		for j := range columns {
+			if transposed {
+				cost[i][j] = computeSingleRegionDistance(columns[j], rows[i])
+			} else {
+				cost[i][j] = computeSingleRegionDistance(rows[i], columns[j])
+			}
+		}
+	}
+	assignment := hungarianAssignment(cost)
+
+	if !transposed {
+		return assignment
+	}
+	matches := make([]int, len(r1))
+	for i := range matches {
+		matches[i] = -1
+	}
+	for row, column := range assignment {
+		matches[column] = row
+	}
+	return matches
 }
 
 func computeSingleRegionDistance(r1, r2 RegionFeatureSet) float64 {
 	distance := 0.0
 
-	// Arc type (categorical)
+	// Arc type (categorical), an uncertain classification on either side weighs less
 	if r1.ArcType != r2.ArcType {
-		distance += 0.3
+		confidence := math.Min(r1.ArcConfidence, r2.ArcConfidence)
+		distance += 0.3 * (0.5 + 0.5*confidence)
+	}
+
+	// Nested structure, a ring and a ring with a bar across look alike in every moment based measure
+	if r1.Holes != r2.Holes {
+		distance += 0.1
 	}
 
 	// Circularity
@@ -173,13 +514,22 @@ func computeSingleRegionDistance(r1, r2 RegionFeatureSet) float64 {
 	// Curve strength
 	distance += math.Abs(r1.CurveStrength-r2.CurveStrength) * 0.1
 
-	// Hu moments
-	huDist := 0.0
-	for i := 0; i < 7; i++ {
-		diff := r1.HuMoments[i] - r2.HuMoments[i]
-		huDist += diff * diff
+	// Hu moments, all zero means they were never computed or the region was degenerate so there is nothing to compare
+	if hasHuMoments(r1.HuMoments) && hasHuMoments(r2.HuMoments) {
+		huDist := 0.0
+		for i := 0; i < 7; i++ {
+			diff := r1.HuMoments[i] - r2.HuMoments[i]
+			huDist += diff * diff
+		}
+		distance += math.Sqrt(huDist) * 0.1
 	}
-	distance += math.Sqrt(huDist) * 0.1
+
+	// Chain code direction histogram, half the L1 distance keeps it in [0, 1]
+	histDist := 0.0
+	for i := 0; i < 8; i++ {
+		histDist += math.Abs(r1.ChainCodeHist[i] - r2.ChainCodeHist[i])
+	}
+	distance += histDist / 2 * 0.1
 
 	// Relative size
 	distance += math.Abs(r1.RelativeSize-r2.RelativeSize) * 0.05
@@ -189,5 +539,8 @@ func computeSingleRegionDistance(r1, r2 RegionFeatureSet) float64 {
 		math.Pow(r1.RelativePos[1]-r2.RelativePos[1], 2))
 	distance += posDistance * 0.05
 
+	if !isFinite(distance) {
+		return regionMaxDistance
+	}
 	return distance
 }
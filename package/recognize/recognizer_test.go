@@ -0,0 +1,539 @@
+package recognize
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+	"github.com/bsthun/glyphcanvas/test"
+)
+
+func TestRecognize(t *testing.T) {
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{}}
+	for unicode, text := range map[string]string{"0041": "A", "004F": "O", "0049": "I", "0058": "X"} {
+		features, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{text}, 3)))
+		if err != nil {
+			t.Fatalf("ExtractFeatures(%q) failed: %v", text, err)
+		}
+		features.Unicode = unicode
+		database.Characters[unicode] = features
+	}
+
+	candidates, err := Recognize(test.CharacterFromImage(test.RenderText([]string{"A"}, 4)), database)
+	if err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if len(candidates) != len(database.Characters) {
+		t.Fatalf("candidates = %d, want %d", len(candidates), len(database.Characters))
+	}
+	if candidates[0].Unicode != "0041" {
+		t.Errorf("top candidate = %s, want 0041", candidates[0].Unicode)
+	}
+
+	if _, err := Recognize(nil, database); err == nil {
+		t.Errorf("expected error for nil character")
+	}
+	if _, err := Recognize(test.CharacterFromImage(test.RenderText([]string{"A"}, 1)), &FeatureDatabase{}); err == nil {
+		t.Errorf("expected error for empty database")
+	}
+}
+
+func TestExplainDistance(t *testing.T) {
+	a, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	o, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"O"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+
+	contributions := ExplainDistance(a, o)
+	if len(contributions) == 0 {
+		t.Fatalf("expected feature contributions")
+	}
+
+	sum := 0.0
+	for name, value := range contributions {
+		if value < 0 {
+			t.Errorf("contribution %s = %v, want non-negative", name, value)
+		}
+		sum += value
+	}
+	if total := computeFeatureDistance(a, o); math.Abs(sum-total) > 1e-12 {
+		t.Errorf("contributions sum to %v, want total distance %v", sum, total)
+	}
+
+	for name, value := range ExplainDistance(a, a) {
+		if value > 1e-12 {
+			t.Errorf("self distance contribution %s = %v, want 0", name, value)
+		}
+	}
+}
+
+func TestTopologyDistanceNormalized(t *testing.T) {
+	topology := func(f1, f2 *CharacterFeature) float64 {
+		terms, _ := computeFeatureTerms(f1, f2)
+		for _, term := range terms {
+			if term.name == "topology" {
+				return term.value / term.weight
+			}
+		}
+		t.Fatalf("expected a topology term")
+		return 0
+	}
+
+	// Every count as far apart as it gets still stays within the range of the other terms
+	apart := topology(
+		&CharacterFeature{EndPoints: 100, RegionCount: 100, StrokeCount: 100},
+		&CharacterFeature{Junctions: 100, RegionCount: 1, StrokeCount: 1},
+	)
+	if apart > 1 {
+		t.Errorf("topology distance = %v, want at most 1", apart)
+	}
+
+	// Agreeing stroke counts dilute a difference instead of adding to it
+	without := topology(&CharacterFeature{EndPoints: 4, RegionCount: 2}, &CharacterFeature{EndPoints: 2, RegionCount: 2})
+	with := topology(&CharacterFeature{EndPoints: 4, RegionCount: 2, StrokeCount: 3}, &CharacterFeature{EndPoints: 2, RegionCount: 2, StrokeCount: 3})
+	if math.Abs(with-without*3/4) > 1e-12 {
+		t.Errorf("topology distance with equal stroke counts = %v, want 3/4 of %v", with, without)
+	}
+}
+
+func TestCharacterSimilarity(t *testing.T) {
+	render := func(text string, scale int) *character.Character {
+		return test.CharacterFromImage(test.RenderText([]string{text}, scale))
+	}
+
+	same, err := CharacterSimilarity(render("A", 3), render("A", 5))
+	if err != nil {
+		t.Fatalf("CharacterSimilarity failed: %v", err)
+	}
+	different, err := CharacterSimilarity(render("A", 3), render("X", 3))
+	if err != nil {
+		t.Fatalf("CharacterSimilarity failed: %v", err)
+	}
+	if same <= different {
+		t.Errorf("similarity of two A = %v, want above A to X %v", same, different)
+	}
+
+	if _, err := CharacterSimilarity(nil, render("A", 3)); err == nil {
+		t.Errorf("expected error for nil character")
+	}
+}
+
+func TestRecognizeCharacterExactMatch(t *testing.T) {
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{}}
+	for unicode, text := range map[string]string{"0041": "A", "004F": "O", "0058": "X"} {
+		features, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{text}, 3)))
+		if err != nil {
+			t.Fatalf("ExtractFeatures(%q) failed: %v", text, err)
+		}
+		features.Unicode = unicode
+		database.Characters[unicode] = features
+	}
+
+	duplicate, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"O"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+
+	candidates := RecognizeCharacterWithOptions(duplicate, database, RecognizeOptions{ExactMatch: true})
+	if len(candidates) != 1 {
+		t.Fatalf("candidates = %d, want the single exact match", len(candidates))
+	}
+	if candidates[0].Unicode != "004F" || candidates[0].Confidence != 100 || candidates[0].Distance != 0 {
+		t.Errorf("exact match = %+v, want 004F at 100%%", candidates[0])
+	}
+
+	// Without an exact match the full ranking is returned
+	other, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"Q"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	if candidates := RecognizeCharacterWithOptions(other, database, RecognizeOptions{ExactMatch: true}); len(candidates) != len(database.Characters) {
+		t.Errorf("candidates = %d, want full ranking of %d", len(candidates), len(database.Characters))
+	}
+	if candidates := RecognizeCharacter(duplicate, database); len(candidates) != len(database.Characters) {
+		t.Errorf("candidates = %d, want full ranking without the flag", len(candidates))
+	}
+}
+
+func TestRegionDistanceWeighsArcConfidence(t *testing.T) {
+	line := RegionFeatureSet{ArcType: "line", ArcConfidence: 1}
+	confidentCircle := RegionFeatureSet{ArcType: "circle", ArcConfidence: 1}
+	uncertainCircle := RegionFeatureSet{ArcType: "circle", ArcConfidence: 0.1}
+
+	confident := computeSingleRegionDistance(line, confidentCircle)
+	uncertain := computeSingleRegionDistance(line, uncertainCircle)
+	if uncertain >= confident {
+		t.Errorf("uncertain mismatch distance = %v, want below confident mismatch %v", uncertain, confident)
+	}
+	if uncertain <= 0 {
+		t.Errorf("uncertain mismatch distance = %v, want still positive", uncertain)
+	}
+}
+
+func TestFeatureDistanceNonFinite(t *testing.T) {
+	a, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+
+	// A single pixel glyph has zero central moments throughout
+	dot := character.NewCharacter(9, 9, nil)
+	dot.Draw(4, 4)
+	single, err := ExtractFeatures(dot)
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed on a single pixel: %v", err)
+	}
+	for i, hu := range single.HuMoments {
+		if math.IsNaN(hu) || math.IsInf(hu, 0) {
+			t.Errorf("single pixel Hu moment %d = %v", i+1, hu)
+		}
+	}
+	if distance := computeFeatureDistance(single, a); math.IsNaN(distance) || math.IsInf(distance, 0) {
+		t.Errorf("distance from a single pixel glyph = %v", distance)
+	}
+
+	// Invalid values loaded from a damaged database count as a full mismatch rather than NaN
+	poisoned := *a
+	poisoned.HuMoments[0] = math.NaN()
+	poisoned.Elongation = math.Inf(1)
+	poisoned.RegionFeatures = append([]RegionFeatureSet(nil), a.RegionFeatures...)
+	poisoned.RegionFeatures[0].HuMoments[0] = math.NaN()
+
+	distance := computeFeatureDistance(a, &poisoned)
+	if math.IsNaN(distance) || math.IsInf(distance, 0) || distance <= 0 {
+		t.Fatalf("distance to poisoned features = %v, want finite and positive", distance)
+	}
+	sum := 0.0
+	for _, value := range ExplainDistance(a, &poisoned) {
+		sum += value
+	}
+	if math.Abs(sum-distance) > 1e-12 {
+		t.Errorf("contributions sum to %v, want %v", sum, distance)
+	}
+
+	poisoned.Unicode = "0000"
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{"0000": &poisoned, "0041": a, "002E": single}}
+	candidates := RecognizeCharacter(a, database)
+	for i, candidate := range candidates {
+		if math.IsNaN(candidate.Distance) || (i > 0 && candidate.Distance < candidates[i-1].Distance) {
+			t.Errorf("candidates not ordered by finite distance: %+v", candidates)
+			break
+		}
+	}
+	if candidates[0].Unicode != "0041" {
+		t.Errorf("top candidate = %s, want 0041", candidates[0].Unicode)
+	}
+}
+
+func TestHungarianAssignment(t *testing.T) {
+	// Both of the first two rows are cheapest in column 1, only one of them can have it
+	cost := [][]float64{
+		{4, 1, 3},
+		{2, 0, 5},
+		{3, 2, 2},
+	}
+	if got := hungarianAssignment(cost); !slices.Equal(got, []int{1, 0, 2}) {
+		t.Errorf("assignment = %v, want [1 0 2]", got)
+	}
+
+	wide := [][]float64{
+		{5, 1, 9, 4},
+		{6, 2, 3, 8},
+	}
+	if got := hungarianAssignment(wide); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("rectangular assignment = %v, want [1 2]", got)
+	}
+}
+
+func TestRegionFeaturesDistanceOneToOne(t *testing.T) {
+	bar := RegionFeatureSet{ArcType: "line", ArcConfidence: 1, Linearity: 1, RelativePos: [2]float64{0.2, 0.5}}
+	ring := RegionFeatureSet{ArcType: "circle", ArcConfidence: 1, Circularity: 1, RelativePos: [2]float64{0.8, 0.5}}
+
+	// Nearest matching would pair both bars with the one bar on the other side and report a perfect match
+	distance := computeRegionFeaturesDistance([]RegionFeatureSet{bar, bar}, []RegionFeatureSet{bar, ring})
+	if want := computeSingleRegionDistance(bar, ring) / 4; distance <= 0 || math.Abs(distance-want) > 1e-12 {
+		t.Errorf("distance = %v, want %v with each region matched once", distance, want)
+	}
+
+	if got := matchRegionFeatures([]RegionFeatureSet{ring, bar, bar}, []RegionFeatureSet{bar, ring}); got[0] != 1 || got[1]+got[2] != -1 {
+		t.Errorf("matches = %v, want the ring on the ring and one bar left over", got)
+	}
+
+	left, right := bar, bar
+	right.RelativePos = [2]float64{0.8, 0.5}
+	if distance := computeRegionFeaturesDistance([]RegionFeatureSet{left, right}, []RegionFeatureSet{right, left}); distance != 0 {
+		t.Errorf("distance between the same regions listed in another order = %v, want 0", distance)
+	}
+}
+
+func TestMatchRegions(t *testing.T) {
+	a, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	b, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"B"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+
+	bar := RegionFeatureSet{ArcType: "line", ArcConfidence: 1, Linearity: 1}
+	cases := [][2][]RegionFeatureSet{
+		{a.RegionFeatures, b.RegionFeatures},
+		{b.RegionFeatures, a.RegionFeatures},
+		{a.RegionFeatures, append(append([]RegionFeatureSet(nil), a.RegionFeatures...), bar)},
+		{nil, []RegionFeatureSet{bar}},
+	}
+	for n, c := range cases {
+		matches, distance := MatchRegions(c[0], c[1])
+		if distance != computeRegionFeaturesDistance(c[0], c[1]) {
+			t.Errorf("case %d: distance = %v, want the recognition region distance", n, distance)
+		}
+
+		queries, references := map[int]int{}, map[int]int{}
+		for _, match := range matches {
+			if match.Query >= 0 {
+				queries[match.Query]++
+			}
+			if match.Reference >= 0 {
+				references[match.Reference]++
+			}
+			if (match.Query < 0 || match.Reference < 0) && match.Distance != 0 {
+				t.Errorf("case %d: unmatched region has distance %v", n, match.Distance)
+			}
+		}
+		for i := range c[0] {
+			if queries[i] != 1 {
+				t.Errorf("case %d: query region %d appears in %d matches, want 1", n, i, queries[i])
+			}
+		}
+		for j := range c[1] {
+			if references[j] != 1 {
+				t.Errorf("case %d: reference region %d appears in %d matches, want 1", n, j, references[j])
+			}
+		}
+		if len(matches) != max(len(c[0]), len(c[1])) {
+			t.Errorf("case %d: %d matches for %d and %d regions", n, len(matches), len(c[0]), len(c[1]))
+		}
+	}
+}
+
+func TestRecognizeAspectTolerance(t *testing.T) {
+	tall, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"I"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	other, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"W"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	tall.Unicode, other.Unicode = "0049", "0057"
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{"0049": tall, "0057": other}}
+
+	// Everything but the aspect ratio matches the tall reference
+	wide := *tall
+	wide.AspectRatio = 4 / tall.AspectRatio
+
+	if candidates := RecognizeCharacter(&wide, database); candidates[0].Unicode != "0049" {
+		t.Fatalf("top candidate without the gate = %s, want 0049 to show the other features outweigh the aspect", candidates[0].Unicode)
+	}
+
+	candidates := RecognizeCharacterWithOptions(&wide, database, RecognizeOptions{AspectTolerance: 2})
+	for _, candidate := range candidates {
+		if candidate.Unicode == "0049" {
+			t.Errorf("tall reference matched a glyph %.1f times wider", wide.AspectRatio/tall.AspectRatio)
+		}
+	}
+
+	if candidates := RecognizeCharacterWithOptions(tall, database, RecognizeOptions{AspectTolerance: 2}); len(candidates) == 0 || candidates[0].Unicode != "0049" {
+		t.Errorf("gate removed the matching reference, got %+v", candidates)
+	}
+
+	for _, tolerance := range []float64{0, 1, 2} {
+		if err := (RecognizeOptions{AspectTolerance: tolerance}).Validate(); err != nil {
+			t.Errorf("tolerance %g: unexpected error %v", tolerance, err)
+		}
+	}
+	for _, tolerance := range []float64{0.5, -1, math.NaN()} {
+		if err := (RecognizeOptions{AspectTolerance: tolerance}).Validate(); err == nil {
+			t.Errorf("tolerance %g: expected an error", tolerance)
+		}
+	}
+	if _, err := RecognizeWithOptions(test.CharacterFromImage(test.RenderText([]string{"I"}, 3)), database, ExtractOptions{}, RecognizeOptions{AspectTolerance: 0.5}); err == nil {
+		t.Errorf("expected RecognizeWithOptions to reject a tolerance below 1")
+	}
+}
+
+func TestRecognizeMultiCodepoint(t *testing.T) {
+	ligature, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"fi"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	single, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+
+	key := UnicodeKey("fi")
+	if key != "0066+0069" {
+		t.Fatalf("UnicodeKey(\"fi\") = %q, want 0066+0069", key)
+	}
+	ligature.Unicode, single.Unicode = key, "0041"
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{key: ligature, "0041": single}}
+	if problems := database.Validate(); len(problems) != 0 {
+		t.Fatalf("database with a two code point key is invalid: %v", problems)
+	}
+
+	query, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"fi"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	candidates := RecognizeCharacter(query, database)
+	if candidates[0].Unicode != key {
+		t.Fatalf("top candidate = %s, want %s", candidates[0].Unicode, key)
+	}
+	if text := UnicodeToString(candidates[0].Unicode); text != "fi" {
+		t.Errorf("UnicodeToString(%q) = %q, want \"fi\"", candidates[0].Unicode, text)
+	}
+
+	for key, want := range map[string]string{"0E01 0E33": "กำ", "0041": "A", "": "?", "0041+zz": "?"} {
+		if got := UnicodeToString(key); got != want {
+			t.Errorf("UnicodeToString(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestUnicodeKeyBeyondBMP(t *testing.T) {
+	for _, text := range []string{"😀", "𝔸", "A😀", "\U0010FFFF"} {
+		key := UnicodeKey(text)
+		if got := UnicodeToString(key); got != text {
+			t.Errorf("UnicodeToString(UnicodeKey(%q)) = %q via key %q", text, got, key)
+		}
+	}
+
+	if key := UnicodeKey("😀"); key != "1F600" {
+		t.Errorf("UnicodeKey(\"😀\") = %q, want 1F600", key)
+	}
+	for key, want := range map[string]string{"1F600": "😀", "1f600": "😀", "41": "A", "0041 1F600": "A😀", "D800": "?", "110000": "?"} {
+		if got := UnicodeToString(key); got != want {
+			t.Errorf("UnicodeToString(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	for key, want := range map[string]string{"41": "0041", "1f600": "1F600", "0041 1F600": "0041+1F600", "0E01+0e33": "0E01+0E33"} {
+		if got, err := CanonicalUnicodeKey(key); err != nil || got != want {
+			t.Errorf("CanonicalUnicodeKey(%q) = %q, %v, want %q", key, got, err, want)
+		}
+	}
+	if _, err := CanonicalUnicodeKey("0001F600"); err == nil {
+		t.Errorf("expected more than 6 hex digits to be rejected")
+	}
+}
+
+func TestRecognizeCharacterTieBreak(t *testing.T) {
+	features, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+
+	// The same glyph stored under several keys, as with identical font variants
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{}}
+	for _, key := range []string{"0410", "0041", "0391", "FF21"} {
+		database.Characters[key] = features
+	}
+
+	for run := 0; run < 20; run++ {
+		candidates := RecognizeCharacter(features, database)
+		var order []string
+		for _, candidate := range candidates {
+			order = append(order, candidate.Unicode)
+		}
+		if !slices.Equal(order, []string{"0041", "0391", "0410", "FF21"}) {
+			t.Fatalf("run %d: candidate order = %v, want equal distances ordered by key", run, order)
+		}
+	}
+}
+
+func TestRecognizeRotationSearch(t *testing.T) {
+	glyphs := map[string][][4]float64{
+		"A": {{-20, 25, 0, -25}, {20, 25, 0, -25}, {-10, 5, 10, 5}},
+		"V": {{-20, -25, 0, 25}, {20, -25, 0, 25}},
+		"Λ": {{-20, 25, 0, -25}, {20, 25, 0, -25}},
+		"Y": {{-20, -25, 0, 0}, {20, -25, 0, 0}, {0, 0, 0, 25}},
+		"P": {{-15, -25, -15, 25}, {-15, -25, 15, -25}, {15, -25, 15, 0}, {-15, 0, 15, 0}},
+	}
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{}}
+	for name, strokes := range glyphs {
+		features, err := ExtractFeatures(strokeGlyph(0, strokes))
+		if err != nil {
+			t.Fatalf("ExtractFeatures(%s) failed: %v", name, err)
+		}
+		database.Characters[name] = features
+	}
+
+	// Tilted 10 degrees counterclockwise on screen, as a hand placed scan would be
+	tilted := strokeGlyph(-10, glyphs["A"])
+	raw, err := RecognizeWithOptions(tilted, database, ExtractOptions{}, RecognizeOptions{})
+	if err != nil {
+		t.Fatalf("RecognizeWithOptions failed: %v", err)
+	}
+	searched, err := RecognizeWithOptions(tilted, database, ExtractOptions{}, RecognizeOptions{RotationAngles: []float64{-10, 10}})
+	if err != nil {
+		t.Fatalf("RecognizeWithOptions with rotations failed: %v", err)
+	}
+
+	if len(searched) != len(database.Characters) {
+		t.Fatalf("candidates = %d, want one per database entry %d", len(searched), len(database.Characters))
+	}
+	if searched[0].Unicode != "A" {
+		t.Fatalf("top candidate with rotation search = %s, want A", searched[0].Unicode)
+	}
+	if searched[0].Distance >= raw[0].Distance {
+		t.Errorf("distance to A with rotation search = %v, want below the unrotated %v", searched[0].Distance, raw[0].Distance)
+	}
+	for i := 1; i < len(searched); i++ {
+		if searched[i].Distance < searched[i-1].Distance {
+			t.Errorf("candidates out of order at %d: %v after %v", i, searched[i].Distance, searched[i-1].Distance)
+		}
+	}
+}
+
+func TestRecognizeSparseFeaturesConfidence(t *testing.T) {
+	// Little more than the box shape, every other term compares two blank features
+	blank := strings.Repeat("0", 64)
+	sparse := &CharacterFeature{GridSignature: blank, AspectRatio: 1, Density: 0.2}
+	database := &FeatureDatabase{Characters: map[string]*CharacterFeature{
+		"x": {GridSignature: blank, AspectRatio: 1.02, Density: 0.21},
+	}}
+
+	candidates := RecognizeCharacter(sparse, database)
+	if len(candidates) != 1 {
+		t.Fatalf("candidates = %d, want 1", len(candidates))
+	}
+	if candidates[0].Distance > 0.1 {
+		t.Fatalf("distance = %v, want the blank terms to agree", candidates[0].Distance)
+	}
+	if candidates[0].Confidence > 90 {
+		t.Errorf("confidence = %v for two sparse glyphs, want at most 90", candidates[0].Confidence)
+	}
+
+	uncapped := RecognizeCharacterWithOptions(sparse, database, RecognizeOptions{MinEvidence: -1})
+	if uncapped[0].Confidence <= 90 {
+		t.Errorf("confidence without the evidence floor = %v, want the near zero distance to show", uncapped[0].Confidence)
+	}
+
+	// A real glyph carries every feature and is not capped
+	features, err := ExtractFeatures(test.CharacterFromImage(test.RenderText([]string{"A"}, 3)))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	database.Characters["A"] = features
+	if candidates := RecognizeCharacter(features, database); candidates[0].Unicode != "A" || candidates[0].Confidence < 99 {
+		t.Errorf("top candidate = %+v, want A at full confidence", candidates[0])
+	}
+}
@@ -1,5 +1,7 @@
 package recognize
 
+import "github.com/bsthun/glyphcanvas/package/region"
+
 type CharacterFeature struct {
 	Unicode        string             `yaml:"unicode"`
 	GridSignature  string             `yaml:"grid_signature"`
@@ -9,27 +11,52 @@ type CharacterFeature struct {
 	HuMoments      [7]float64         `yaml:"hu_moments"`
 	AspectRatio    float64            `yaml:"aspect_ratio"`
 	Density        float64            `yaml:"density"`
+	FillRatio      float64            `yaml:"fill_ratio,omitempty"` // Density with enclosed holes left out of the box area
 	CenterOfMass   [2]float64         `yaml:"center_of_mass"`
+	Elongation     float64            `yaml:"elongation"`
+	Eccentricity   float64            `yaml:"eccentricity"`
+	SymmetryAxis   float64            `yaml:"symmetry_axis,omitempty"`  // Mirror axis angle in radians, π/2 is vertical, see helper.ComputeSymmetryAxis
+	SymmetryScore  float64            `yaml:"symmetry_score,omitempty"` // Share of the ink that mirrors onto ink across SymmetryAxis
+	Directions     []float64          `yaml:"dominant_directions"`
 	EndPoints      int                `yaml:"end_points"`
 	Junctions      int                `yaml:"junctions"`
+	StrokeCount    int                `yaml:"stroke_count,omitempty"` // Skeleton branches, 4 for '+', see characterHelper.CharacterEstimateStrokeCount
 	RegionCount    int                `yaml:"region_count"`
 	RegionFeatures []RegionFeatureSet `yaml:"region_features"`
+	ComponentCount int                `yaml:"component_count,omitempty"` // Connected pieces of ink, 2 for 'i' and 'j'
+	Components     []ComponentFeature `yaml:"components,omitempty"`      // Largest components first, at most MaxComponents
 	TopologyHash   string             `yaml:"topology_hash"`
 }
 
-type RegionFeatureSet struct {
-	ArcType       string     `yaml:"arc_type"`
-	Circularity   float64    `yaml:"circularity"`
-	Linearity     float64    `yaml:"linearity"`
-	CurveStrength float64    `yaml:"curve_strength"`
-	HuMoments     [7]float64 `yaml:"hu_moments"`
-	ChainCodeHash string     `yaml:"chain_code_hash"`
-	RelativeSize  float64    `yaml:"relative_size"`
-	RelativePos   [2]float64 `yaml:"relative_position"`
+// MaxComponents is how many of the largest connected components get a ComponentFeature
+const MaxComponents = 3
+
+// ComponentFeature is a connected piece of ink, its share of the glyph's ink and its centroid relative to the glyph's
+// bounding box, so the dot of an 'i' is small and sits above the stem
+type ComponentFeature struct {
+	RelativeSize float64    `yaml:"relative_size"`
+	RelativePos  [2]float64 `yaml:"relative_position"`
 }
 
+// RegionFeatureSet is kept here for callers that only deal with recognition, see region.RegionFeatureSet
+type RegionFeatureSet = region.RegionFeatureSet
+
+// DatabaseVersion is written by SaveDatabase, LoadDatabase rejects newer files.
+// Version 2 added the optional hex packed grid signatures, version 3 extracts on the normalized canvas, version 4
+// counts the holes of each region, version 5 measures the skeleton on a thinned medial axis, version 6 only
+// segments a region at a neck, version 7 names a round region a circle when its corners make no clean polygon and
+// version 8 finds junctions where strokes thin to a 2x2 block and stores stroke counts, version 9 anchors a
+// curvature plateau at its first point and version 10 scores line confidence on edge votes
+const DatabaseVersion = 10
+
+// MinDatabaseVersion is the oldest version whose features are still computed the way ExtractFeatures does,
+// LoadDatabase rejects older files so they get extracted again instead of silently mismatching
+const MinDatabaseVersion = 10
+
 type FeatureDatabase struct {
-	Characters map[string]*CharacterFeature `yaml:"characters"`
+	Version      int                          `yaml:"version"`
+	GridEncoding string                       `yaml:"grid_encoding,omitempty"` // "hex" when grid signatures are packed on disk, in memory they are always bit strings
+	Characters   map[string]*CharacterFeature `yaml:"characters"`
 }
 
 type RecognitionCandidate struct {
@@ -37,3 +64,11 @@ type RecognitionCandidate struct {
 	Confidence float64
 	Distance   float64
 }
+
+// RegionMatch pairs a query region with the reference region it was matched to, a region left over when the sides
+// have different counts has -1 on the other side and no distance
+type RegionMatch struct {
+	Query     int
+	Reference int
+	Distance  float64
+}
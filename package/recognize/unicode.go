@@ -0,0 +1,59 @@
+package recognize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// UnicodeToString converts a hex code point key from the database to its text, "?" when invalid. A ligature or
+// cluster key lists several code points separated by '+' or spaces and converts to the combined string
+func UnicodeToString(unicode string) string {
+	runes, err := ParseUnicodeKey(unicode)
+	if err != nil {
+		return "?"
+	}
+	return string(runes)
+}
+
+// UnicodeKey returns the canonical database key for text, each code point as upper case hex of at least 4 digits joined
+// by '+', so code points beyond U+FFFF take 5 or 6
+func UnicodeKey(text string) string {
+	parts := make([]string, 0, utf8.RuneCountInString(text))
+	for _, r := range text {
+		parts = append(parts, fmt.Sprintf("%04X", r))
+	}
+	return strings.Join(parts, "+")
+}
+
+// ParseUnicodeKey splits a database key into its code points, accepting 1 to 6 hex digits in either case for each and
+// '+' or spaces between them
+func ParseUnicodeKey(key string) ([]rune, error) {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return r == '+' || r == ' '
+	})
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty unicode key %q", key)
+	}
+
+	runes := make([]rune, 0, len(parts))
+	for _, part := range parts {
+		code, err := strconv.ParseUint(part, 16, 32)
+		if len(part) > 6 || err != nil || !utf8.ValidRune(rune(code)) {
+			return nil, fmt.Errorf("code point %q in %q is not valid hex", part, key)
+		}
+		runes = append(runes, rune(code))
+	}
+	return runes, nil
+}
+
+// CanonicalUnicodeKey rewrites key in the form UnicodeKey produces, so "41" and "0041" or "0E01 0E33" and "0E01+0E33"
+// name the same entry
+func CanonicalUnicodeKey(key string) (string, error) {
+	runes, err := ParseUnicodeKey(key)
+	if err != nil {
+		return "", err
+	}
+	return UnicodeKey(string(runes)), nil
+}
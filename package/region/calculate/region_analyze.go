@@ -0,0 +1,148 @@
+package regionCalculate
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+	"github.com/bsthun/glyphcanvas/package/region/helper"
+)
+
+const (
+	regionHoughMinEdges = 12
+	regionHoughMinDraws = 20
+	regionMinHoleDraws  = 4    // Smaller enclosed gaps are pinholes in a thick stroke, not a counter of the glyph
+	regionMinHoleRatio  = 0.01 // Same for gaps below this share of the region's own pixel count
+)
+
+func RegionAnalyze(r *region.Region) *region.RegionAnalysis {
+	return RegionAnalyzeWithOptions(r, region.ClassifyOptions{})
+}
+
+func RegionAnalyzeWithOptions(r *region.Region, opts region.ClassifyOptions) *region.RegionAnalysis {
+	analysis := &region.RegionAnalysis{}
+
+	// Work on the tight content box so empty canvas does not cost time or skew size dependent measures
+	r = r.Crop()
+
+	analysis.Moments = regionHelper.RegionComputeMoments(r)
+	analysis.HuInvariants = regionHelper.RegionComputeHuInvariants(analysis.Moments)
+	analysis.Circularity = regionHelper.RegionComputeCircularity(analysis.HuInvariants)
+	analysis.PerimeterCircularity = regionHelper.RegionComputeCircularityPerimeter(r)
+	analysis.Linearity = regionHelper.RegionComputeLinearity(analysis.Moments)
+	analysis.Orientation = regionHelper.RegionComputeOrientation(analysis.Moments)
+
+	analysis.Edges = regionHelper.RegionExtractEdge(r)
+	analysis.ChainCode = regionHelper.RegionExtractChainCode(r)
+	analysis.Curvatures = regionHelper.RegionComputeCurvatures(analysis.ChainCode)
+	analysis.CurveStrength = regionHelper.RegionComputeCurveStrength(analysis.Curvatures, analysis.Edges)
+
+	if len(r.Draws) < 3 || len(analysis.Edges) < 3 {
+		return analysis
+	}
+
+	fillType := regionHelper.RegionDetermineFillType(r)
+
+	// Hough votes are noise on tiny regions, fall back to moment based heuristics
+	if len(analysis.Edges) < regionHoughMinEdges || len(r.Draws) < regionHoughMinDraws {
+		arcType, fillType := regionHelper.RegionClassifySmallShape(fillType, analysis.Moments, analysis.PerimeterCircularity)
+		analysis.Arc = regionBuildArc(arcType, fillType, analysis, len(r.Draws), opts)
+		analysis.Arc.Children = regionAnalyzeHoles(r, opts)
+		return analysis
+	}
+
+	analysis.Lines = regionHelper.RegionDetectLinesHough(r, analysis.Edges)
+	analysis.Circles = regionHelper.RegionDetectCirclesHough(r, analysis.Edges)
+
+	arcType, fillType := regionHelper.RegionClassifyShape(fillType, len(r.Draws), analysis, opts)
+	analysis.Arc = regionBuildArc(arcType, fillType, analysis, len(r.Draws), opts)
+	analysis.Arc.Children = regionAnalyzeHoles(r, opts)
+
+	return analysis
+}
+
+// regionAnalyzeHoles describes every hole of r from its moments alone. A hole is a solid flood filled area, so the
+// edge, chain code and Hough steps of RegionAnalyze would only add cost
+func regionAnalyzeHoles(r *region.Region, opts region.ClassifyOptions) []*region.Arc {
+	var children []*region.Arc
+	for _, hole := range regionHelper.RegionExtractHoles(r) {
+		if len(hole.Draws) < regionMinHoleDraws || float64(len(hole.Draws)) < regionMinHoleRatio*float64(len(r.Draws)) {
+			continue
+		}
+
+		analysis := &region.RegionAnalysis{}
+		analysis.Moments = regionHelper.RegionComputeMoments(hole)
+		analysis.HuInvariants = regionHelper.RegionComputeHuInvariants(analysis.Moments)
+		analysis.Circularity = regionHelper.RegionComputeCircularity(analysis.HuInvariants)
+		analysis.PerimeterCircularity = regionHelper.RegionComputeCircularityPerimeter(hole)
+		analysis.Linearity = regionHelper.RegionComputeLinearity(analysis.Moments)
+		analysis.Orientation = regionHelper.RegionComputeOrientation(analysis.Moments)
+
+		arcType, fillType := regionHelper.RegionClassifySmallShape(region.ArcFillTypeFill, analysis.Moments, analysis.PerimeterCircularity)
+		children = append(children, regionBuildArc(arcType, fillType, analysis, len(hole.Draws), opts))
+	}
+	return children
+}
+
+// AnalyzeRegions classifies every region of a character, the arcs are aligned with regions by index and nil where a
+// region is too small to classify. Regions are analyzed in parallel, see RegionAnalyzeAll
+func AnalyzeRegions(regions []*region.Region) []*region.Arc {
+	return AnalyzeRegionsWithOptions(regions, 0, region.ClassifyOptions{})
+}
+
+// AnalyzeRegionsWithOptions is AnalyzeRegions with the worker count of RegionAnalyzeAll and custom classify options
+func AnalyzeRegionsWithOptions(regions []*region.Region, workers int, opts region.ClassifyOptions) []*region.Arc {
+	arcs := make([]*region.Arc, len(regions))
+	for i, analysis := range RegionAnalyzeAllWithOptions(regions, workers, opts) {
+		if analysis != nil {
+			arcs[i] = analysis.Arc
+		}
+	}
+	return arcs
+}
+
+// RegionAnalyzeAll runs RegionAnalyze on every region using a pool of workers, 0 uses GOMAXPROCS and 1 stays on the
+// calling goroutine. Results are aligned with regions by index, a nil region gets a nil analysis
+func RegionAnalyzeAll(regions []*region.Region, workers int) []*region.RegionAnalysis {
+	return RegionAnalyzeAllWithOptions(regions, workers, region.ClassifyOptions{})
+}
+
+// RegionAnalyzeAllWithOptions is RegionAnalyzeAll running RegionAnalyzeWithOptions on every region
+func RegionAnalyzeAllWithOptions(regions []*region.Region, workers int, opts region.ClassifyOptions) []*region.RegionAnalysis {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(regions))
+
+	analyses := make([]*region.RegionAnalysis, len(regions))
+	if workers <= 1 {
+		for i, r := range regions {
+			if r != nil {
+				analyses[i] = RegionAnalyzeWithOptions(r, opts)
+			}
+		}
+		return analyses
+	}
+
+	// Each worker writes only its own slots and the analysis caches of its own regions
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				analyses[i] = RegionAnalyzeWithOptions(regions[i], opts)
+			}
+		}()
+	}
+	for i, r := range regions {
+		if r != nil {
+			indices <- i
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	return analyses
+}
@@ -0,0 +1,298 @@
+package regionCalculate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+	"github.com/bsthun/glyphcanvas/package/region/helper"
+)
+
+func TestRegionAnalyzeMatchesHelpers(t *testing.T) {
+	r := region.NewRegion(30, 30)
+	for x := uint16(5); x <= 24; x++ {
+		for y := uint16(12); y <= 16; y++ {
+			r.Draw(x, y)
+		}
+	}
+
+	analysis := RegionAnalyze(r)
+	if analysis.Arc == nil {
+		t.Fatal("RegionAnalyze returned nil arc for bar region")
+	}
+
+	hu := regionHelper.RegionComputeHuInvariants(regionHelper.RegionComputeMoments(r))
+	for i := range hu {
+		if analysis.HuInvariants[i] != hu[i] {
+			t.Errorf("hu[%d] = %v, want %v", i, analysis.HuInvariants[i], hu[i])
+		}
+	}
+
+	if analysis.Circularity != regionHelper.RegionComputeCircularity(hu) {
+		t.Errorf("circularity = %v, want %v", analysis.Circularity, regionHelper.RegionComputeCircularity(hu))
+	}
+
+	if len(analysis.ChainCode) != len(analysis.Edges)-1 {
+		t.Errorf("chain code length = %v, want %v", len(analysis.ChainCode), len(analysis.Edges)-1)
+	}
+}
+
+func TestRegionAnalyzeSmallRegion(t *testing.T) {
+	r := region.NewRegion(10, 10)
+	r.Draw(4, 4)
+	r.Draw(5, 4)
+
+	analysis := RegionAnalyze(r)
+	if analysis.Arc != nil {
+		t.Errorf("expected nil arc for region with fewer than 3 points")
+	}
+	if len(analysis.HuInvariants) != 7 {
+		t.Errorf("hu invariants length = %v, want 7", len(analysis.HuInvariants))
+	}
+}
+
+func TestRegionAnalyzeSkipsHoughOnTinyRegion(t *testing.T) {
+	tests := []struct {
+		name   string
+		points [][2]uint16
+		degree float32
+	}{
+		{name: "horizontal", points: [][2]uint16{{4, 5}, {5, 5}, {6, 5}}, degree: 0},
+		{name: "diagonal", points: [][2]uint16{{4, 4}, {5, 5}, {6, 6}}, degree: 45},
+		{name: "vertical", points: [][2]uint16{{5, 4}, {5, 5}, {5, 6}}, degree: 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := region.NewRegion(12, 12)
+			for _, point := range tt.points {
+				r.Draw(point[0], point[1])
+			}
+
+			analysis := RegionAnalyze(r)
+			if analysis.Arc == nil {
+				t.Fatal("RegionAnalyze returned nil arc for 3px region")
+			}
+			if len(analysis.Lines) != 0 || len(analysis.Circles) != 0 {
+				t.Errorf("expected Hough to be skipped, got %d lines and %d circles", len(analysis.Lines), len(analysis.Circles))
+			}
+			if analysis.Arc.Type != region.ArcTypeStrengthLine {
+				t.Errorf("arc type = %v, want straight line", analysis.Arc.Type)
+			}
+			if analysis.Arc.LineDegree != tt.degree {
+				t.Errorf("line degree = %v, want %v", analysis.Arc.LineDegree, tt.degree)
+			}
+		})
+	}
+}
+
+func TestRegionAnalyzeCanvasSizeIndependent(t *testing.T) {
+	drawShape := func(r *region.Region, offsetX, offsetY uint16) {
+		for x := uint16(0); x < 20; x++ {
+			for y := uint16(0); y < 6; y++ {
+				r.Draw(offsetX+x, offsetY+y)
+			}
+		}
+		for y := uint16(6); y < 24; y++ {
+			for x := uint16(0); x < 5; x++ {
+				r.Draw(offsetX+x, offsetY+y)
+			}
+		}
+	}
+
+	small := region.NewRegion(50, 50)
+	drawShape(small, 10, 10)
+	large := region.NewRegion(200, 200)
+	drawShape(large, 120, 140)
+
+	minX, minY, maxX, maxY := large.ContentBounds()
+	if minX != 120 || minY != 140 || maxX != 139 || maxY != 163 {
+		t.Errorf("content bounds = (%d,%d)-(%d,%d), want (120,140)-(139,163)", minX, minY, maxX, maxY)
+	}
+
+	cropped := large.Crop()
+	if cropped.GetSizeX() != 22 || cropped.GetSizeY() != 26 {
+		t.Errorf("cropped size = %dx%d, want 22x26", cropped.GetSizeX(), cropped.GetSizeY())
+	}
+
+	smallHu := RegionAnalyze(small).HuInvariants
+	largeHu := RegionAnalyze(large).HuInvariants
+	for i := range smallHu {
+		if smallHu[i] != largeHu[i] {
+			t.Errorf("hu[%d] = %v in 50x50, %v in 200x200", i, smallHu[i], largeHu[i])
+		}
+	}
+}
+
+func TestAnalyzeRegions(t *testing.T) {
+	// The pieces of one character on a shared canvas, a bowl over a stem with a stray dot
+	pieces := func() []*region.Region {
+		bowl, stem, dot := region.NewRegion(60, 80), region.NewRegion(60, 80), region.NewRegion(60, 80)
+		for x := 0; x < 60; x++ {
+			for y := 0; y < 40; y++ {
+				dx, dy := x-30, y-20
+				if distSq := dx*dx + dy*dy; distSq <= 18*18 && distSq > 12*12 {
+					bowl.Draw(uint16(x), uint16(y))
+				}
+			}
+		}
+		for x := uint16(45); x < 50; x++ {
+			for y := uint16(20); y < 75; y++ {
+				stem.Draw(x, y)
+			}
+		}
+		dot.Draw(10, 70)
+		dot.Draw(11, 70)
+		return []*region.Region{bowl, stem, nil, dot}
+	}
+
+	regions := pieces()
+	arcs := AnalyzeRegions(regions)
+	if len(arcs) != len(regions) {
+		t.Fatalf("Expected %d arcs aligned with the regions, got %d", len(regions), len(arcs))
+	}
+	if arcs[2] != nil || arcs[3] != nil {
+		t.Errorf("Expected no arc for the missing region and the dot, got %v and %v", arcs[2], arcs[3])
+	}
+
+	for i, reference := range pieces() {
+		if reference == nil {
+			continue
+		}
+		want := RegionArc(reference)
+		if (want == nil) != (arcs[i] == nil) {
+			t.Fatalf("Region %d: arc %v, want %v", i, arcs[i], want)
+		}
+		if want == nil {
+			continue
+		}
+		if arcs[i].Type != want.Type || arcs[i].Fill != want.Fill || arcs[i].Confidence != want.Confidence || len(arcs[i].Children) != len(want.Children) {
+			t.Errorf("Region %d: arc %+v, want %+v", i, arcs[i], want)
+		}
+	}
+	if len(arcs[0].Children) != 1 {
+		t.Errorf("Expected the bowl to enclose one hole, got %d", len(arcs[0].Children))
+	}
+
+	sequential := RegionAnalyzeAll(pieces(), 1)
+	for i, analysis := range RegionAnalyzeAll(pieces(), 4) {
+		if (analysis == nil) != (sequential[i] == nil) {
+			t.Fatalf("Region %d: parallel analysis %v, sequential %v", i, analysis, sequential[i])
+		}
+		if analysis != nil && analysis.Circularity != sequential[i].Circularity {
+			t.Errorf("Region %d: parallel circularity %v, sequential %v", i, analysis.Circularity, sequential[i].Circularity)
+		}
+	}
+}
+
+func TestAnalyzeRegionsWithOptions(t *testing.T) {
+	bar := func() *region.Region {
+		r := region.NewRegion(70, 20)
+		for x := uint16(5); x < 65; x++ {
+			for y := uint16(6); y < 14; y++ {
+				r.Draw(x, y)
+			}
+		}
+		return r
+	}
+
+	opts := region.ClassifyOptions{LineMinVoteRatio: 0.05}
+	want := RegionAnalyzeWithOptions(bar(), opts).Arc
+	if want == nil || want.Confidence == RegionAnalyze(bar()).Arc.Confidence {
+		t.Fatalf("Expected the vote ratio to change the bar confidence, got %+v", want)
+	}
+
+	for _, workers := range []int{1, 4} {
+		arcs := AnalyzeRegionsWithOptions([]*region.Region{bar(), nil, bar()}, workers, opts)
+		if arcs[1] != nil {
+			t.Errorf("workers %d: expected no arc for the missing region, got %v", workers, arcs[1])
+		}
+		for _, i := range []int{0, 2} {
+			if arcs[i] == nil || arcs[i].Type != want.Type || arcs[i].Confidence != want.Confidence {
+				t.Errorf("workers %d, region %d: arc %+v, want %+v", workers, i, arcs[i], want)
+			}
+		}
+	}
+}
+
+func TestRegionFeaturesStandaloneCircle(t *testing.T) {
+	r := region.NewRegion(60, 60)
+	for x := 0; x < 60; x++ {
+		for y := 0; y < 60; y++ {
+			dx, dy := x-30, y-30
+			if dx*dx+dy*dy <= 20*20 {
+				r.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+
+	features := RegionFeatures(r)
+	analysis := RegionAnalyze(r)
+	if analysis.Arc == nil {
+		t.Fatal("RegionAnalyze returned nil arc for circle region")
+	}
+
+	if features.ArcType != region.ArcTypeString(analysis.Arc.Type) || features.ArcConfidence != analysis.Arc.Confidence {
+		t.Errorf("arc = %s (%v), want %s (%v)", features.ArcType, features.ArcConfidence, region.ArcTypeString(analysis.Arc.Type), analysis.Arc.Confidence)
+	}
+	if features.Circularity != analysis.Circularity || features.Linearity != analysis.Linearity {
+		t.Errorf("circularity, linearity = %v, %v, want %v, %v", features.Circularity, features.Linearity, analysis.Circularity, analysis.Linearity)
+	}
+	if features.Circularity < 0.9 {
+		t.Errorf("circularity = %v, want a disk to be near 1", features.Circularity)
+	}
+
+	sum := 0.0
+	for _, share := range features.ChainCodeHist {
+		sum += share
+	}
+	if sum < 0.999 || sum > 1.001 {
+		t.Errorf("chain code histogram sums to %v, want 1", sum)
+	}
+
+	if features.RelativeSize != 0 || features.RelativePos != [2]float64{} {
+		t.Errorf("relative size %v and position %v, want zero without a character", features.RelativeSize, features.RelativePos)
+	}
+}
+
+func TestRegionArcZeroSize(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, r := range []*region.Region{region.NewRegion(0, 0), region.NewRegion(0, 12), region.NewRegion(12, 0), region.NewRegion(1, 1)} {
+			if arc := RegionArc(r); arc != nil {
+				t.Errorf("RegionArc of an empty %dx%d region = %+v, want nil", r.SizeX, r.SizeY, arc)
+			}
+			if edges := regionHelper.RegionExtractEdge(r); len(edges) != 0 {
+				t.Errorf("edges of an empty %dx%d region = %d, want none", r.SizeX, r.SizeY, len(edges))
+			}
+			if fill := regionHelper.RegionDetermineFillType(r); fill != region.ArcFillTypeFill {
+				t.Errorf("fill type of an empty %dx%d region = %v, want fill", r.SizeX, r.SizeY, fill)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("analysis of a zero sized region did not finish")
+	}
+}
+
+func TestRegionArcWideRegion(t *testing.T) {
+	// Wider than 255 pixels, the Hough rho range must not wrap around in uint16
+	r := region.NewRegion(400, 300)
+	for x := uint16(340); x < 350; x++ {
+		for y := uint16(10); y < 290; y++ {
+			r.Draw(x, y)
+		}
+	}
+
+	lines := regionHelper.RegionDetectLinesHough(r, regionHelper.RegionExtractEdge(r))
+	if len(lines) == 0 {
+		t.Fatal("no Hough line for a long bar")
+	}
+	if lines[0].Theta != 0 || lines[0].Rho < 339 || lines[0].Rho > 350 {
+		t.Errorf("strongest line of a vertical bar at x=340 = %+v, want theta 0 and rho near 340", *lines[0])
+	}
+}
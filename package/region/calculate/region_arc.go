@@ -1,65 +1,42 @@
 package regionCalculate
 
 import (
-	"fmt"
+	"math"
 
 	"github.com/bsthun/glyphcanvas/package/region"
 	"github.com/bsthun/glyphcanvas/package/region/helper"
 )
 
 func RegionArc(r *region.Region) *region.Arc {
-	if len(r.Draws) < 3 {
-		return nil
-	}
-
-	edges := regionHelper.RegionExtractEdge(r)
-	if len(edges) < 3 {
-		return nil
-	}
-
-	chainCode := regionHelper.RegionComputeChainCode(edges)
-	curvatures := regionHelper.RegionComputeCurvatures(chainCode)
-
-	moments := regionHelper.RegionComputeMoments(r)
-	huInvariants := regionHelper.RegionComputeHuInvariants(moments)
-
-	lines := regionHelper.RegionDetectLinesHough(r, edges)
-	circles := regionHelper.RegionDetectCirclesHough(r, edges)
+	return RegionAnalyze(r).Arc
+}
 
-	fillType := regionHelper.RegionDetermineFillType(r)
-	arcType, fillType := regionHelper.RegionClassifyShape(fillType, len(r.Draws), huInvariants, curvatures, lines, circles)
+func RegionArcWithOptions(r *region.Region, opts region.ClassifyOptions) *region.Arc {
+	return RegionAnalyzeWithOptions(r, opts).Arc
+}
 
+func regionBuildArc(arcType region.ArcType, fillType region.ArcFillType, analysis *region.RegionAnalysis, drawsCount int, opts region.ClassifyOptions) *region.Arc {
 	arc := &region.Arc{
-		Type: arcType,
-		Fill: fillType,
+		Type:       arcType,
+		Fill:       fillType,
+		Confidence: regionHelper.RegionComputeArcConfidence(arcType, analysis, drawsCount, opts),
 	}
 
 	switch arcType {
 	case region.ArcTypeCircle:
-		arc.CircleEllipseRatio = regionHelper.RegionComputeEllipseRatio(moments)
+		arc.CircleEllipseRatio = regionHelper.RegionComputeEllipseRatio(analysis.Moments)
 
 	case region.ArcTypeStrengthLine:
+		lines := analysis.Lines
+		if len(lines) == 0 {
+			// Hough was skipped, use the principal axis as the line normal
+			lines = []*region.HoughAccumulator{{Theta: math.Mod(analysis.Orientation+math.Pi/2, math.Pi)}}
+		}
 		arc.LineDegree = regionHelper.RegionComputeLineDegree(lines)
-		fmt.Printf("Line detected with degree: %.0f°\n", arc.LineDegree)
 
 	case region.ArcTypeCurveLine:
-		arc.ArcLineTheta = regionHelper.RegionComputeCurveStrength(curvatures, edges)
-		fmt.Printf("Curve detected with strength: %.3f\n", arc.ArcLineTheta)
-
-	case region.ArcTypeTriangle:
-		corners := regionHelper.RegionDetectCorners(curvatures, edges)
-		if len(corners) == 3 {
-			fmt.Println("Triangle detected")
-		}
-
-	case region.ArcTypeRectangle:
-		corners := regionHelper.RegionDetectCorners(curvatures, edges)
-		if len(corners) == 4 {
-			fmt.Println("Rectangle detected")
-		}
+		arc.ArcLineTheta = analysis.CurveStrength
 	}
 
-	regionHelper.RegionPrintDetectedAngles(edges)
-
 	return arc
 }
@@ -1,6 +1,8 @@
 package regionCalculate
 
 import (
+	"math"
+	"math/rand"
 	"path/filepath"
 	"testing"
 
@@ -18,7 +20,7 @@ func TestRegionArcWithTestImage(t *testing.T) {
 		t.Fatal("RegionArc returned nil for test image")
 	}
 
-	if arc.Type < 0 || arc.Type > region.ArcTypeRectangle {
+	if arc.Type < 0 || arc.Type > region.ArcTypeUnknown {
 		t.Errorf("Invalid arc type: %v", arc.Type)
 	}
 
@@ -56,6 +58,25 @@ func TestRegionArcWithCircle(t *testing.T) {
 	}
 }
 
+func TestRegionArcWithNoisyCircle(t *testing.T) {
+	// A rasterized outline turns sharply at many pixels, jittered edges add more, none of it makes a polygon
+	for seed := int64(1); seed <= 5; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		r := region.NewRegion(40, 40)
+		for x := 0; x < 40; x++ {
+			for y := 0; y < 40; y++ {
+				if math.Hypot(float64(x)-20, float64(y)-20) <= 10+(rng.Float64()-0.5)*0.5 {
+					r.Draw(uint16(x), uint16(y))
+				}
+			}
+		}
+
+		if arc := RegionArc(r); arc.Type != region.ArcTypeCircle {
+			t.Errorf("Expected noisy circle %d to classify as circle, got %v", seed, arc.Type)
+		}
+	}
+}
+
 func TestRegionArcWithRectangle(t *testing.T) {
 	r := region.NewRegion(100, 100)
 
@@ -210,6 +231,54 @@ func TestRegionArcCurve(t *testing.T) {
 	}
 }
 
+func TestRegionArcWithRingAndBar(t *testing.T) {
+	ring := func(bar bool) *region.Region {
+		r := region.NewRegion(60, 60)
+		for x := 0; x < 60; x++ {
+			for y := 0; y < 60; y++ {
+				dx, dy := x-30, y-30
+				distSq := dx*dx + dy*dy
+				if distSq <= 22*22 && (distSq > 16*16 || (bar && dy >= -2 && dy <= 2)) {
+					r.Draw(uint16(x), uint16(y))
+				}
+			}
+		}
+		return r
+	}
+
+	arc := RegionArc(ring(true))
+	if arc == nil {
+		t.Fatal("RegionArc returned nil for ring with bar")
+	}
+	if len(arc.Children) != 2 {
+		t.Fatalf("Expected 2 child arcs for the halves of a ring with bar, got %d", len(arc.Children))
+	}
+	for i, child := range arc.Children {
+		if child.Fill != region.ArcFillTypeFill {
+			t.Errorf("Expected child %d to be a filled hole, got fill %v", i, child.Fill)
+		}
+		if len(child.Children) != 0 {
+			t.Errorf("Expected child %d without nested children, got %d", i, len(child.Children))
+		}
+	}
+
+	if children := RegionArc(ring(false)).Children; len(children) != 1 {
+		t.Errorf("Expected 1 child arc for a plain ring, got %d", len(children))
+	} else if children[0].Type != region.ArcTypeCircle {
+		t.Errorf("Expected the hole of a plain ring to be a circle, got %v", children[0].Type)
+	}
+
+	solid := region.NewRegion(40, 40)
+	for x := uint16(10); x < 30; x++ {
+		for y := uint16(10); y < 30; y++ {
+			solid.Draw(x, y)
+		}
+	}
+	if children := len(RegionArc(solid).Children); children != 0 {
+		t.Errorf("Expected no child arcs for a solid square, got %d", children)
+	}
+}
+
 func BenchmarkRegionArc(b *testing.B) {
 	r := region.NewRegion(100, 100)
 	for x := uint16(20); x <= 80; x++ {
@@ -223,3 +292,26 @@ func BenchmarkRegionArc(b *testing.B) {
 		_ = RegionArc(r)
 	}
 }
+
+func TestArcTypeStringRoundTrip(t *testing.T) {
+	names := map[string]bool{}
+	for _, arcType := range region.ArcTypes() {
+		name := region.ArcTypeString(arcType)
+		if names[name] {
+			t.Errorf("Arc type %d shares the name %q", arcType, name)
+		}
+		names[name] = true
+
+		parsed, ok := region.ParseArcType(name)
+		if !ok || parsed != arcType {
+			t.Errorf("ParseArcType(%q) = %d, %v, want %d", name, parsed, ok, arcType)
+		}
+	}
+
+	if name := region.ArcTypeString(region.ArcType(99)); name != "unknown" {
+		t.Errorf("Expected an out of range arc type to be unknown, got %q", name)
+	}
+	if _, ok := region.ParseArcType("spiral"); ok {
+		t.Error("Expected ParseArcType to reject an unlisted name")
+	}
+}
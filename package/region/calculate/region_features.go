@@ -0,0 +1,31 @@
+package regionCalculate
+
+import (
+	"github.com/bsthun/glyphcanvas/package/region"
+	"github.com/bsthun/glyphcanvas/package/region/helper"
+)
+
+// RegionFeatures analyzes reg on its own and returns its intrinsic features, the fields relative to a character stay zero
+func RegionFeatures(reg *region.Region) region.RegionFeatureSet {
+	return RegionFeaturesFromAnalysis(RegionAnalyze(reg))
+}
+
+// RegionFeaturesFromAnalysis fills the intrinsic features from a finished analysis. Hu invariants are kept only for
+// regions too small to classify, the arc fields describe the shape of the others
+func RegionFeaturesFromAnalysis(analysis *region.RegionAnalysis) region.RegionFeatureSet {
+	features := region.RegionFeatureSet{}
+	if analysis.Arc != nil {
+		features.ArcType = region.ArcTypeString(analysis.Arc.Type)
+		features.ArcConfidence = analysis.Arc.Confidence
+		features.Circularity = analysis.Circularity
+		features.Linearity = analysis.Linearity
+		features.CurveStrength = float64(analysis.CurveStrength)
+		features.Holes = len(analysis.Arc.Children)
+	} else {
+		copy(features.HuMoments[:], analysis.HuInvariants)
+	}
+
+	features.ChainCodeHist = regionHelper.RegionComputeChainCodeHistogram(analysis.ChainCode)
+
+	return features
+}
@@ -0,0 +1,36 @@
+package regionHelper
+
+import (
+	"sync"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+// Scratch buffers reused across regions to keep batch extraction off the garbage collector
+
+var pointBufferPool = sync.Pool{
+	New: func() any {
+		buffer := make([][2]uint16, 0, 1024)
+		return &buffer
+	},
+}
+
+var edgeBufferPool = sync.Pool{
+	New: func() any {
+		buffer := make([]region.EdgePoint, 0, 256)
+		return &buffer
+	},
+}
+
+var lineAccumulatorPool = sync.Pool{
+	New: func() any {
+		buffer := make([]int, 0, 1<<16)
+		return &buffer
+	},
+}
+
+var circleAccumulatorPool = sync.Pool{
+	New: func() any {
+		return make(map[uint64]int, 1024)
+	},
+}
@@ -6,31 +6,50 @@ import (
 	"github.com/bsthun/glyphcanvas/package/region"
 )
 
-func RegionClassifyShape(fillType region.ArcFillType, drawsCount int, hu []float64, curvatures []float64, lines, circles []*region.HoughAccumulator) (region.ArcType, region.ArcFillType) {
+// RegionClassifyShape names the shape of a region of drawsCount pixels from its analysis, which needs the edges, Hu
+// invariants, perimeter circularity, linearity, curvatures and Hough peaks filled in
+func RegionClassifyShape(fillType region.ArcFillType, drawsCount int, analysis *region.RegionAnalysis, opts region.ClassifyOptions) (region.ArcType, region.ArcFillType) {
+	lineMinVoteRatio := opts.LineMinVoteRatio
+	if lineMinVoteRatio == 0 {
+		lineMinVoteRatio = region.DefaultLineMinVoteRatio
+	}
+	cornerMinSpread := opts.CornerMinSpread
+	if cornerMinSpread == 0 {
+		cornerMinSpread = region.DefaultCornerMinSpread
+	}
+
+	hu, curvatures := analysis.HuInvariants, analysis.Curvatures
+	perimeterCircularity, linearity := analysis.PerimeterCircularity, analysis.Linearity
+	lines, circles := analysis.Lines, analysis.Circles
+
 	if len(circles) > 0 && circles[0].Votes > drawsCount/3 {
 		circularity := RegionComputeCircularity(hu)
-		if circularity > 0.7 {
+		if circularity > 0.7 && perimeterCircularity > 0.75 {
 			return region.ArcTypeCircle, fillType
 		}
 	}
 
-	if len(lines) > 0 && lines[0].Votes > drawsCount/2 {
-		linearity := RegionComputeLinearity(hu)
+	if len(lines) > 0 && float64(lines[0].Votes) >= lineMinVoteRatio*float64(len(analysis.Edges)) {
 		if linearity > 0.8 {
 			return region.ArcTypeStrengthLine, fillType
 		}
 	}
 
 	corners := RegionDetectCorners(curvatures, nil)
-	if len(corners) == 3 {
-		return region.ArcTypeTriangle, fillType
-	} else if len(corners) == 4 {
-		rectangularity := RegionComputeRectangularity(hu)
-		if rectangularity > 0.7 {
+	if (len(corners) == 3 || len(corners) == 4) && RegionCornersSpread(corners, len(curvatures), cornerMinSpread) {
+		if len(corners) == 3 {
+			return region.ArcTypeTriangle, fillType
+		} else if RegionComputeRectangularity(hu) > 0.7 {
 			return region.ArcTypeRectangle, fillType
 		}
 	}
 
+	// Clustered corners are noise on one side of the outline and a rasterized curve turns sharply at many pixels,
+	// without a clean polygon a round outline is still a circle
+	if RegionComputeCircularity(hu) > 0.7 && perimeterCircularity > 0.75 {
+		return region.ArcTypeCircle, fillType
+	}
+
 	avgCurvature := 0.0
 	for _, c := range curvatures {
 		avgCurvature += math.Abs(c)
@@ -39,9 +58,15 @@ func RegionClassifyShape(fillType region.ArcFillType, drawsCount int, hu []float
 		avgCurvature /= float64(len(curvatures))
 	}
 
-	if avgCurvature > 0.1 && avgCurvature < 0.8 {
+	// Nothing matched decisively, fall back on elongation, compactness and curvature
+	if linearity > 0.8 {
+		return region.ArcTypeStrengthLine, fillType
+	}
+
+	// A thin winding stroke has a long perimeter for its area, a compact blob does not
+	if avgCurvature > 0.1 && avgCurvature < 0.8 && perimeterCircularity < 0.6 {
 		return region.ArcTypeCurveLine, fillType
 	}
 
-	return region.ArcTypeStrengthLine, fillType
+	return region.ArcTypeUnknown, fillType
 }
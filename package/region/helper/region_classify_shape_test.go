@@ -0,0 +1,186 @@
+package regionHelper
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func createBlobRegion(size int, radius, wobble float64) *region.Region {
+	r := region.NewRegion(uint16(size), uint16(size))
+	center := float64(size) / 2
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			dx, dy := float64(x)-center, float64(y)-center
+			edge := radius + wobble*math.Sin(3*math.Atan2(dy, dx))
+			if dx*dx+dy*dy <= edge*edge {
+				r.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+	return r
+}
+
+func TestRegionClassifyShapeFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   *region.Region
+		expected region.ArcType
+	}{
+		{name: "Compact blob", region: createBlobRegion(60, 14, 4), expected: region.ArcTypeUnknown},
+		{name: "Thin plus stroke", region: createPlusRegion(60, 20, 3), expected: region.ArcTypeCurveLine},
+		{name: "Elongated bar", region: createEllipseRegion(80, 35, 3), expected: region.ArcTypeStrengthLine},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moments := RegionComputeMoments(tt.region)
+			hu := RegionComputeHuInvariants(moments)
+			curvatures := RegionComputeCurvatures(RegionExtractChainCode(tt.region))
+
+			// No Hough peaks, so only the fallback decides
+			arcType, _ := RegionClassifyShape(region.ArcFillTypeFill, len(tt.region.Draws), &region.RegionAnalysis{
+				Edges:                RegionExtractEdge(tt.region),
+				Curvatures:           curvatures,
+				HuInvariants:         hu,
+				PerimeterCircularity: RegionComputeCircularityPerimeter(tt.region),
+				Linearity:            RegionComputeLinearity(moments),
+			}, region.ClassifyOptions{})
+			if arcType != tt.expected {
+				t.Errorf("RegionClassifyShape() = %v, want %v", arcType, tt.expected)
+			}
+		})
+	}
+}
+
+// createBarRegion draws a horizontal length×thickness bar, only its one pixel outline when hollow
+func createBarRegion(length, thickness int, hollow bool) *region.Region {
+	r := region.NewRegion(uint16(length+10), uint16(thickness+10))
+	for x := 5; x < 5+length; x++ {
+		for y := 5; y < 5+thickness; y++ {
+			border := x == 5 || x == 4+length || y == 5 || y == 4+thickness
+			if !hollow || border {
+				r.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+	return r
+}
+
+func TestRegionClassifyShapeLineVotes(t *testing.T) {
+	tests := []struct {
+		name   string
+		region *region.Region
+	}{
+		{name: "Filled bar", region: createBarRegion(60, 8, false)},
+		{name: "Hollow bar outline", region: createBarRegion(60, 8, true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moments := RegionComputeMoments(tt.region)
+			hu := RegionComputeHuInvariants(moments)
+			edges := RegionExtractEdge(tt.region)
+			curvatures := RegionComputeCurvatures(RegionExtractChainCode(tt.region))
+			lines := RegionDetectLinesHough(tt.region, edges)
+
+			if len(lines) == 0 || float64(lines[0].Votes) < region.DefaultLineMinVoteRatio*float64(len(edges)) {
+				t.Fatalf("expected the long side to collect enough votes from %d edge pixels, got %v", len(edges), lines)
+			}
+
+			analysis := &region.RegionAnalysis{
+				Edges:                edges,
+				Curvatures:           curvatures,
+				HuInvariants:         hu,
+				Lines:                lines,
+				PerimeterCircularity: RegionComputeCircularityPerimeter(tt.region),
+				Linearity:            RegionComputeLinearity(moments),
+			}
+			arcType, _ := RegionClassifyShape(region.ArcFillTypeFill, len(tt.region.Draws), analysis, region.ClassifyOptions{})
+			if arcType != region.ArcTypeStrengthLine {
+				t.Errorf("RegionClassifyShape() = %v, want %v", arcType, region.ArcTypeStrengthLine)
+			}
+
+		})
+	}
+}
+
+// createNoisyCircleRegion draws a filled circle whose edge is jittered by up to noise/2 pixels
+func createNoisyCircleRegion(size int, radius, noise float64, seed int64) *region.Region {
+	rng := rand.New(rand.NewSource(seed))
+	r := region.NewRegion(uint16(size), uint16(size))
+	center := float64(size) / 2
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			if math.Hypot(float64(x)-center, float64(y)-center) <= radius+(rng.Float64()-0.5)*noise {
+				r.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+	return r
+}
+
+func TestRegionClassifyShapeClusteredCorners(t *testing.T) {
+	r := createNoisyCircleRegion(40, 14, 0.5, 1)
+	moments := RegionComputeMoments(r)
+	hu := RegionComputeHuInvariants(moments)
+	edges := RegionExtractEdge(r)
+	edgeCount := len(edges)
+
+	// A smooth outline whose only sharp turns are at the given contour points
+	outline := func(corners ...int) []float64 {
+		curvatures := make([]float64, edgeCount)
+		for i := range curvatures {
+			curvatures[i] = 2 * math.Pi / float64(edgeCount)
+		}
+		for _, corner := range corners {
+			curvatures[corner] = math.Pi / 3
+		}
+		return curvatures
+	}
+
+	tests := []struct {
+		name     string
+		corners  []int
+		opts     region.ClassifyOptions
+		expected region.ArcType
+	}{
+		{name: "Burr on one side", corners: []int{10, 13, 16}, expected: region.ArcTypeCircle},
+		{name: "Corners around the outline", corners: []int{0, edgeCount / 3, 2 * edgeCount / 3}, expected: region.ArcTypeTriangle},
+		{name: "Burr with clustered corners accepted", corners: []int{10, 13, 16}, opts: region.ClassifyOptions{CornerMinSpread: -1}, expected: region.ArcTypeTriangle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curvatures := outline(tt.corners...)
+			if corners := RegionDetectCorners(curvatures, nil); len(corners) != len(tt.corners) {
+				t.Fatalf("expected %d detected corners, got %v", len(tt.corners), corners)
+			}
+
+			arcType, _ := RegionClassifyShape(region.ArcFillTypeFill, len(r.Draws), &region.RegionAnalysis{
+				Edges:                edges,
+				Curvatures:           curvatures,
+				HuInvariants:         hu,
+				PerimeterCircularity: RegionComputeCircularityPerimeter(r),
+				Linearity:            RegionComputeLinearity(moments),
+			}, tt.opts)
+			if arcType != tt.expected {
+				t.Errorf("RegionClassifyShape() = %v, want %v", arcType, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegionCornersSpread(t *testing.T) {
+	if !RegionCornersSpread([]int{0, 30, 60, 90}, 120, region.DefaultCornerMinSpread) {
+		t.Error("Expected evenly spaced corners to be spread")
+	}
+	if !RegionCornersSpread([]int{5, 40, 100}, 120, region.DefaultCornerMinSpread) {
+		t.Error("Expected corners with the wrap around gap to be spread")
+	}
+	if RegionCornersSpread([]int{10, 13, 16}, 120, region.DefaultCornerMinSpread) {
+		t.Error("Expected corners within a few points of each other to be clustered")
+	}
+}
@@ -0,0 +1,22 @@
+package regionHelper
+
+import "github.com/bsthun/glyphcanvas/package/region"
+
+func RegionClassifySmallShape(fillType region.ArcFillType, moments map[string]float64, perimeterCircularity float64) (region.ArcType, region.ArcFillType) {
+	lambda1, lambda2 := RegionComputePrincipalAxes(moments)
+
+	if lambda1 <= 0 {
+		return region.ArcTypeStrengthLine, fillType
+	}
+
+	elongation := lambda2 / lambda1
+	if elongation < 0.15 {
+		return region.ArcTypeStrengthLine, fillType
+	}
+
+	if elongation > 0.6 && perimeterCircularity > 0.75 {
+		return region.ArcTypeCircle, fillType
+	}
+
+	return region.ArcTypeCurveLine, fillType
+}
@@ -0,0 +1,52 @@
+package regionHelper
+
+import (
+	"math"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+// RegionComputeArcConfidence scores in [0, 1] how decisively the analysis supports arcType, from the margins over the
+// thresholds RegionClassifyShape applies with the same opts
+func RegionComputeArcConfidence(arcType region.ArcType, analysis *region.RegionAnalysis, drawsCount int, opts region.ClassifyOptions) float64 {
+	lineMinVoteRatio := opts.LineMinVoteRatio
+	if lineMinVoteRatio == 0 {
+		lineMinVoteRatio = region.DefaultLineMinVoteRatio
+	}
+
+	clamp := func(value float64) float64 {
+		return math.Max(0, math.Min(1, value))
+	}
+
+	switch arcType {
+	case region.ArcTypeCircle:
+		margin := clamp(math.Min((analysis.Circularity-0.7)/0.3, (analysis.PerimeterCircularity-0.75)/0.25))
+		if len(analysis.Circles) > 0 && drawsCount >= 3 {
+			votes := clamp(float64(analysis.Circles[0].Votes)/float64(drawsCount/3) - 1)
+			margin = (margin + votes) / 2
+		}
+		return margin
+
+	case region.ArcTypeStrengthLine:
+		margin := clamp((analysis.Linearity - 0.8) / 0.2)
+		// Votes come from edge pixels, a line with twice the votes the classifier needs is decisive
+		if minVotes := lineMinVoteRatio * float64(len(analysis.Edges)); len(analysis.Lines) > 0 && minVotes > 0 {
+			votes := clamp(float64(analysis.Lines[0].Votes)/minVotes - 1)
+			margin = (margin + votes) / 2
+		}
+		return margin
+
+	case region.ArcTypeCurveLine:
+		// A curve is what remains, so it is decisive only when far from both a line and a circle
+		lineLike := clamp(analysis.Linearity / 0.8)
+		circleLike := clamp(analysis.PerimeterCircularity / 0.75)
+		return clamp(1 - math.Max(lineLike, circleLike))
+
+	case region.ArcTypeUnknown:
+		return 0
+
+	default:
+		// Corner counting has no margin to measure
+		return 0.5
+	}
+}
@@ -0,0 +1,108 @@
+package regionHelper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func createEllipseRegion(size int, a, b float64) *region.Region {
+	r := region.NewRegion(uint16(size), uint16(size))
+	center := float64(size) / 2
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			dx := (float64(x) - center) / a
+			dy := (float64(y) - center) / b
+			if dx*dx+dy*dy <= 1 {
+				r.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+	return r
+}
+
+func analyzeForConfidence(r *region.Region) *region.RegionAnalysis {
+	moments := RegionComputeMoments(r)
+	hu := RegionComputeHuInvariants(moments)
+	return &region.RegionAnalysis{
+		Moments:              moments,
+		HuInvariants:         hu,
+		Circularity:          RegionComputeCircularity(hu),
+		PerimeterCircularity: RegionComputeCircularityPerimeter(r),
+		Linearity:            RegionComputeLinearity(moments),
+	}
+}
+
+func TestRegionComputeArcConfidence(t *testing.T) {
+	tests := []struct {
+		name      string
+		arcType   region.ArcType
+		clean     *region.Region
+		ambiguous *region.Region
+	}{
+		{
+			name:      "Circle",
+			arcType:   region.ArcTypeCircle,
+			clean:     createDiskRegion(60, 20),
+			ambiguous: createEllipseRegion(60, 20, 14),
+		},
+		{
+			name:      "Line",
+			arcType:   region.ArcTypeStrengthLine,
+			clean:     createEllipseRegion(80, 35, 3),
+			ambiguous: createEllipseRegion(80, 35, 14),
+		},
+		{
+			name:      "Curve",
+			arcType:   region.ArcTypeCurveLine,
+			clean:     createPlusRegion(60, 20, 3),
+			ambiguous: createDiskRegion(60, 20),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clean := RegionComputeArcConfidence(tt.arcType, analyzeForConfidence(tt.clean), len(tt.clean.Draws), region.ClassifyOptions{})
+			ambiguous := RegionComputeArcConfidence(tt.arcType, analyzeForConfidence(tt.ambiguous), len(tt.ambiguous.Draws), region.ClassifyOptions{})
+
+			if clean < 0 || clean > 1 || ambiguous < 0 || ambiguous > 1 {
+				t.Fatalf("confidence out of range: clean %v, ambiguous %v", clean, ambiguous)
+			}
+			if ambiguous >= clean {
+				t.Errorf("ambiguous confidence = %v, want below clean %v", ambiguous, clean)
+			}
+		})
+	}
+}
+
+func TestRegionComputeArcConfidenceLineVotes(t *testing.T) {
+	// The classifier counts line votes against the edge pixels, so a filled bar and its outline are equally decisive
+	confidence := make(map[bool]float64)
+	for _, hollow := range []bool{false, true} {
+		r := createBarRegion(60, 8, hollow)
+		moments := RegionComputeMoments(r)
+		edges := RegionExtractEdge(r)
+		analysis := &region.RegionAnalysis{
+			Edges:                edges,
+			Curvatures:           RegionComputeCurvatures(RegionExtractChainCode(r)),
+			HuInvariants:         RegionComputeHuInvariants(moments),
+			Lines:                RegionDetectLinesHough(r, edges),
+			PerimeterCircularity: RegionComputeCircularityPerimeter(r),
+			Linearity:            RegionComputeLinearity(moments),
+		}
+		if arcType, _ := RegionClassifyShape(region.ArcFillTypeFill, len(r.Draws), analysis, region.ClassifyOptions{}); arcType != region.ArcTypeStrengthLine {
+			t.Fatalf("expected the bar (hollow %v) to classify as a line, got %v", hollow, arcType)
+		}
+
+		linearityOnly := math.Max(0, math.Min(1, (analysis.Linearity-0.8)/0.2)) / 2
+		confidence[hollow] = RegionComputeArcConfidence(region.ArcTypeStrengthLine, analysis, len(r.Draws), region.ClassifyOptions{})
+		if confidence[hollow] <= linearityOnly {
+			t.Errorf("expected the line votes of the bar (hollow %v) to add confidence, got %v", hollow, confidence[hollow])
+		}
+	}
+
+	if math.Abs(confidence[false]-confidence[true]) > 0.1 {
+		t.Errorf("filled bar confidence %v, want close to the hollow bar's %v", confidence[false], confidence[true])
+	}
+}
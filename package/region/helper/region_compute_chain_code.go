@@ -1,6 +1,8 @@
 package regionHelper
 
 import (
+	"sort"
+
 	"github.com/bsthun/glyphcanvas/package/region"
 )
 
@@ -9,7 +11,18 @@ func RegionComputeChainCode(edges []*region.EdgePoint) []int {
 		return []int{}
 	}
 
-	sortedEdges := RegionSortEdgesForContour(edges)
+	// Row major order makes the trace start at the topmost, then leftmost, edge and break distance ties the same way
+	// whatever order the edges come in
+	ordered := make([]*region.EdgePoint, len(edges))
+	copy(ordered, edges)
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Y != ordered[j].Y {
+			return ordered[i].Y < ordered[j].Y
+		}
+		return ordered[i].X < ordered[j].X
+	})
+
+	sortedEdges := RegionSortEdgesForContour(ordered)
 	chainCode := []int{}
 
 	for i := 1; i < len(sortedEdges); i++ {
@@ -0,0 +1,18 @@
+package regionHelper
+
+// RegionComputeChainCodeHistogram returns the share of each of the 8 chain code directions
+func RegionComputeChainCodeHistogram(chainCode []int) [8]float64 {
+	var histogram [8]float64
+	if len(chainCode) == 0 {
+		return histogram
+	}
+
+	for _, code := range chainCode {
+		histogram[code&7]++
+	}
+	for i := range histogram {
+		histogram[i] /= float64(len(chainCode))
+	}
+
+	return histogram
+}
@@ -0,0 +1,54 @@
+package regionHelper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func createStrokeRegion(horizontal bool) *region.Region {
+	r := region.NewRegion(40, 40)
+	for i := uint16(5); i < 35; i++ {
+		for w := uint16(18); w < 21; w++ {
+			if horizontal {
+				r.Draw(i, w)
+			} else {
+				r.Draw(w, i)
+			}
+		}
+	}
+	return r
+}
+
+func TestRegionComputeChainCodeHistogram(t *testing.T) {
+	horizontal := RegionComputeChainCodeHistogram(RegionExtractChainCode(createStrokeRegion(true)))
+	vertical := RegionComputeChainCodeHistogram(RegionExtractChainCode(createStrokeRegion(false)))
+
+	sum := 0.0
+	for _, share := range horizontal {
+		sum += share
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("histogram sums to %v, want 1", sum)
+	}
+
+	if horizontal[0]+horizontal[4] <= horizontal[2]+horizontal[6] {
+		t.Errorf("horizontal stroke histogram %v is not dominated by horizontal moves", horizontal)
+	}
+	if vertical[2]+vertical[6] <= vertical[0]+vertical[4] {
+		t.Errorf("vertical stroke histogram %v is not dominated by vertical moves", vertical)
+	}
+
+	difference := 0.0
+	for i := range horizontal {
+		difference += math.Abs(horizontal[i] - vertical[i])
+	}
+	if difference < 0.5 {
+		t.Errorf("histogram L1 distance = %v, want distinct histograms", difference)
+	}
+
+	if empty := RegionComputeChainCodeHistogram(nil); empty != [8]float64{} {
+		t.Errorf("empty chain code histogram = %v, want zeros", empty)
+	}
+}
@@ -0,0 +1,55 @@
+package regionHelper
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func TestRegionComputeChainCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupRegion func() *region.Region
+	}{
+		{name: "Horizontal stroke", setupRegion: func() *region.Region { return createStrokeRegion(true) }},
+		{name: "Vertical stroke", setupRegion: func() *region.Region { return createStrokeRegion(false) }},
+		{
+			name: "Filled square",
+			setupRegion: func() *region.Region {
+				r := region.NewRegion(30, 30)
+				for x := uint16(5); x < 25; x++ {
+					for y := uint16(5); y < 25; y++ {
+						r.Draw(x, y)
+					}
+				}
+				return r
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edges := RegionExtractEdge(tt.setupRegion())
+			code := RegionComputeChainCode(edges)
+			if len(code) != len(edges)-1 {
+				t.Fatalf("chain code has %d steps for %d edges", len(code), len(edges))
+			}
+
+			// The same edges in any order trace the same contour
+			shuffled := make([]*region.EdgePoint, len(edges))
+			copy(shuffled, edges)
+			rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+			if other := RegionComputeChainCode(shuffled); !reflect.DeepEqual(other, code) {
+				t.Errorf("chain code changed with edge order:\n%v\n%v", code, other)
+			}
+		})
+	}
+
+	if code := RegionComputeChainCode(nil); len(code) != 0 {
+		t.Errorf("expected an empty chain code without edges, got %v", code)
+	}
+}
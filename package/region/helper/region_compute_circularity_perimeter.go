@@ -0,0 +1,36 @@
+package regionHelper
+
+import (
+	"math"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func RegionComputeCircularityPerimeter(reg *region.Region) float64 {
+	contour := RegionTraceOuterContour(reg)
+	if len(contour) < 3 {
+		return 0
+	}
+
+	area := 0.0
+	perimeter := 0.0
+	for i := range contour {
+		current := contour[i]
+		next := contour[(i+1)%len(contour)]
+
+		area += float64(current.X)*float64(next.Y) - float64(next.X)*float64(current.Y)
+
+		if current.X != next.X && current.Y != next.Y {
+			perimeter += math.Sqrt2
+		} else {
+			perimeter += 1
+		}
+	}
+	area = math.Abs(area) / 2
+
+	if perimeter == 0 {
+		return 0
+	}
+
+	return math.Min(1.0, 4*math.Pi*area/(perimeter*perimeter))
+}
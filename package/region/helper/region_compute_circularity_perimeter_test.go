@@ -0,0 +1,89 @@
+package regionHelper
+
+import (
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func createDiskRegion(size, radius int) *region.Region {
+	r := region.NewRegion(uint16(size), uint16(size))
+	center := size / 2
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			if (x-center)*(x-center)+(y-center)*(y-center) <= radius*radius {
+				r.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+	return r
+}
+
+func createPlusRegion(size, arm, thickness int) *region.Region {
+	r := region.NewRegion(uint16(size), uint16(size))
+	center := size / 2
+	for x := center - arm; x <= center+arm; x++ {
+		for y := center - thickness; y <= center+thickness; y++ {
+			r.Draw(uint16(x), uint16(y))
+			r.Draw(uint16(y), uint16(x))
+		}
+	}
+	return r
+}
+
+func TestRegionComputeCircularityPerimeter(t *testing.T) {
+	tests := []struct {
+		name   string
+		region *region.Region
+		min    float64
+		max    float64
+	}{
+		{name: "disk", region: createDiskRegion(60, 20), min: 0.85, max: 1.0},
+		{name: "small disk", region: createDiskRegion(20, 6), min: 0.8, max: 1.0},
+		{name: "plus blob", region: createPlusRegion(60, 20, 3), min: 0.0, max: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RegionComputeCircularityPerimeter(tt.region)
+			if result < tt.min || result > tt.max {
+				t.Errorf("RegionComputeCircularityPerimeter() = %v, want in [%v, %v]", result, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRegionClassifyShapeRequiresBothCircularities(t *testing.T) {
+	circles := []*region.HoughAccumulator{{Votes: 100000}}
+
+	disk := createDiskRegion(60, 20)
+	diskMoments := RegionComputeMoments(disk)
+	diskHu := RegionComputeHuInvariants(diskMoments)
+	arcType, _ := RegionClassifyShape(region.ArcFillTypeFill, len(disk.Draws), &region.RegionAnalysis{
+		Edges:                RegionExtractEdge(disk),
+		HuInvariants:         diskHu,
+		Circles:              circles,
+		PerimeterCircularity: RegionComputeCircularityPerimeter(disk),
+		Linearity:            RegionComputeLinearity(diskMoments),
+	}, region.ClassifyOptions{})
+	if arcType != region.ArcTypeCircle {
+		t.Errorf("disk classified as %v, want circle", arcType)
+	}
+
+	plus := createPlusRegion(60, 20, 3)
+	plusMoments := RegionComputeMoments(plus)
+	plusHu := RegionComputeHuInvariants(plusMoments)
+	if RegionComputeCircularity(plusHu) <= 0.7 {
+		t.Fatalf("expected plus blob to have high Hu circularity, got %v", RegionComputeCircularity(plusHu))
+	}
+	arcType, _ = RegionClassifyShape(region.ArcFillTypeFill, len(plus.Draws), &region.RegionAnalysis{
+		Edges:                RegionExtractEdge(plus),
+		HuInvariants:         plusHu,
+		Circles:              circles,
+		PerimeterCircularity: RegionComputeCircularityPerimeter(plus),
+		Linearity:            RegionComputeLinearity(plusMoments),
+	}, region.ClassifyOptions{})
+	if arcType == region.ArcTypeCircle {
+		t.Errorf("plus blob classified as circle")
+	}
+}
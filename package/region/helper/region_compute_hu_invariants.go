@@ -5,20 +5,23 @@ import "math"
 func RegionComputeHuInvariants(moments map[string]float64) []float64 {
 	hu := make([]float64, 7)
 
+	// Zero or invalid area has no shape, all zeros marks the invariants as missing
 	m00 := moments["m00"]
-	if m00 == 0 {
+	if !(m00 > 0) || math.IsInf(m00, 0) {
 		return hu
 	}
 
-	norm := math.Pow(m00, 2.5)
+	// Scale normalization: eta_pq = mu_pq / m00^(1+(p+q)/2)
+	norm2 := m00 * m00
+	norm3 := math.Pow(m00, 2.5)
 
-	eta20 := moments["mu20"] / norm
-	eta02 := moments["mu02"] / norm
-	eta11 := moments["mu11"] / norm
-	eta30 := moments["mu30"] / math.Pow(m00, 3.5)
-	eta21 := moments["mu21"] / math.Pow(m00, 3.5)
-	eta12 := moments["mu12"] / math.Pow(m00, 3.5)
-	eta03 := moments["mu03"] / math.Pow(m00, 3.5)
+	eta20 := moments["mu20"] / norm2
+	eta02 := moments["mu02"] / norm2
+	eta11 := moments["mu11"] / norm2
+	eta30 := moments["mu30"] / norm3
+	eta21 := moments["mu21"] / norm3
+	eta12 := moments["mu12"] / norm3
+	eta03 := moments["mu03"] / norm3
 
 	hu[0] = eta20 + eta02
 	hu[1] = math.Pow(eta20-eta02, 2) + 4*math.Pow(eta11, 2)
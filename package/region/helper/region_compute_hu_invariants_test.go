@@ -0,0 +1,53 @@
+package regionHelper
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegionComputeHuInvariantsDegenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		moments map[string]float64
+	}{
+		{
+			name:    "Empty moments",
+			moments: map[string]float64{},
+		},
+		{
+			name:    "Zero area",
+			moments: map[string]float64{"m00": 0, "mu20": 1, "mu02": 1},
+		},
+		{
+			name:    "Negative area",
+			moments: map[string]float64{"m00": -2, "mu20": 1, "mu02": 1},
+		},
+		{
+			name:    "NaN area",
+			moments: map[string]float64{"m00": math.NaN(), "mu20": 1, "mu02": 1},
+		},
+		{
+			name:    "Infinite area",
+			moments: map[string]float64{"m00": math.Inf(1), "mu20": 1, "mu02": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hu := RegionComputeHuInvariants(tt.moments)
+			if len(hu) != 7 {
+				t.Fatalf("RegionComputeHuInvariants() returned %d values, want 7", len(hu))
+			}
+			for i, value := range hu {
+				if value != 0 {
+					t.Errorf("RegionComputeHuInvariants()[%d] = %v, want 0", i, value)
+				}
+			}
+		})
+	}
+
+	hu := RegionComputeHuInvariants(RegionComputeMoments(createDiskRegion(21, 8)))
+	if hu[0] <= 0 || math.IsNaN(hu[0]) {
+		t.Errorf("RegionComputeHuInvariants() on a disk = %v, want a positive first invariant", hu[0])
+	}
+}
@@ -1,20 +1,11 @@
 package regionHelper
 
-import "math"
-
-func RegionComputeLinearity(hu []float64) float64 {
-	if len(hu) < 3 {
+// RegionComputeLinearity returns 1 - λ2/λ1 of the pixel covariance, 1 for a line and 0 for a disk
+func RegionComputeLinearity(moments map[string]float64) float64 {
+	lambda1, lambda2 := RegionComputePrincipalAxes(moments)
+	if lambda1 <= 0 {
 		return 0
 	}
 
-	sum := 0.0
-	for i := 2; i < len(hu); i++ {
-		sum += math.Abs(hu[i])
-	}
-
-	if sum < 0.01 {
-		return 1.0
-	}
-
-	return 1.0 / (1.0 + sum*100)
+	return 1 - lambda2/lambda1
 }
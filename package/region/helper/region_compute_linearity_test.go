@@ -0,0 +1,99 @@
+package regionHelper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func TestRegionComputeLinearity(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupRegion func() *region.Region
+		min         float64
+		max         float64
+	}{
+		{
+			name: "Straight horizontal line",
+			setupRegion: func() *region.Region {
+				r := region.NewRegion(40, 10)
+				for x := uint16(5); x < 35; x++ {
+					r.Draw(x, 5)
+				}
+				return r
+			},
+			min: 0.99,
+			max: 1.0,
+		},
+		{
+			name: "Diagonal line",
+			setupRegion: func() *region.Region {
+				r := region.NewRegion(40, 40)
+				for i := uint16(5); i < 35; i++ {
+					r.Draw(i, i)
+				}
+				return r
+			},
+			min: 0.99,
+			max: 1.0,
+		},
+		{
+			name: "Filled square",
+			setupRegion: func() *region.Region {
+				r := region.NewRegion(30, 30)
+				for x := uint16(5); x < 25; x++ {
+					for y := uint16(5); y < 25; y++ {
+						r.Draw(x, y)
+					}
+				}
+				return r
+			},
+			min: 0.0,
+			max: 0.01,
+		},
+		{
+			name: "Filled disk",
+			setupRegion: func() *region.Region {
+				return createDiskRegion(60, 20)
+			},
+			min: 0.0,
+			max: 0.05,
+		},
+		{
+			name: "Empty region",
+			setupRegion: func() *region.Region {
+				return region.NewRegion(10, 10)
+			},
+			min: 0.0,
+			max: 0.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RegionComputeLinearity(RegionComputeMoments(tt.setupRegion()))
+			if result < tt.min || result > tt.max {
+				t.Errorf("RegionComputeLinearity() = %v, want in [%v, %v]", result, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRegionComputeLinearityScaleInvariant(t *testing.T) {
+	bar := func(width, height uint16) float64 {
+		r := region.NewRegion(width+10, height+10)
+		for x := uint16(5); x < width+5; x++ {
+			for y := uint16(5); y < height+5; y++ {
+				r.Draw(x, y)
+			}
+		}
+		return RegionComputeLinearity(RegionComputeMoments(r))
+	}
+
+	small := bar(4, 20)
+	large := bar(16, 80)
+	if math.Abs(small-large) > 0.01 {
+		t.Errorf("bar linearity = %v at 4x20 and %v at 16x80, want equal", small, large)
+	}
+}
@@ -2,29 +2,68 @@ package regionHelper
 
 import "github.com/bsthun/glyphcanvas/package/region"
 
+// RegionComputeMoments returns the image moments of the pixels drawn in reg, each pixel counted once. The raw moments
+// m00, m10, m01, m11, m20, m02, m21, m12, m30 and m03 are always present. When reg has ink the map also holds the
+// centroid cx, cy and the complete set of central moments about it up to order 3: mu00, mu10, mu01, mu20, mu11, mu02,
+// mu30, mu21, mu12 and mu03, where mu00 equals m00 and the first order ones are zero by definition.
 func RegionComputeMoments(reg *region.Region) map[string]float64 {
-	moments := make(map[string]float64)
+	moments := make(map[string]float64, 22)
 
-	m00, m10, m01, m11, m20, m02, m21, m12, m30, m03 := 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0
+	pointsBuffer := pointBufferPool.Get().(*[][2]uint16)
+	points := (*pointsBuffer)[:0]
+	defer func() {
+		*pointsBuffer = points[:0]
+		pointBufferPool.Put(pointsBuffer)
+	}()
 
-	for x := uint16(0); x < reg.GetSizeX(); x++ {
-		for y := uint16(0); y < reg.GetSizeY(); y++ {
-			if reg.IsDrew(x, y) {
-				fx := float64(x)
-				fy := float64(y)
+	// Only the listed draws can be ink, collect the ones still drawn along with their bounds
+	var minX, minY, maxX, maxY uint16
+	for _, point := range reg.Draws {
+		if !reg.IsDrew(point.X, point.Y) {
+			continue
+		}
+		if len(points) == 0 {
+			minX, minY, maxX, maxY = point.X, point.Y, point.X, point.Y
+		} else {
+			minX, maxX = min(minX, point.X), max(maxX, point.X)
+			minY, maxY = min(minY, point.Y), max(maxY, point.Y)
+		}
+		points = append(points, [2]uint16{point.X, point.Y})
+	}
 
-				m00 += 1
-				m10 += fx
-				m01 += fy
-				m11 += fx * fy
-				m20 += fx * fx
-				m02 += fy * fy
-				m21 += fx * fx * fy
-				m12 += fx * fy * fy
-				m30 += fx * fx * fx
-				m03 += fy * fy * fy
+	// A pixel drawn twice is listed twice, drop the repeats with a bitset over the content box
+	if len(points) > 0 {
+		width := int(maxX-minX) + 1
+		seen := make([]uint64, (width*(int(maxY-minY)+1)+63)/64)
+		unique := points[:0]
+		for _, point := range points {
+			bit := int(point[1]-minY)*width + int(point[0]-minX)
+			if seen[bit/64]&(1<<(bit%64)) != 0 {
+				continue
 			}
+			seen[bit/64] |= 1 << (bit % 64)
+			unique = append(unique, point)
 		}
+		points = unique
+	}
+
+	// First pass: raw moments
+	m00, m10, m01, m11, m20, m02, m21, m12, m30, m03 := 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0
+
+	for _, point := range points {
+		fx := float64(point[0])
+		fy := float64(point[1])
+
+		m00 += 1
+		m10 += fx
+		m01 += fy
+		m11 += fx * fy
+		m20 += fx * fx
+		m02 += fy * fy
+		m21 += fx * fx * fy
+		m12 += fx * fy * fy
+		m30 += fx * fx * fx
+		m03 += fy * fy * fy
 	}
 
 	moments["m00"] = m00
@@ -39,19 +78,30 @@ func RegionComputeMoments(reg *region.Region) map[string]float64 {
 	moments["m03"] = m03
 
 	if m00 > 0 {
-		cx := m10 / m00
-		cy := m01 / m00
-		moments["cx"] = cx
-		moments["cy"] = cy
+		// Centroid relative to the content box keeps full precision regardless of the shape offset
+		localCx := (m10 - float64(minX)*m00) / m00
+		localCy := (m01 - float64(minY)*m00) / m00
+		moments["cx"] = float64(minX) + localCx
+		moments["cy"] = float64(minY) + localCy
 
-		mu20 := m20 - cx*m10
-		mu02 := m02 - cy*m01
-		mu11 := m11 - cx*m01
-		mu30 := m30 - 3*cx*m20 + 2*cx*cx*m10
-		mu21 := m21 - 2*cx*m11 - cy*m20 + 2*cx*cx*m01
-		mu12 := m12 - 2*cy*m11 - cx*m02 + 2*cy*cy*m10
-		mu03 := m03 - 3*cy*m02 + 2*cy*cy*m01
+		// Second pass: central moments from centroid relative coordinates, over the points found in the first
+		mu20, mu02, mu11, mu30, mu21, mu12, mu03 := 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0
+		for _, point := range points {
+			dx := float64(int(point[0])-int(minX)) - localCx
+			dy := float64(int(point[1])-int(minY)) - localCy
+
+			mu20 += dx * dx
+			mu02 += dy * dy
+			mu11 += dx * dy
+			mu30 += dx * dx * dx
+			mu21 += dx * dx * dy
+			mu12 += dx * dy * dy
+			mu03 += dy * dy * dy
+		}
 
+		moments["mu00"] = m00
+		moments["mu10"] = 0
+		moments["mu01"] = 0
 		moments["mu20"] = mu20
 		moments["mu02"] = mu02
 		moments["mu11"] = mu11
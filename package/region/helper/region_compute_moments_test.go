@@ -1,6 +1,7 @@
 package regionHelper
 
 import (
+	"math"
 	"testing"
 
 	"github.com/bsthun/glyphcanvas/package/region"
@@ -55,6 +56,123 @@ func TestRegionComputeMoments(t *testing.T) {
 	}
 }
 
+func TestRegionComputeMomentsRepeatedAndErasedDraws(t *testing.T) {
+	square := region.NewRegion(5, 5)
+	for x := uint16(1); x <= 3; x++ {
+		for y := uint16(1); y <= 3; y++ {
+			square.Draw(x, y)
+		}
+	}
+
+	// The same square drawn twice in reverse order on a large canvas, with a stray pixel erased again
+	messy := region.NewRegion(500, 500)
+	for pass := 0; pass < 2; pass++ {
+		for x := uint16(3); x >= 1; x-- {
+			for y := uint16(3); y >= 1; y-- {
+				messy.Draw(x, y)
+			}
+		}
+	}
+	messy.Draw(400, 400)
+	messy.Erase(400, 400)
+
+	want := map[string]float64{
+		"m00": 9, "m10": 18, "m01": 18, "m11": 36, "m20": 42, "m02": 42,
+		"cx": 2, "cy": 2, "mu20": 6, "mu02": 6, "mu11": 0, "mu30": 0, "mu03": 0,
+	}
+	clean := RegionComputeMoments(square)
+	got := RegionComputeMoments(messy)
+	for key, value := range want {
+		if clean[key] != value {
+			t.Errorf("%s = %v for the 3x3 square, want %v", key, clean[key], value)
+		}
+	}
+	for key, value := range clean {
+		if got[key] != value {
+			t.Errorf("%s = %v with repeated and erased draws, want %v", key, got[key], value)
+		}
+	}
+}
+
+func TestRegionComputeMomentsCentralSet(t *testing.T) {
+	// An L of four pixels, three along the top and one below the left end, centroid (1.75, 1.25)
+	r := region.NewRegion(5, 5)
+	r.Draw(1, 1)
+	r.Draw(2, 1)
+	r.Draw(3, 1)
+	r.Draw(1, 2)
+
+	// Offsets from the centroid are x -0.75, 0.25, 1.25, -0.75 and y -0.25, -0.25, -0.25, 0.75
+	want := map[string]float64{
+		"cx": 1.75, "cy": 1.25,
+		"mu00": 4, "mu10": 0, "mu01": 0,
+		"mu20": 2.75, "mu11": -0.75, "mu02": 0.75,
+		"mu30": 1.125, "mu21": -0.125, "mu12": -0.375, "mu03": 0.375,
+	}
+	moments := RegionComputeMoments(r)
+	for key, value := range want {
+		got, ok := moments[key]
+		if !ok {
+			t.Errorf("%s missing from the moments", key)
+			continue
+		}
+		if math.Abs(got-value) > 1e-9 {
+			t.Errorf("%s = %v, want %v", key, got, value)
+		}
+	}
+}
+
+func TestRegionComputeMomentsTranslationInvariant(t *testing.T) {
+	drawShape := func(r *region.Region, offsetX, offsetY uint16) {
+		for x := uint16(0); x < 12; x++ {
+			for y := uint16(0); y < 4; y++ {
+				r.Draw(offsetX+x, offsetY+y)
+			}
+		}
+		for y := uint16(4); y < 15; y++ {
+			r.Draw(offsetX+y/2, offsetY+y)
+			r.Draw(offsetX+y/2+1, offsetY+y)
+		}
+	}
+
+	near := region.NewRegion(40, 40)
+	drawShape(near, 2, 3)
+	far := region.NewRegion(1000, 1000)
+	drawShape(far, 900, 950)
+
+	nearMoments := RegionComputeMoments(near)
+	farMoments := RegionComputeMoments(far)
+
+	for _, key := range []string{"mu20", "mu02", "mu11", "mu30", "mu21", "mu12", "mu03"} {
+		if nearMoments[key] != farMoments[key] {
+			t.Errorf("%s = %v near origin, %v at offset", key, nearMoments[key], farMoments[key])
+		}
+	}
+}
+
+func TestRegionComputeHuInvariantsScale(t *testing.T) {
+	square := func(size uint16) *region.Region {
+		r := region.NewRegion(size+4, size+4)
+		for x := uint16(2); x < size+2; x++ {
+			for y := uint16(2); y < size+2; y++ {
+				r.Draw(x, y)
+			}
+		}
+		return r
+	}
+
+	small := RegionComputeHuInvariants(RegionComputeMoments(square(10)))
+	large := RegionComputeHuInvariants(RegionComputeMoments(square(40)))
+
+	// A continuous square has I1 = 1/6, the digital one approaches it as it grows
+	if math.Abs(large[0]-1.0/6.0) > 0.001 {
+		t.Errorf("hu[0] = %v, want close to %v", large[0], 1.0/6.0)
+	}
+	if math.Abs(small[0]-large[0]) > 0.01 {
+		t.Errorf("hu[0] = %v for 10x10, %v for 40x40", small[0], large[0])
+	}
+}
+
 func BenchmarkRegionComputeMoments(b *testing.B) {
 	r := region.NewRegion(100, 100)
 	for x := uint16(20); x <= 80; x++ {
@@ -0,0 +1,17 @@
+package regionHelper
+
+import "math"
+
+func RegionComputeOrientation(moments map[string]float64) float64 {
+	mu20 := moments["mu20"]
+	mu02 := moments["mu02"]
+	mu11 := moments["mu11"]
+
+	// Angle of the major principal axis, normalized to [0, π)
+	theta := 0.5 * math.Atan2(2*mu11, mu20-mu02)
+	if theta < 0 {
+		theta += math.Pi
+	}
+
+	return theta
+}
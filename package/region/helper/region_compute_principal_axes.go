@@ -0,0 +1,16 @@
+package regionHelper
+
+import "math"
+
+// RegionComputePrincipalAxes returns the eigenvalues of the pixel covariance, largest first
+func RegionComputePrincipalAxes(moments map[string]float64) (float64, float64) {
+	mu20 := moments["mu20"]
+	mu02 := moments["mu02"]
+	mu11 := moments["mu11"]
+
+	spread := math.Sqrt(math.Pow(mu20-mu02, 2) + 4*mu11*mu11)
+	lambda1 := (mu20 + mu02 + spread) / 2
+	lambda2 := (mu20 + mu02 - spread) / 2
+
+	return lambda1, math.Max(0, lambda2)
+}
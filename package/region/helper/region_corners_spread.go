@@ -0,0 +1,25 @@
+package regionHelper
+
+// RegionCornersSpread reports whether the corner indices, in contour order, are spread around a closed contour of
+// contourLength points rather than clustered on one part of it. Neighboring corners must be at least minSpread of the
+// even spacing contourLength/len(corners) apart, see region.DefaultCornerMinSpread
+func RegionCornersSpread(corners []int, contourLength int, minSpread float64) bool {
+	if len(corners) < 2 || contourLength <= 0 {
+		return true
+	}
+
+	minGap := minSpread * float64(contourLength) / float64(len(corners))
+	for i, corner := range corners {
+		next := corners[(i+1)%len(corners)]
+		gap := next - corner
+		if gap <= 0 {
+			// Wrap around from the last corner to the first
+			gap += contourLength
+		}
+		if float64(gap) < minGap {
+			return false
+		}
+	}
+
+	return true
+}
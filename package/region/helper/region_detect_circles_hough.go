@@ -1,7 +1,6 @@
 package regionHelper
 
 import (
-	"fmt"
 	"math"
 	"sort"
 
@@ -16,7 +15,10 @@ func RegionDetectCirclesHough(reg *region.Region, edges []*region.EdgePoint) []*
 	minRadius := 5.0
 	maxRadius := math.Min(float64(reg.GetSizeX()), float64(reg.GetSizeY())) / 2.0
 
-	accumulator := make(map[string]int)
+	// Pooled accumulator keyed by the rounded centre and radius packed into 16 bits each
+	accumulator := circleAccumulatorPool.Get().(map[uint64]int)
+	defer circleAccumulatorPool.Put(accumulator)
+	clear(accumulator)
 
 	for _, edge := range edges {
 		for radius := minRadius; radius <= maxRadius; radius += 2.0 {
@@ -25,7 +27,7 @@ func RegionDetectCirclesHough(reg *region.Region, edges []*region.EdgePoint) []*
 				b := float64(edge.Y) - radius*math.Sin(theta)
 
 				if a >= 0 && a < float64(reg.GetSizeX()) && b >= 0 && b < float64(reg.GetSizeY()) {
-					key := fmt.Sprintf("%.0f_%.0f_%.0f", a, b, radius)
+					key := uint64(math.RoundToEven(a))<<32 | uint64(math.RoundToEven(b))<<16 | uint64(math.RoundToEven(radius))
 					accumulator[key]++
 				}
 			}
@@ -33,27 +35,37 @@ func RegionDetectCirclesHough(reg *region.Region, edges []*region.EdgePoint) []*
 	}
 
 	threshold := len(edges) / 10
-	circles := []*region.HoughAccumulator{}
+	var peaks []uint64
 
 	for key, votes := range accumulator {
 		if votes > threshold {
-			var a, b, radius float64
-			fmt.Sscanf(key, "%f_%f_%f", &a, &b, &radius)
-
-			circles = append(circles, &region.HoughAccumulator{
-				Rho:   radius,
-				Theta: math.Atan2(b, a),
-				Votes: votes,
-			})
+			peaks = append(peaks, key)
 		}
 	}
 
-	sort.Slice(circles, func(i, j int) bool {
-		return circles[i].Votes > circles[j].Votes
+	// Break vote ties on the key so the order does not depend on map iteration
+	sort.Slice(peaks, func(i, j int) bool {
+		if accumulator[peaks[i]] != accumulator[peaks[j]] {
+			return accumulator[peaks[i]] > accumulator[peaks[j]]
+		}
+		return peaks[i] < peaks[j]
 	})
 
-	if len(circles) > 3 {
-		circles = circles[:3]
+	if len(peaks) > 3 {
+		peaks = peaks[:3]
+	}
+
+	circles := []*region.HoughAccumulator{}
+	for _, key := range peaks {
+		a := float64(key >> 32 & 0xFFFF)
+		b := float64(key >> 16 & 0xFFFF)
+		radius := float64(key & 0xFFFF)
+
+		circles = append(circles, &region.HoughAccumulator{
+			Rho:   radius,
+			Theta: math.Atan2(b, a),
+			Votes: accumulator[key],
+		})
 	}
 
 	return circles
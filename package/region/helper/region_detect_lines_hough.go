@@ -1,7 +1,6 @@
 package regionHelper
 
 import (
-	"fmt"
 	"math"
 	"sort"
 
@@ -13,11 +12,21 @@ func RegionDetectLinesHough(reg *region.Region, edges []*region.EdgePoint) []*re
 		return []*region.HoughAccumulator{}
 	}
 
-	maxRho := math.Sqrt(float64(reg.GetSizeX()*reg.GetSizeX() + reg.GetSizeY()*reg.GetSizeY()))
+	maxRho := math.Hypot(float64(reg.GetSizeX()), float64(reg.GetSizeY()))
 	rhoStep := 1.0
 	thetaStep := math.Pi / 180.0
 
-	accumulator := make(map[string]int)
+	// Dense pooled accumulator indexed by theta then rho, with slack for float rounding at the range ends
+	rhoCount := int(2*maxRho/rhoStep) + 2
+	thetaCount := int(math.Pi/thetaStep) + 2
+
+	buffer := lineAccumulatorPool.Get().(*[]int)
+	defer lineAccumulatorPool.Put(buffer)
+	if cap(*buffer) < rhoCount*thetaCount {
+		*buffer = make([]int, rhoCount*thetaCount)
+	}
+	accumulator := (*buffer)[:rhoCount*thetaCount]
+	clear(accumulator)
 
 	for _, edge := range edges {
 		for theta := 0.0; theta < math.Pi; theta += thetaStep {
@@ -26,18 +35,17 @@ func RegionDetectLinesHough(reg *region.Region, edges []*region.EdgePoint) []*re
 			rhoIdx := int((rho + maxRho) / rhoStep)
 			thetaIdx := int(theta / thetaStep)
 
-			key := fmt.Sprintf("%d_%d", rhoIdx, thetaIdx)
-			accumulator[key]++
+			accumulator[thetaIdx*rhoCount+rhoIdx]++
 		}
 	}
 
 	threshold := len(edges) / 4
 	lines := []*region.HoughAccumulator{}
 
-	for key, votes := range accumulator {
+	for index, votes := range accumulator {
 		if votes > threshold {
-			var rhoIdx, thetaIdx int
-			fmt.Sscanf(key, "%d_%d", &rhoIdx, &thetaIdx)
+			rhoIdx := index % rhoCount
+			thetaIdx := index / rhoCount
 
 			rho := float64(rhoIdx)*rhoStep - maxRho
 			theta := float64(thetaIdx) * thetaStep
@@ -50,7 +58,7 @@ func RegionDetectLinesHough(reg *region.Region, edges []*region.EdgePoint) []*re
 		}
 	}
 
-	sort.Slice(lines, func(i, j int) bool {
+	sort.SliceStable(lines, func(i, j int) bool {
 		return lines[i].Votes > lines[j].Votes
 	})
 
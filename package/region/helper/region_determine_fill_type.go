@@ -8,8 +8,10 @@ func RegionDetermineFillType(reg *region.Region) region.ArcFillType {
 	edgeCount := 0
 	totalCount := 0
 
-	for x := uint16(1); x < reg.GetSizeX()-1; x++ {
-		for y := uint16(1); y < reg.GetSizeY()-1; y++ {
+	// int bounds, a zero sized region would wrap SizeX-1 around to 65535
+	for ix := 1; ix < int(reg.GetSizeX())-1; ix++ {
+		for iy := 1; iy < int(reg.GetSizeY())-1; iy++ {
+			x, y := uint16(ix), uint16(iy)
 			if reg.IsDrew(x, y) {
 				totalCount++
 
@@ -0,0 +1,16 @@
+package regionHelper
+
+import "github.com/bsthun/glyphcanvas/package/region"
+
+// RegionExtractChainCode returns the chain code of the edges of r. The result is a copy of the cached chain code, so
+// callers may modify it without affecting later calls
+func RegionExtractChainCode(r *region.Region) []int {
+	if cached, ok := r.CachedChainCode(); ok {
+		return append([]int{}, cached...)
+	}
+
+	chainCode := RegionComputeChainCode(regionExtractEdge(r))
+	r.CacheChainCode(chainCode)
+
+	return append([]int{}, chainCode...)
+}
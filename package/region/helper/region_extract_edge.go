@@ -2,13 +2,44 @@ package regionHelper
 
 import "github.com/bsthun/glyphcanvas/package/region"
 
+// RegionExtractEdge returns the edge pixels of r. The result is a copy of the cached edges, so callers may sort or
+// modify it without affecting later calls
 func RegionExtractEdge(r *region.Region) []*region.EdgePoint {
-	var edges []*region.EdgePoint
+	return copyEdges(regionExtractEdge(r))
+}
+
+// copyEdges copies edges into one new backing array so no point is shared with the cache
+func copyEdges(edges []*region.EdgePoint) []*region.EdgePoint {
+	points := make([]region.EdgePoint, len(edges))
+	copied := make([]*region.EdgePoint, len(edges))
+	for i, edge := range edges {
+		points[i] = *edge
+		copied[i] = &points[i]
+	}
+	return copied
+}
+
+// regionExtractEdge returns the cached edges of r, computing them on the first call. The slice is shared with the
+// cache and must not be modified
+func regionExtractEdge(r *region.Region) []*region.EdgePoint {
+	if cached, ok := r.CachedEdges(); ok {
+		return cached
+	}
+
+	buffer := edgeBufferPool.Get().(*[]region.EdgePoint)
+	found := (*buffer)[:0]
+	defer func() {
+		*buffer = found[:0]
+		edgeBufferPool.Put(buffer)
+	}()
+
 	dx := []int{-1, 0, 1, -1, 1, -1, 0, 1}
 	dy := []int{-1, -1, -1, 0, 0, 1, 1, 1}
 
-	for x := uint16(1); x < r.GetSizeX()-1; x++ {
-		for y := uint16(1); y < r.GetSizeY()-1; y++ {
+	// int bounds, a zero sized region would wrap SizeX-1 around to 65535
+	for ix := 1; ix < int(r.GetSizeX())-1; ix++ {
+		for iy := 1; iy < int(r.GetSizeY())-1; iy++ {
+			x, y := uint16(ix), uint16(iy)
 			if !r.IsDrew(x, y) {
 				continue
 			}
@@ -27,7 +58,7 @@ func RegionExtractEdge(r *region.Region) []*region.EdgePoint {
 
 			if isEdge {
 				angle := RegionComputeGradientAngle(r, x, y)
-				edges = append(edges, &region.EdgePoint{
+				found = append(found, region.EdgePoint{
 					X:     int(x),
 					Y:     int(y),
 					Angle: angle,
@@ -36,5 +67,18 @@ func RegionExtractEdge(r *region.Region) []*region.EdgePoint {
 		}
 	}
 
+	// One backing array for all points instead of an allocation per edge
+	var edges []*region.EdgePoint
+	if len(found) > 0 {
+		points := make([]region.EdgePoint, len(found))
+		copy(points, found)
+		edges = make([]*region.EdgePoint, len(points))
+		for i := range points {
+			edges[i] = &points[i]
+		}
+	}
+
+	r.CacheEdges(edges)
+
 	return edges
 }
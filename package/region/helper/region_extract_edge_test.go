@@ -0,0 +1,87 @@
+package regionHelper
+
+import (
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func TestRegionExtractEdgeCache(t *testing.T) {
+	r := region.NewRegion(10, 10)
+	for x := uint16(2); x <= 6; x++ {
+		for y := uint16(2); y <= 6; y++ {
+			r.Draw(x, y)
+		}
+	}
+
+	first := RegionExtractEdge(r)
+	if _, ok := r.CachedEdges(); !ok || len(first) == 0 {
+		t.Fatalf("expected the edges to be cached")
+	}
+	second := RegionExtractEdge(r)
+	if len(second) != len(first) || second[0] == first[0] || *second[0] != *first[0] {
+		t.Errorf("expected an equal copy of the cached edges")
+	}
+
+	chainCode := RegionExtractChainCode(r)
+	if len(chainCode) != len(first)-1 {
+		t.Errorf("chain code length = %v, want %v", len(chainCode), len(first)-1)
+	}
+
+	r.Erase(4, 4)
+	third := RegionExtractEdge(r)
+	if len(third) != len(first)+8 {
+		t.Errorf("edges after erase = %v, want %v", len(third), len(first)+8)
+	}
+}
+
+func TestRegionExtractEdgeCopy(t *testing.T) {
+	r := region.NewRegion(10, 10)
+	for x := uint16(2); x <= 6; x++ {
+		for y := uint16(2); y <= 6; y++ {
+			r.Draw(x, y)
+		}
+	}
+
+	edges := RegionExtractEdge(r)
+	want := *edges[0]
+	edges[0].X, edges[0].Angle = -1, -1
+	edges[1] = nil
+	if again := RegionExtractEdge(r); again[1] == nil || *again[0] != want {
+		t.Errorf("mutating the returned edges changed the cache, first edge = %+v, want %+v", again[0], want)
+	}
+
+	chainCode := RegionExtractChainCode(r)
+	wantCode := chainCode[0]
+	chainCode[0] = -1
+	if again := RegionExtractChainCode(r); again[0] != wantCode {
+		t.Errorf("mutating the returned chain code changed the cache, first code = %d, want %d", again[0], wantCode)
+	}
+}
+
+func BenchmarkRegionExtractEdge(b *testing.B) {
+	r := region.NewRegion(100, 100)
+	for x := uint16(20); x <= 80; x++ {
+		for y := uint16(20); y <= 80; y++ {
+			r.Draw(x, y)
+		}
+	}
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			r.Erase(50, 50)
+			r.Draw(50, 50)
+			b.StartTimer()
+			_ = RegionExtractEdge(r)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		_ = RegionExtractEdge(r)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = RegionExtractEdge(r)
+		}
+	})
+}
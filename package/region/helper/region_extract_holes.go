@@ -0,0 +1,60 @@
+package regionHelper
+
+import "github.com/bsthun/glyphcanvas/package/region"
+
+// RegionExtractHoles returns each background area fully enclosed by ink as a filled region of the same size as reg,
+// ordered by the topmost-leftmost pixel of the hole. Background is 4-connected, the dual of 8-connected ink, so a
+// diagonal gap in a stroke does not close a hole.
+func RegionExtractHoles(reg *region.Region) []*region.Region {
+	if len(reg.Draws) == 0 {
+		return nil
+	}
+	minX, minY, maxX, maxY := reg.ContentBounds()
+
+	// Grid over the content box plus a background margin that surrounds the ink from outside
+	width, height := int(maxX-minX)+3, int(maxY-minY)+3
+	ink := make([]bool, width*height)
+	for _, point := range reg.Draws {
+		if reg.IsDrew(point.X, point.Y) {
+			ink[(int(point.Y-minY)+1)*width+int(point.X-minX)+1] = true
+		}
+	}
+
+	label := make([]int, width*height)
+	fill := func(start, id int) []int {
+		filled := []int{start}
+		label[start] = id
+		for i := 0; i < len(filled); i++ {
+			x, y := filled[i]%width, filled[i]/width
+			for _, step := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nx, ny := x+step[0], y+step[1]
+				if nx < 0 || ny < 0 || nx >= width || ny >= height {
+					continue
+				}
+				if next := ny*width + nx; !ink[next] && label[next] == 0 {
+					label[next] = id
+					filled = append(filled, next)
+				}
+			}
+		}
+		return filled
+	}
+
+	// The margin is one connected background area, everything it reaches is outside
+	fill(0, -1)
+
+	var holes []*region.Region
+	for at := range ink {
+		if ink[at] || label[at] != 0 {
+			continue
+		}
+
+		hole := region.NewRegion(reg.GetSizeX(), reg.GetSizeY())
+		for _, pixel := range fill(at, len(holes)+1) {
+			hole.Draw(uint16(pixel%width-1)+minX, uint16(pixel/width-1)+minY)
+		}
+		holes = append(holes, hole)
+	}
+
+	return holes
+}
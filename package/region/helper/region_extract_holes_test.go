@@ -0,0 +1,65 @@
+package regionHelper
+
+import (
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+// createRingRegion draws a ring of the given radii centered in a size×size region, with a horizontal bar across it
+// as in 'Θ' when bar is set
+func createRingRegion(size, outer, inner int, bar bool) *region.Region {
+	reg := region.NewRegion(uint16(size), uint16(size))
+	center := size / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := x-center, y-center
+			distSq := dx*dx + dy*dy
+			onRing := distSq <= outer*outer && distSq > inner*inner
+			onBar := bar && dy >= -1 && dy <= 1 && distSq <= outer*outer
+			if onRing || onBar {
+				reg.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+	return reg
+}
+
+func TestRegionExtractHoles(t *testing.T) {
+	diagonal := region.NewRegion(10, 10)
+	// A diamond outline whose sides only touch diagonally still encloses its center
+	for _, point := range [][2]uint16{{4, 2}, {5, 3}, {6, 4}, {5, 5}, {4, 6}, {3, 5}, {2, 4}, {3, 3}} {
+		diagonal.Draw(point[0], point[1])
+	}
+
+	tests := []struct {
+		name  string
+		reg   *region.Region
+		holes []int // Pixel count of each hole
+	}{
+		{name: "Empty", reg: region.NewRegion(10, 10), holes: nil},
+		{name: "Disk", reg: createDiskRegion(30, 10), holes: nil},
+		{name: "Ring", reg: createRingRegion(40, 15, 9, false), holes: []int{253}},
+		{name: "Ring with bar", reg: createRingRegion(40, 15, 9, true), holes: []int{100, 100}},
+		{name: "Diagonal outline", reg: diagonal, holes: []int{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holes := RegionExtractHoles(tt.reg)
+			if len(holes) != len(tt.holes) {
+				t.Fatalf("found %d holes, want %d", len(holes), len(tt.holes))
+			}
+			for i, hole := range holes {
+				if len(hole.Draws) != tt.holes[i] {
+					t.Errorf("hole %d has %d pixels, want %d", i, len(hole.Draws), tt.holes[i])
+				}
+				for _, point := range hole.Draws {
+					if tt.reg.IsDrew(point.X, point.Y) {
+						t.Fatalf("hole %d covers ink at (%d, %d)", i, point.X, point.Y)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package regionHelper
+
+import (
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+// RegionNormalizeOrientation returns a copy rotated about its centroid so the principal axis is horizontal
+func RegionNormalizeOrientation(reg *region.Region) *region.Region {
+	moments := RegionComputeMoments(reg)
+	if moments["m00"] == 0 {
+		return reg
+	}
+
+	return rotateAboutCentroid(reg, moments, RegionComputeOrientation(moments))
+}
@@ -0,0 +1,32 @@
+package regionHelper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func TestRegionNormalizeOrientation(t *testing.T) {
+	vertical := createStrokeRegion(false)
+	normalized := RegionNormalizeOrientation(vertical)
+
+	minX, minY, maxX, maxY := normalized.ContentBounds()
+	if maxX-minX <= maxY-minY {
+		t.Errorf("normalized content is %dx%d, want wider than tall", maxX-minX+1, maxY-minY+1)
+	}
+
+	moments := RegionComputeMoments(normalized)
+	theta := RegionComputeOrientation(moments)
+	if math.Min(theta, math.Pi-theta) > 0.05 {
+		t.Errorf("normalized orientation = %v, want horizontal", theta)
+	}
+	if original := RegionComputeMoments(vertical)["m00"]; moments["m00"] != original {
+		t.Errorf("pixel count changed from %v to %v", original, moments["m00"])
+	}
+
+	empty := region.NewRegion(10, 10)
+	if RegionNormalizeOrientation(empty) != empty {
+		t.Errorf("expected an empty region to be returned unchanged")
+	}
+}
@@ -0,0 +1,65 @@
+package regionHelper
+
+import (
+	"math"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+// RegionRotate returns a copy rotated about its centroid so the direction at theta radians becomes horizontal
+func RegionRotate(reg *region.Region, theta float64) *region.Region {
+	moments := RegionComputeMoments(reg)
+	if moments["m00"] == 0 {
+		return reg
+	}
+
+	return rotateAboutCentroid(reg, moments, theta)
+}
+
+func rotateAboutCentroid(reg *region.Region, moments map[string]float64, theta float64) *region.Region {
+	sin, cos := math.Sincos(theta)
+	cx, cy := moments["cx"], moments["cy"]
+
+	// Bounds of the content after rotating by -theta, relative to the centroid
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, point := range reg.Draws {
+		if !reg.IsDrew(point.X, point.Y) {
+			continue
+		}
+		dx := float64(point.X) - cx
+		dy := float64(point.Y) - cy
+		rx := dx*cos + dy*sin
+		ry := -dx*sin + dy*cos
+		minX, maxX = math.Min(minX, rx), math.Max(maxX, rx)
+		minY, maxY = math.Min(minY, ry), math.Max(maxY, ry)
+	}
+
+	// One pixel margin so edge extraction still sees the border
+	originX := math.Floor(minX) - 1
+	originY := math.Floor(minY) - 1
+	sizeX := int(math.Ceil(maxX)-originX) + 2
+	sizeY := int(math.Ceil(maxY)-originY) + 2
+	if sizeX > math.MaxUint16 || sizeY > math.MaxUint16 {
+		return reg
+	}
+
+	rotated := region.NewRegion(uint16(sizeX), uint16(sizeY))
+	for y := 0; y < sizeY; y++ {
+		for x := 0; x < sizeX; x++ {
+			// Inverse mapping keeps the rotated strokes free of holes
+			rx := float64(x) + originX
+			ry := float64(y) + originY
+			sx := math.Round(rx*cos - ry*sin + cx)
+			sy := math.Round(rx*sin + ry*cos + cy)
+			if sx < 0 || sy < 0 || sx >= float64(reg.GetSizeX()) || sy >= float64(reg.GetSizeY()) {
+				continue
+			}
+			if reg.IsDrew(uint16(sx), uint16(sy)) {
+				rotated.Draw(uint16(x), uint16(y))
+			}
+		}
+	}
+
+	return rotated
+}
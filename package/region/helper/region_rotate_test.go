@@ -0,0 +1,42 @@
+package regionHelper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsthun/glyphcanvas/package/region"
+)
+
+func TestRegionRotate(t *testing.T) {
+	tests := []struct {
+		name       string
+		theta      float64
+		horizontal bool // Expected principal axis of the rotated stroke
+	}{
+		{name: "No rotation", theta: 0, horizontal: false},
+		{name: "Quarter turn", theta: math.Pi / 2, horizontal: true},
+		{name: "Half turn", theta: math.Pi, horizontal: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vertical := createStrokeRegion(false)
+			rotated := RegionRotate(vertical, tt.theta)
+
+			minX, minY, maxX, maxY := rotated.ContentBounds()
+			if wider := maxX-minX > maxY-minY; wider != tt.horizontal {
+				t.Errorf("rotated content is %dx%d, want horizontal %v", maxX-minX+1, maxY-minY+1, tt.horizontal)
+			}
+
+			moments := RegionComputeMoments(rotated)
+			if original := RegionComputeMoments(vertical)["m00"]; moments["m00"] != original {
+				t.Errorf("pixel count changed from %v to %v", original, moments["m00"])
+			}
+		})
+	}
+
+	empty := region.NewRegion(10, 10)
+	if RegionRotate(empty, 1) != empty {
+		t.Errorf("expected an empty region to be returned unchanged")
+	}
+}
@@ -0,0 +1,81 @@
+package regionHelper
+
+import "github.com/bsthun/glyphcanvas/package/region"
+
+func RegionTraceOuterContour(reg *region.Region) []*region.Point {
+	dx := []int{1, 1, 0, -1, -1, -1, 0, 1}
+	dy := []int{0, 1, 1, 1, 0, -1, -1, -1}
+
+	isDrew := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= int(reg.GetSizeX()) || y >= int(reg.GetSizeY()) {
+			return false
+		}
+		return reg.IsDrew(uint16(x), uint16(y))
+	}
+
+	// Start from the top-left pixel, its west neighbor is guaranteed background
+	startX, startY := -1, -1
+	for y := 0; y < int(reg.GetSizeY()) && startX < 0; y++ {
+		for x := 0; x < int(reg.GetSizeX()); x++ {
+			if isDrew(x, y) {
+				startX, startY = x, y
+				break
+			}
+		}
+	}
+	if startX < 0 {
+		return nil
+	}
+
+	contour := []*region.Point{{X: uint16(startX), Y: uint16(startY)}}
+	x, y := startX, startY
+	backtrack := 4
+	firstDir := -1
+	maxSteps := 4*len(reg.Draws) + 8
+
+	for step := 0; step < maxSteps; step++ {
+		dir := -1
+		for i := 1; i <= 8; i++ {
+			d := (backtrack + i) % 8
+			if isDrew(x+dx[d], y+dy[d]) {
+				dir = d
+				break
+			}
+		}
+
+		// Isolated pixel
+		if dir < 0 {
+			break
+		}
+
+		if x == startX && y == startY {
+			if firstDir < 0 {
+				firstDir = dir
+			} else if dir == firstDir {
+				break
+			}
+		}
+
+		// The last background neighbor examined becomes the backtrack of the next pixel
+		prev := (dir + 7) % 8
+		px, py := x+dx[prev], y+dy[prev]
+		x, y = x+dx[dir], y+dy[dir]
+		for d := 0; d < 8; d++ {
+			if x+dx[d] == px && y+dy[d] == py {
+				backtrack = d
+				break
+			}
+		}
+		contour = append(contour, &region.Point{X: uint16(x), Y: uint16(y)})
+	}
+
+	// Drop the closing visit back to the start pixel
+	if len(contour) > 1 {
+		last := contour[len(contour)-1]
+		if int(last.X) == startX && int(last.Y) == startY {
+			contour = contour[:len(contour)-1]
+		}
+	}
+
+	return contour
+}
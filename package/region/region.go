@@ -5,6 +5,15 @@ type Region struct {
 	SizeY  uint16                     `json:"sizeY"`
 	Bitmap map[uint16]map[uint16]bool `json:"bitmap"`
 	Draws  []*Point                   `json:"draws"`
+
+	// Centroid of the region within the character it was cut from, set by characterCalculate.CharacterBreakdownToRegions
+	OffsetX float64 `json:"offsetX"`
+	OffsetY float64 `json:"offsetY"`
+
+	// Cached analysis results, invalidated on Draw/Erase
+	edges     []*EdgePoint
+	chainCode []int
+	cropped   *Region
 }
 
 func NewRegion(sizeX, sizeY uint16) *Region {
@@ -32,6 +41,7 @@ func (r *Region) Draw(x, y uint16) {
 	}
 	r.Bitmap[x][y] = true
 	r.Draws = append(r.Draws, &Point{X: x, Y: y})
+	r.invalidateCache()
 }
 
 func (r *Region) Erase(x, y uint16) {
@@ -39,6 +49,7 @@ func (r *Region) Erase(x, y uint16) {
 		return
 	}
 	r.Bitmap[x][y] = false
+	r.invalidateCache()
 }
 
 func (r *Region) GetSizeX() uint16 {
@@ -48,3 +59,96 @@ func (r *Region) GetSizeX() uint16 {
 func (r *Region) GetSizeY() uint16 {
 	return r.SizeY
 }
+
+// RelativePosition returns the offset as a fraction of a sizeX×sizeY character, zero when either side is zero
+func (r *Region) RelativePosition(sizeX, sizeY uint16) [2]float64 {
+	if sizeX == 0 || sizeY == 0 {
+		return [2]float64{}
+	}
+	return [2]float64{r.OffsetX / float64(sizeX), r.OffsetY / float64(sizeY)}
+}
+
+func (r *Region) ContentBounds() (minX, minY, maxX, maxY uint16) {
+	minX, minY, maxX, maxY, _ = r.contentBounds()
+	return minX, minY, maxX, maxY
+}
+
+func (r *Region) contentBounds() (minX, minY, maxX, maxY uint16, found bool) {
+	for _, point := range r.Draws {
+		if !r.IsDrew(point.X, point.Y) {
+			continue
+		}
+		if !found {
+			minX, minY, maxX, maxY = point.X, point.Y, point.X, point.Y
+			found = true
+			continue
+		}
+		if point.X < minX {
+			minX = point.X
+		}
+		if point.X > maxX {
+			maxX = point.X
+		}
+		if point.Y < minY {
+			minY = point.Y
+		}
+		if point.Y > maxY {
+			maxY = point.Y
+		}
+	}
+	return minX, minY, maxX, maxY, found
+}
+
+// Crop returns a region sized to the content plus a 1px empty margin, so border pixels still count as edges
+func (r *Region) Crop() *Region {
+	if r.cropped != nil {
+		return r.cropped
+	}
+
+	minX, minY, maxX, maxY, found := r.contentBounds()
+	if !found {
+		return r
+	}
+
+	cropped := NewRegion(maxX-minX+3, maxY-minY+3)
+	seen := make(map[uint32]bool, len(r.Draws))
+	for _, point := range r.Draws {
+		key := uint32(point.X)<<16 | uint32(point.Y)
+		if seen[key] || !r.IsDrew(point.X, point.Y) {
+			continue
+		}
+		seen[key] = true
+		cropped.Draw(point.X-minX+1, point.Y-minY+1)
+	}
+
+	r.cropped = cropped
+	return cropped
+}
+
+func (r *Region) CachedEdges() ([]*EdgePoint, bool) {
+	return r.edges, r.edges != nil
+}
+
+func (r *Region) CacheEdges(edges []*EdgePoint) {
+	if edges == nil {
+		edges = []*EdgePoint{}
+	}
+	r.edges = edges
+}
+
+func (r *Region) CachedChainCode() ([]int, bool) {
+	return r.chainCode, r.chainCode != nil
+}
+
+func (r *Region) CacheChainCode(chainCode []int) {
+	if chainCode == nil {
+		chainCode = []int{}
+	}
+	r.chainCode = chainCode
+}
+
+func (r *Region) invalidateCache() {
+	r.edges = nil
+	r.chainCode = nil
+	r.cropped = nil
+}
@@ -0,0 +1,32 @@
+package region
+
+type RegionAnalysis struct {
+	Edges                []*EdgePoint
+	ChainCode            []int
+	Curvatures           []float64
+	Moments              map[string]float64
+	HuInvariants         []float64
+	Lines                []*HoughAccumulator
+	Circles              []*HoughAccumulator
+	Circularity          float64
+	PerimeterCircularity float64
+	Linearity            float64
+	Orientation          float64
+	CurveStrength        float32
+	Arc                  *Arc
+}
+
+// DefaultLineMinVoteRatio is the share of edge pixels the strongest Hough line needs for a line classification. Votes
+// come from edge pixels only, so the perimeter rather than the area keeps filled and outlined strokes comparable.
+const DefaultLineMinVoteRatio = 0.3
+
+// DefaultCornerMinSpread is the smallest gap allowed between neighboring corners as a share of the even spacing
+// contourLength/len(corners). Noise on a round outline puts its high curvature points close together, the corners of a
+// real polygon sit apart along the contour.
+const DefaultCornerMinSpread = 0.5
+
+// ClassifyOptions tunes how a region analysis chooses its arc type
+type ClassifyOptions struct {
+	LineMinVoteRatio float64 // Share of edge pixels the strongest Hough line needs, 0 uses DefaultLineMinVoteRatio
+	CornerMinSpread  float64 // Smallest corner gap for a polygon, 0 uses DefaultCornerMinSpread, negative accepts clustered corners
+}
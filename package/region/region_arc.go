@@ -8,8 +8,41 @@ const (
 	ArcTypeCurveLine
 	ArcTypeTriangle
 	ArcTypeRectangle
+	ArcTypeUnknown // Too ambiguous to name
 )
 
+var arcTypeNames = map[ArcType]string{
+	ArcTypeCircle:       "circle",
+	ArcTypeStrengthLine: "strength_line",
+	ArcTypeCurveLine:    "curve_line",
+	ArcTypeTriangle:     "triangle",
+	ArcTypeRectangle:    "rectangle",
+	ArcTypeUnknown:      "unknown",
+}
+
+// ArcTypes lists every arc type in declaration order
+func ArcTypes() []ArcType {
+	return []ArcType{ArcTypeCircle, ArcTypeStrengthLine, ArcTypeCurveLine, ArcTypeTriangle, ArcTypeRectangle, ArcTypeUnknown}
+}
+
+// ArcTypeString returns the name the feature database stores for arcType, "unknown" for a value outside ArcTypes
+func ArcTypeString(arcType ArcType) string {
+	if name, ok := arcTypeNames[arcType]; ok {
+		return name
+	}
+	return arcTypeNames[ArcTypeUnknown]
+}
+
+// ParseArcType is the reverse of ArcTypeString, it reports false for a name no arc type has
+func ParseArcType(name string) (ArcType, bool) {
+	for arcType, arcName := range arcTypeNames {
+		if arcName == name {
+			return arcType, true
+		}
+	}
+	return ArcTypeUnknown, false
+}
+
 type ArcFillType int
 
 const (
@@ -23,4 +56,6 @@ type Arc struct {
 	CircleEllipseRatio float32
 	LineDegree         float32
 	ArcLineTheta       float32
+	Confidence         float64 // How decisively the classifier chose Type, 0 to 1
+	Children           []*Arc  // Shapes of the holes the region encloses, so 'O' has one child and 'Θ' two
 }
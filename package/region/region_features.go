@@ -0,0 +1,16 @@
+package region
+
+// RegionFeatureSet is the per region part of a glyph's recognition features as stored in the feature database. The
+// relative fields place the region within its character and stay zero for a standalone region
+type RegionFeatureSet struct {
+	ArcType       string     `yaml:"arc_type"`
+	ArcConfidence float64    `yaml:"arc_confidence"`
+	Circularity   float64    `yaml:"circularity"`
+	Linearity     float64    `yaml:"linearity"`
+	CurveStrength float64    `yaml:"curve_strength"`
+	HuMoments     [7]float64 `yaml:"hu_moments"`
+	ChainCodeHist [8]float64 `yaml:"chain_code_histogram"`
+	RelativeSize  float64    `yaml:"relative_size"`
+	RelativePos   [2]float64 `yaml:"relative_position"`
+	Holes         int        `yaml:"holes,omitempty"` // Enclosed counters, see Arc.Children
+}
@@ -0,0 +1,11 @@
+package test
+
+import (
+	"image"
+
+	"github.com/bsthun/glyphcanvas/package/character"
+)
+
+func CharacterFromImage(img image.Image) *character.Character {
+	return character.LoadFromImage(img, character.DefaultForegroundThreshold, nil)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+func RenderText(lines []string, scale int) *image.Gray {
+	face := basicfont.Face7x13
+	margin := 10
+	lineHeight := face.Height + 8
+
+	width := 0
+	for _, line := range lines {
+		if w := font.MeasureString(face, line).Ceil(); w > width {
+			width = w
+		}
+	}
+	width += margin * 2
+	height := len(lines)*lineHeight + margin*2
+
+	base := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(base, base.Bounds(), image.White, image.Point{}, draw.Src)
+
+	drawer := &font.Drawer{
+		Dst:  base,
+		Src:  image.Black,
+		Face: face,
+	}
+	for i, line := range lines {
+		drawer.Dot = fixed.P(margin, margin+i*lineHeight+face.Ascent)
+		drawer.DrawString(line)
+	}
+
+	if scale <= 1 {
+		return base
+	}
+
+	scaled := image.NewGray(image.Rect(0, 0, width*scale, height*scale))
+	for y := 0; y < height*scale; y++ {
+		for x := 0; x < width*scale; x++ {
+			scaled.SetGray(x, y, color.Gray{Y: base.GrayAt(x/scale, y/scale).Y})
+		}
+	}
+
+	return scaled
+}